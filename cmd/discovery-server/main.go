@@ -0,0 +1,50 @@
+// Package main runs a standalone SHIP and SLAP discovery server.
+//
+// Usage:
+//
+//	discovery-server -config config.yaml
+//	DISCOVERY_STORAGE_BACKEND=mongo DISCOVERY_STORAGE_MONGO_URI=mongodb://db:27017 discovery-server
+//
+// The server hosts the tm_ship and tm_slap topic managers and the ls_ship and ls_slap lookup
+// services behind the HTTP API used by overlay clients (POST /submit and POST /lookup).
+// Configuration is read from a YAML, JSON or .env file and from DISCOVERY_* environment
+// variables; see config.example.yaml for every setting. The server shuts down gracefully on
+// SIGINT or SIGTERM.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/server"
+)
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("Discovery server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	configPath := flag.String("config", "", "configuration file (YAML, JSON or .env)")
+	flag.Parse()
+
+	cfg, err := server.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv, err := server.New(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return srv.Run(ctx)
+}
@@ -0,0 +1,186 @@
+// Package main exports and imports SHIP or SLAP discovery records as line-delimited JSON,
+// for snapshotting a tracker or migrating it between clusters, and migrates stored records.
+//
+// Usage:
+//
+//	records export -protocol ship -out ship.jsonl
+//	records import -protocol ship -in ship.jsonl
+//	records export -protocol slap | records import -protocol slap -mongo-uri mongodb://other:27017
+//	records migrate-domains -protocol ship
+//
+// Every record field is preserved, including the creation time and any stored token material,
+// except that domains are canonicalized. Imported records replace existing records for the same
+// outpoint, so imports can be rerun. migrate-domains canonicalizes the domains of records stored
+// before canonicalization was introduced, and can also be rerun.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+)
+
+// Static error variables for err113 compliance
+var (
+	errUnknownProtocol = errors.New("protocol must be ship or slap")
+	errUnknownCommand  = errors.New("command must be export, import or migrate-domains")
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		slog.Error("Records command failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return errUnknownCommand
+	}
+	command := args[0]
+	if command != "export" && command != "import" && command != "migrate-domains" {
+		return fmt.Errorf("%w: %q", errUnknownCommand, command)
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	mongoURI := flags.String("mongo-uri", "mongodb://localhost:27017", "MongoDB connection URI")
+	database := flags.String("db", "overlay", "MongoDB database name")
+	protocol := flags.String("protocol", "ship", "protocol whose records to transfer: ship or slap")
+	out := flags.String("out", "-", "export destination file, or - for standard output")
+	in := flags.String("in", "-", "import source file, or - for standard input")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(*mongoURI))
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+	db := client.Database(*database)
+
+	switch command {
+	case "export":
+		return runExport(ctx, db, *protocol, *out)
+	case "migrate-domains":
+		return runMigrateDomains(ctx, db, *protocol)
+	default:
+		return runImport(ctx, db, *protocol, *in)
+	}
+}
+
+// runExport writes every record of the protocol to the named file.
+func runExport(ctx context.Context, db *mongo.Database, protocol, path string) error {
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if path != "-" {
+		var err error
+		f, err = os.Create(path) //nolint:gosec // path is supplied by the operator
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		w = f
+	}
+
+	count, err := exportRecords(ctx, db, protocol, w)
+	if f != nil {
+		// A failed close can mean the export did not reach the disk
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close export file: %w", closeErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Exported records", "protocol", protocol, "count", count)
+	return nil
+}
+
+// exportRecords writes every record of the protocol to w and returns the number written.
+func exportRecords(ctx context.Context, db *mongo.Database, protocol string, w io.Writer) (int, error) {
+	switch protocol {
+	case "ship":
+		return ship.ExportJSONL(ctx, ship.NewStorage(db), w)
+	case "slap":
+		return slap.ExportJSONL(ctx, slap.NewStorage(db), w)
+	default:
+		return 0, fmt.Errorf("%w: %q", errUnknownProtocol, protocol)
+	}
+}
+
+// runImport reads records of the protocol from the named file into storage.
+func runImport(ctx context.Context, db *mongo.Database, protocol, path string) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path) //nolint:gosec // path is supplied by the operator
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		r = f
+	}
+
+	var count int
+	var err error
+	switch protocol {
+	case "ship":
+		storage := ship.NewStorage(db)
+		if err = storage.EnsureIndexes(ctx); err == nil {
+			count, err = ship.ImportJSONL(ctx, storage, r)
+		}
+	case "slap":
+		storage := slap.NewStorage(db)
+		if err = storage.EnsureIndexes(ctx); err == nil {
+			count, err = slap.ImportJSONL(ctx, storage, r)
+		}
+	default:
+		return fmt.Errorf("%w: %q", errUnknownProtocol, protocol)
+	}
+
+	if err != nil {
+		return fmt.Errorf("import failed after %d records: %w", count, err)
+	}
+
+	slog.Info("Imported records", "protocol", protocol, "count", count)
+	return nil
+}
+
+// runMigrateDomains rewrites the stored domains of the protocol's records to canonical form.
+func runMigrateDomains(ctx context.Context, db *mongo.Database, protocol string) error {
+	var count int
+	var err error
+	switch protocol {
+	case "ship":
+		count, err = ship.NewStorage(db).CanonicalizeDomains(ctx)
+	case "slap":
+		count, err = slap.NewStorage(db).CanonicalizeDomains(ctx)
+	default:
+		return fmt.Errorf("%w: %q", errUnknownProtocol, protocol)
+	}
+
+	if err != nil {
+		return fmt.Errorf("domain migration failed after %d records: %w", count, err)
+	}
+
+	slog.Info("Canonicalized record domains", "protocol", protocol, "updated", count)
+	return nil
+}
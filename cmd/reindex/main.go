@@ -0,0 +1,142 @@
+// Package main rebuilds SHIP or SLAP discovery storage from an archive of Atomic BEEF.
+//
+// Usage:
+//
+//	reindex -protocol ship -dir ./archive
+//	reindex -protocol slap -file archive.jsonl
+//	cat archive.jsonl | reindex -protocol ship -file -
+//
+// A directory may contain raw Atomic BEEF files (*.beef, every output is reindexed) and
+// JSON lines files (*.jsonl, *.json) of {"beef": "<hex>", "outputIndexes": [0]} records.
+// The same records can be supplied as a single file or on standard input with -file.
+// Outputs that already have a record are skipped, so the command can be rerun safely.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+)
+
+// Static error variables for err113 compliance
+var (
+	errUnknownProtocol = errors.New("protocol must be ship or slap")
+	errNoArchive       = errors.New("exactly one of -dir or -file is required")
+)
+
+// reindexer is implemented by the SHIP and SLAP topic managers.
+type reindexer interface {
+	Reindex(ctx context.Context, source shared.ReindexSource) (shared.ReindexReport, error)
+}
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("Reindex failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	mongoURI := flag.String("mongo-uri", "mongodb://localhost:27017", "MongoDB connection URI")
+	database := flag.String("db", "overlay", "MongoDB database name")
+	protocol := flag.String("protocol", "ship", "protocol to reindex: ship or slap")
+	dir := flag.String("dir", "", "directory of *.beef and *.jsonl archives to reindex")
+	file := flag.String("file", "", "JSON lines archive to reindex, or - for standard input")
+	keepToken := flag.Bool("keep-token-material", false, "store raw token material with each record")
+	flag.Parse()
+
+	if (*dir == "") == (*file == "") {
+		return errNoArchive
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(*mongoURI))
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+
+	topicManager, err := newReindexer(ctx, client.Database(*database), *protocol, *keepToken)
+	if err != nil {
+		return err
+	}
+
+	source, closeSource, err := openSource(*dir, *file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = closeSource()
+	}()
+
+	report, reindexErr := topicManager.Reindex(ctx, source)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return reindexErr
+}
+
+// newReindexer builds the topic manager and lookup service for the protocol.
+func newReindexer(ctx context.Context, db *mongo.Database, protocol string, keepToken bool) (reindexer, error) {
+	switch protocol {
+	case "ship":
+		storage := ship.NewStorage(db)
+		if err := storage.EnsureIndexes(ctx); err != nil {
+			return nil, err
+		}
+		lookupService := ship.NewLookupService(storage)
+		lookupService.SetKeepTokenMaterial(keepToken)
+		return ship.NewTopicManager(storage, lookupService), nil
+	case "slap":
+		storage := slap.NewStorage(db)
+		if err := storage.EnsureIndexes(ctx); err != nil {
+			return nil, err
+		}
+		lookupService := slap.NewLookupService(storage)
+		lookupService.SetKeepTokenMaterial(keepToken)
+		return slap.NewTopicManager(storage, lookupService), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownProtocol, protocol)
+	}
+}
+
+// openSource opens the archive named on the command line and returns a function closing it.
+func openSource(dir, file string) (shared.ReindexSource, func() error, error) {
+	if dir != "" {
+		source, err := shared.NewDirReindexSource(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return source, source.Close, nil
+	}
+
+	if file == "-" {
+		return shared.NewStreamReindexSource("stdin", os.Stdin), func() error { return nil }, nil
+	}
+
+	f, err := os.Open(file) //nolint:gosec // path is supplied by the operator
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	return shared.NewStreamReindexSource(file, f), f.Close, nil
+}
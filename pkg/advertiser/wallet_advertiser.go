@@ -31,6 +31,7 @@ import (
 	toolboxWallet "github.com/bsv-blockchain/go-wallet-toolbox/pkg/wallet"
 	"github.com/bsv-blockchain/go-wallet-toolbox/pkg/wdk"
 
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
@@ -96,12 +97,18 @@ type WalletAdvertiser struct {
 	skipStorageValidation bool
 	// testMode enables test mode with mock data instead of real HTTP requests
 	testMode bool
+	// uriSchemes decides which advertisable URIs are accepted (nil uses the default registry)
+	uriSchemes *utils.URISchemeRegistry
+	// naming decides which topic or service names are accepted (nil uses the default policy)
+	naming *utils.NamingPolicy
 	// authFetch provides authenticated HTTP requests for storage communication
 	authFetch *authhttp.AuthFetch
 	// wallet provides the wallet interface for authentication
 	wallet wallet.Interface
 	// identityKey is the hex-encoded identity key derived from the private key
 	identityKey string
+	// logger receives advertisement lookup events (optional)
+	logger *slog.Logger
 	// Finder allows mocking
 	Finder Finder
 }
@@ -131,8 +138,8 @@ func NewWalletAdvertiser(chain, privateKey, storageURL, advertisableURI string,
 	}
 
 	// Validate advertisable URI
-	if !utils.IsAdvertisableURI(advertisableURI) {
-		return nil, fmt.Errorf("%w: %s", errAdvertisableURIInvalid, advertisableURI)
+	if _, err := utils.ParseAdvertisableURI(advertisableURI); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errAdvertisableURIInvalid, advertisableURI, err)
 	}
 
 	// Validate storage URL (basic URL validation)
@@ -149,7 +156,8 @@ func NewWalletAdvertiser(chain, privateKey, storageURL, advertisableURI string,
 	// Initialize the wallet configuration
 	cfg := infra.Defaults()
 	cfg.ServerPrivateKey = privateKey
-	activeServices := services.New(slog.Default(), cfg.Services)
+	// The wallet services are created before a logger can be set, so they stay silent
+	activeServices := services.New(shared.Logger(context.Background(), nil), cfg.Services)
 
 	// Create storage manager for the wallet
 	storageManager, errStorage := storage.NewGORMProvider(
@@ -204,6 +212,17 @@ func NewWalletAdvertiser(chain, privateKey, storageURL, advertisableURI string,
 	}, nil
 }
 
+// SetLogger sets the logger receiving advertisement lookup events and the logs of the wallet
+// services used to create advertisements. A nil logger (the default) discards them.
+func (w *WalletAdvertiser) SetLogger(logger *slog.Logger) {
+	w.logger = logger
+}
+
+// log returns the logger set by SetLogger, or a logger discarding every event.
+func (w *WalletAdvertiser) log() *slog.Logger {
+	return shared.Logger(context.Background(), w.logger)
+}
+
 // SetSkipStorageValidation allows skipping storage connectivity validation.
 // This is useful for testing environments where the storage service may not be available.
 func (w *WalletAdvertiser) SetSkipStorageValidation(skip bool) {
@@ -216,6 +235,42 @@ func (w *WalletAdvertiser) SetTestMode(testMode bool) {
 	w.testMode = testMode
 }
 
+// SetURISchemes sets the registry the advertisable URI is validated against, in place of
+// utils.DefaultURISchemeRegistry. It returns an error, leaving the registry unchanged, if the
+// registry does not accept the advertiser's URI.
+func (w *WalletAdvertiser) SetURISchemes(registry *utils.URISchemeRegistry) error {
+	if registry == nil {
+		registry = utils.DefaultURISchemeRegistry()
+	}
+	if _, err := registry.Parse(w.advertisableURI); err != nil {
+		return fmt.Errorf("%w: %s: %w", errAdvertisableURIInvalid, w.advertisableURI, err)
+	}
+	w.uriSchemes = registry
+	return nil
+}
+
+// uriSchemeRegistry returns the registry the advertisable URI is validated against.
+func (w *WalletAdvertiser) uriSchemeRegistry() *utils.URISchemeRegistry {
+	if w.uriSchemes != nil {
+		return w.uriSchemes
+	}
+	return utils.DefaultURISchemeRegistry()
+}
+
+// SetNamingPolicy sets the policy topic and service names are validated against, in place of
+// utils.DefaultNamingPolicy. A nil policy restores the default.
+func (w *WalletAdvertiser) SetNamingPolicy(policy *utils.NamingPolicy) {
+	w.naming = policy
+}
+
+// namingPolicy returns the policy topic and service names are validated against.
+func (w *WalletAdvertiser) namingPolicy() *utils.NamingPolicy {
+	if w.naming != nil {
+		return w.naming
+	}
+	return utils.DefaultNamingPolicy()
+}
+
 // Init initializes the advertiser service and sets up any required resources.
 // This method must be called before using any other advertiser functionality.
 func (w *WalletAdvertiser) Init() error {
@@ -262,6 +317,11 @@ func (w *WalletAdvertiser) CreateAdvertisements(adsData []*oa.AdvertisementData)
 		return overlay.TaggedBEEF{}, errNoAdvertisementData
 	}
 
+	// Schemes may have been disabled since the advertiser was created
+	if _, err := w.uriSchemeRegistry().Parse(w.advertisableURI); err != nil {
+		return overlay.TaggedBEEF{}, fmt.Errorf("%w: %s: %w", errAdvertisableURIInvalid, w.advertisableURI, err)
+	}
+
 	// Validate all advertisement data entries
 	for i, adData := range adsData {
 		if err := w.validateAdvertisementData(adData); err != nil {
@@ -287,7 +347,7 @@ func (w *WalletAdvertiser) CreateAdvertisements(adsData []*oa.AdvertisementData)
 	if err != nil {
 		return overlay.TaggedBEEF{}, fmt.Errorf("failed to create private key from hex: %w", err)
 	}
-	logger := slog.Default()
+	logger := w.log()
 	cfg := infra.Defaults()
 	cfg.ServerPrivateKey = w.privateKey
 	activeServices := services.New(logger, cfg.Services)
@@ -325,7 +385,7 @@ func (w *WalletAdvertiser) CreateAdvertisements(adsData []*oa.AdvertisementData)
 
 	outputs := make([]wallet.CreateActionOutput, 0, len(adsData))
 	for _, ad := range adsData {
-		if !utils.IsValidTopicOrServiceName(ad.TopicOrServiceName) {
+		if !w.namingPolicy().IsValid(ad.TopicOrServiceName) {
 			return overlay.TaggedBEEF{}, fmt.Errorf("%w: %s", errInvalidTopicOrServiceName, ad.TopicOrServiceName)
 		}
 		protocol := wallet.Protocol{SecurityLevel: wallet.SecurityLevelEveryAppAndCounterparty}
@@ -506,7 +566,7 @@ func (w *WalletAdvertiser) ParseAdvertisement(outputScript *script.Script) (*oa.
 		fullTopicOrService = "ls_" + topicOrService
 	}
 
-	if !utils.IsValidTopicOrServiceName(fullTopicOrService) {
+	if !w.namingPolicy().IsValid(fullTopicOrService) {
 		return nil, fmt.Errorf("%w: %s", errInvalidTopicOrServiceName, fullTopicOrService)
 	}
 
@@ -540,7 +600,7 @@ func (w *WalletAdvertiser) validateAdvertisementData(adData *oa.AdvertisementDat
 	}
 
 	// Validate using utils function
-	if !utils.IsValidTopicOrServiceName(fullName) {
+	if !w.namingPolicy().IsValid(fullName) {
 		return fmt.Errorf("%w: %s", errInvalidTopicOrServiceName, fullName)
 	}
 
@@ -869,7 +929,7 @@ func (w *WalletAdvertiser) queryStorageForAdvertisements(protocol overlay.Protoc
 	lookupAnswer, err := resolver.Query(ctx, question)
 	if err != nil {
 		// Log warning but return empty array, matching TypeScript behavior
-		slog.Warn("Error finding advertisements", "protocol", protocol, "error", err)
+		w.log().Warn("Error finding advertisements", shared.LogKeyProtocol, protocol, shared.LogKeyError, err)
 		return []*oa.Advertisement{}, nil
 	}
 
@@ -882,14 +942,17 @@ func (w *WalletAdvertiser) queryStorageForAdvertisements(protocol overlay.Protoc
 			// Parse out the advertisements using the provided parser
 			tx, err := transaction.NewTransactionFromBEEF(output.Beef)
 			if err != nil {
-				slog.Error("Failed to parse transaction from BEEF", "error", err)
+				w.log().Error("Failed to parse transaction from BEEF", shared.LogKeyError, err)
 				continue
 			}
 
 			// Get the output at the specified index
 			if int(output.OutputIndex) >= len(tx.Outputs) {
-				slog.Error("Output index out of range for transaction with outputs", "index", output.OutputIndex,
-					"output_count", len(tx.Outputs))
+				w.log().Error("Output index out of range for transaction",
+					shared.LogKeyTxid, tx.TxID().String(),
+					shared.LogKeyOutputIndex, output.OutputIndex,
+					"outputCount", len(tx.Outputs),
+				)
 				continue
 			}
 
@@ -899,14 +962,21 @@ func (w *WalletAdvertiser) queryStorageForAdvertisements(protocol overlay.Protoc
 			// Parse the advertisement from the locking script
 			advertisement, err := w.ParseAdvertisement(lockingScript)
 			if err != nil {
-				slog.Error("Failed to parse advertisement output", "error", err)
+				w.log().Error("Failed to parse advertisement output",
+					shared.LogKeyTxid, tx.TxID().String(),
+					shared.LogKeyOutputIndex, output.OutputIndex,
+					shared.LogKeyError, err,
+				)
 				continue
 			}
 
 			// Check if the advertisement matches the requested protocol
 			if advertisement != nil && advertisement.Protocol == protocol {
-				slog.Info("Found current advertisement", "TopicOrService", advertisement.TopicOrService,
-					"Domain", advertisement.Domain)
+				w.log().Info("Found current advertisement",
+					shared.LogKeyProtocol, advertisement.Protocol,
+					"topicOrService", advertisement.TopicOrService,
+					"domain", advertisement.Domain,
+				)
 				// Add BEEF and output index from the lookup result
 				advertisement.Beef = output.Beef
 				advertisement.OutputIndex = output.OutputIndex
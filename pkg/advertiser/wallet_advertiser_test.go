@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
 func TestNewWalletAdvertiser(t *testing.T) {
@@ -522,6 +523,45 @@ func TestWalletAdvertiser_MethodsRequireInitialization(t *testing.T) {
 	assert.Contains(t, err.Error(), "WalletAdvertiser must be initialized")
 }
 
+func TestWalletAdvertiser_SetURISchemes(t *testing.T) {
+	advertiser := setupInitializedAdvertiser(t)
+	advertiser.Finder = &MockFinder{}
+	adsData := []*oa.AdvertisementData{{Protocol: overlay.ProtocolSHIP, TopicOrServiceName: "tm_ship"}}
+
+	registry := utils.NewURISchemeRegistry()
+	require.NoError(t, registry.Disable("https://"))
+
+	// A registry rejecting the advertiser's URI is not adopted
+	err := advertiser.SetURISchemes(registry)
+	require.ErrorIs(t, err, errAdvertisableURIInvalid)
+	require.ErrorIs(t, err, utils.ErrURIUnsupportedScheme)
+	_, err = advertiser.CreateAdvertisements(adsData)
+	require.NoError(t, err)
+
+	// Disabling the scheme after adoption stops new advertisements
+	registry = utils.NewURISchemeRegistry()
+	require.NoError(t, advertiser.SetURISchemes(registry))
+	require.NoError(t, registry.Disable("https://"))
+	_, err = advertiser.CreateAdvertisements(adsData)
+	require.ErrorIs(t, err, errAdvertisableURIInvalid)
+}
+
+func TestWalletAdvertiser_SetNamingPolicy(t *testing.T) {
+	advertiser := setupInitializedAdvertiser(t)
+	advertiser.Finder = &MockFinder{}
+	adsData := []*oa.AdvertisementData{{Protocol: overlay.ProtocolSHIP, TopicOrServiceName: "brc100"}}
+
+	_, err := advertiser.CreateAdvertisements(adsData)
+	require.ErrorIs(t, err, errInvalidTopicOrServiceName)
+
+	policy, err := utils.NewNamingPolicy(utils.NamingPolicyConfig{AllowedChars: utils.LowercaseLetters + utils.Digits})
+	require.NoError(t, err)
+	advertiser.SetNamingPolicy(policy)
+
+	_, err = advertiser.CreateAdvertisements(adsData)
+	require.NoError(t, err)
+}
+
 // Helper functions
 
 func setupInitializedAdvertiser(t *testing.T) *WalletAdvertiser {
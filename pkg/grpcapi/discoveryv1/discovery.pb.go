@@ -0,0 +1,867 @@
+// Discovery API for SHIP host and SLAP tracker lookups and advertisement subscriptions.
+//
+// The Go code in pkg/grpcapi/discoveryv1 is generated with go generate ./pkg/grpcapi.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: discovery/v1/discovery.proto
+
+package discoveryv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SortOrder orders lookup results by record creation time.
+type SortOrder int32
+
+const (
+	// SORT_ORDER_UNSPECIFIED uses the storage default (newest first).
+	SortOrder_SORT_ORDER_UNSPECIFIED SortOrder = 0
+	// SORT_ORDER_ASC returns the oldest records first.
+	SortOrder_SORT_ORDER_ASC SortOrder = 1
+	// SORT_ORDER_DESC returns the newest records first.
+	SortOrder_SORT_ORDER_DESC SortOrder = 2
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_UNSPECIFIED",
+		1: "SORT_ORDER_ASC",
+		2: "SORT_ORDER_DESC",
+	}
+	SortOrder_value = map[string]int32{
+		"SORT_ORDER_UNSPECIFIED": 0,
+		"SORT_ORDER_ASC":         1,
+		"SORT_ORDER_DESC":        2,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_discovery_v1_discovery_proto_enumTypes[0].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_discovery_v1_discovery_proto_enumTypes[0]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{0}
+}
+
+// RecordEventType is the kind of change a RecordEvent describes.
+type RecordEventType int32
+
+const (
+	// RECORD_EVENT_TYPE_UNSPECIFIED is never sent.
+	RecordEventType_RECORD_EVENT_TYPE_UNSPECIFIED RecordEventType = 0
+	// RECORD_EVENT_TYPE_ADMITTED reports a newly stored advertisement.
+	RecordEventType_RECORD_EVENT_TYPE_ADMITTED RecordEventType = 1
+	// RECORD_EVENT_TYPE_REMOVED reports an advertisement removed after being spent or evicted.
+	RecordEventType_RECORD_EVENT_TYPE_REMOVED RecordEventType = 2
+)
+
+// Enum value maps for RecordEventType.
+var (
+	RecordEventType_name = map[int32]string{
+		0: "RECORD_EVENT_TYPE_UNSPECIFIED",
+		1: "RECORD_EVENT_TYPE_ADMITTED",
+		2: "RECORD_EVENT_TYPE_REMOVED",
+	}
+	RecordEventType_value = map[string]int32{
+		"RECORD_EVENT_TYPE_UNSPECIFIED": 0,
+		"RECORD_EVENT_TYPE_ADMITTED":    1,
+		"RECORD_EVENT_TYPE_REMOVED":     2,
+	}
+)
+
+func (x RecordEventType) Enum() *RecordEventType {
+	p := new(RecordEventType)
+	*p = x
+	return p
+}
+
+func (x RecordEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RecordEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_discovery_v1_discovery_proto_enumTypes[1].Descriptor()
+}
+
+func (RecordEventType) Type() protoreflect.EnumType {
+	return &file_discovery_v1_discovery_proto_enumTypes[1]
+}
+
+func (x RecordEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RecordEventType.Descriptor instead.
+func (RecordEventType) EnumDescriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{1}
+}
+
+// Pagination selects a page of lookup results.
+type Pagination struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// limit is the maximum number of results; unset returns every match.
+	Limit *int32 `protobuf:"varint,1,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
+	// skip is the number of matching results to skip.
+	Skip *int32 `protobuf:"varint,2,opt,name=skip,proto3,oneof" json:"skip,omitempty"`
+	// sort_order orders results by record creation time.
+	SortOrder     SortOrder `protobuf:"varint,3,opt,name=sort_order,json=sortOrder,proto3,enum=discovery.v1.SortOrder" json:"sort_order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pagination) Reset() {
+	*x = Pagination{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pagination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pagination) ProtoMessage() {}
+
+func (x *Pagination) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pagination.ProtoReflect.Descriptor instead.
+func (*Pagination) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Pagination) GetLimit() int32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+func (x *Pagination) GetSkip() int32 {
+	if x != nil && x.Skip != nil {
+		return *x.Skip
+	}
+	return 0
+}
+
+func (x *Pagination) GetSortOrder() SortOrder {
+	if x != nil {
+		return x.SortOrder
+	}
+	return SortOrder_SORT_ORDER_UNSPECIFIED
+}
+
+// FindHostsRequest is a SHIP query. Unset filters match any value.
+type FindHostsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// find_all returns every SHIP record, ignoring the other filters.
+	FindAll bool `protobuf:"varint,1,opt,name=find_all,json=findAll,proto3" json:"find_all,omitempty"`
+	// domain filters by advertised domain.
+	Domain *string `protobuf:"bytes,2,opt,name=domain,proto3,oneof" json:"domain,omitempty"`
+	// topics filters by advertised topic, matching any of the given topics.
+	Topics []string `protobuf:"bytes,3,rep,name=topics,proto3" json:"topics,omitempty"`
+	// identity_key filters by the advertiser's hex-encoded identity key.
+	IdentityKey *string `protobuf:"bytes,4,opt,name=identity_key,json=identityKey,proto3,oneof" json:"identity_key,omitempty"`
+	// pagination selects a page of results.
+	Pagination    *Pagination `protobuf:"bytes,5,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindHostsRequest) Reset() {
+	*x = FindHostsRequest{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindHostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindHostsRequest) ProtoMessage() {}
+
+func (x *FindHostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindHostsRequest.ProtoReflect.Descriptor instead.
+func (*FindHostsRequest) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FindHostsRequest) GetFindAll() bool {
+	if x != nil {
+		return x.FindAll
+	}
+	return false
+}
+
+func (x *FindHostsRequest) GetDomain() string {
+	if x != nil && x.Domain != nil {
+		return *x.Domain
+	}
+	return ""
+}
+
+func (x *FindHostsRequest) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *FindHostsRequest) GetIdentityKey() string {
+	if x != nil && x.IdentityKey != nil {
+		return *x.IdentityKey
+	}
+	return ""
+}
+
+func (x *FindHostsRequest) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+// FindTrackersRequest is a SLAP query. Unset filters match any value.
+type FindTrackersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// find_all returns every SLAP record, ignoring the other filters.
+	FindAll bool `protobuf:"varint,1,opt,name=find_all,json=findAll,proto3" json:"find_all,omitempty"`
+	// domain filters by advertised domain.
+	Domain *string `protobuf:"bytes,2,opt,name=domain,proto3,oneof" json:"domain,omitempty"`
+	// service filters by advertised lookup service.
+	Service *string `protobuf:"bytes,3,opt,name=service,proto3,oneof" json:"service,omitempty"`
+	// identity_key filters by the advertiser's hex-encoded identity key.
+	IdentityKey *string `protobuf:"bytes,4,opt,name=identity_key,json=identityKey,proto3,oneof" json:"identity_key,omitempty"`
+	// pagination selects a page of results.
+	Pagination    *Pagination `protobuf:"bytes,5,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindTrackersRequest) Reset() {
+	*x = FindTrackersRequest{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindTrackersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindTrackersRequest) ProtoMessage() {}
+
+func (x *FindTrackersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindTrackersRequest.ProtoReflect.Descriptor instead.
+func (*FindTrackersRequest) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FindTrackersRequest) GetFindAll() bool {
+	if x != nil {
+		return x.FindAll
+	}
+	return false
+}
+
+func (x *FindTrackersRequest) GetDomain() string {
+	if x != nil && x.Domain != nil {
+		return *x.Domain
+	}
+	return ""
+}
+
+func (x *FindTrackersRequest) GetService() string {
+	if x != nil && x.Service != nil {
+		return *x.Service
+	}
+	return ""
+}
+
+func (x *FindTrackersRequest) GetIdentityKey() string {
+	if x != nil && x.IdentityKey != nil {
+		return *x.IdentityKey
+	}
+	return ""
+}
+
+func (x *FindTrackersRequest) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+// Output is an advertisement output matched by a lookup.
+type Output struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// txid is the hex-encoded transaction ID.
+	Txid string `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	// output_index is the index of the advertisement output.
+	OutputIndex uint32 `protobuf:"varint,2,opt,name=output_index,json=outputIndex,proto3" json:"output_index,omitempty"`
+	// beef is the Atomic BEEF of the transaction, when the lookup service is configured to provide it.
+	Beef          []byte `protobuf:"bytes,3,opt,name=beef,proto3" json:"beef,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Output) Reset() {
+	*x = Output{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Output) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Output) ProtoMessage() {}
+
+func (x *Output) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Output.ProtoReflect.Descriptor instead.
+func (*Output) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Output) GetTxid() string {
+	if x != nil {
+		return x.Txid
+	}
+	return ""
+}
+
+func (x *Output) GetOutputIndex() uint32 {
+	if x != nil {
+		return x.OutputIndex
+	}
+	return 0
+}
+
+func (x *Output) GetBeef() []byte {
+	if x != nil {
+		return x.Beef
+	}
+	return nil
+}
+
+// LookupResponse lists the outputs matched by a lookup.
+type LookupResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// outputs are the matched advertisement outputs.
+	Outputs       []*Output `protobuf:"bytes,1,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResponse) Reset() {
+	*x = LookupResponse{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResponse) ProtoMessage() {}
+
+func (x *LookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResponse.ProtoReflect.Descriptor instead.
+func (*LookupResponse) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LookupResponse) GetOutputs() []*Output {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+// SubscribeHostsRequest filters SHIP advertisement events. Empty fields match any value.
+type SubscribeHostsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// topic filters by advertised topic.
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// domain filters by advertised domain.
+	Domain string `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	// identity_key filters by the advertiser's identity key.
+	IdentityKey   string `protobuf:"bytes,3,opt,name=identity_key,json=identityKey,proto3" json:"identity_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeHostsRequest) Reset() {
+	*x = SubscribeHostsRequest{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeHostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeHostsRequest) ProtoMessage() {}
+
+func (x *SubscribeHostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeHostsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeHostsRequest) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SubscribeHostsRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *SubscribeHostsRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *SubscribeHostsRequest) GetIdentityKey() string {
+	if x != nil {
+		return x.IdentityKey
+	}
+	return ""
+}
+
+// SubscribeTrackersRequest filters SLAP advertisement events. Empty fields match any value.
+type SubscribeTrackersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// service filters by advertised service; a trailing "*" matches by prefix.
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// domain filters by advertised domain; "*" matches any domain.
+	Domain string `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	// identity_key filters by the advertiser's identity key.
+	IdentityKey   string `protobuf:"bytes,3,opt,name=identity_key,json=identityKey,proto3" json:"identity_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeTrackersRequest) Reset() {
+	*x = SubscribeTrackersRequest{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeTrackersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeTrackersRequest) ProtoMessage() {}
+
+func (x *SubscribeTrackersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeTrackersRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeTrackersRequest) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SubscribeTrackersRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *SubscribeTrackersRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *SubscribeTrackersRequest) GetIdentityKey() string {
+	if x != nil {
+		return x.IdentityKey
+	}
+	return ""
+}
+
+// RecordEvent describes a SHIP or SLAP advertisement being admitted or removed.
+type RecordEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// type is the kind of change.
+	Type RecordEventType `protobuf:"varint,1,opt,name=type,proto3,enum=discovery.v1.RecordEventType" json:"type,omitempty"`
+	// protocol is "SHIP" or "SLAP".
+	Protocol string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// txid is the hex-encoded transaction ID of the advertisement output.
+	Txid string `protobuf:"bytes,3,opt,name=txid,proto3" json:"txid,omitempty"`
+	// output_index is the index of the advertisement output.
+	OutputIndex uint32 `protobuf:"varint,4,opt,name=output_index,json=outputIndex,proto3" json:"output_index,omitempty"`
+	// identity_key is the advertiser's identity key.
+	IdentityKey string `protobuf:"bytes,5,opt,name=identity_key,json=identityKey,proto3" json:"identity_key,omitempty"`
+	// domain is the advertised domain.
+	Domain string `protobuf:"bytes,6,opt,name=domain,proto3" json:"domain,omitempty"`
+	// topic is the advertised topic (SHIP only).
+	Topic string `protobuf:"bytes,7,opt,name=topic,proto3" json:"topic,omitempty"`
+	// service is the advertised service (SLAP only).
+	Service string `protobuf:"bytes,8,opt,name=service,proto3" json:"service,omitempty"`
+	// timestamp is when the change happened.
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordEvent) Reset() {
+	*x = RecordEvent{}
+	mi := &file_discovery_v1_discovery_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordEvent) ProtoMessage() {}
+
+func (x *RecordEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_discovery_v1_discovery_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordEvent.ProtoReflect.Descriptor instead.
+func (*RecordEvent) Descriptor() ([]byte, []int) {
+	return file_discovery_v1_discovery_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RecordEvent) GetType() RecordEventType {
+	if x != nil {
+		return x.Type
+	}
+	return RecordEventType_RECORD_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *RecordEvent) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *RecordEvent) GetTxid() string {
+	if x != nil {
+		return x.Txid
+	}
+	return ""
+}
+
+func (x *RecordEvent) GetOutputIndex() uint32 {
+	if x != nil {
+		return x.OutputIndex
+	}
+	return 0
+}
+
+func (x *RecordEvent) GetIdentityKey() string {
+	if x != nil {
+		return x.IdentityKey
+	}
+	return ""
+}
+
+func (x *RecordEvent) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *RecordEvent) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *RecordEvent) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *RecordEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+var File_discovery_v1_discovery_proto protoreflect.FileDescriptor
+
+const file_discovery_v1_discovery_proto_rawDesc = "" +
+	"\n" +
+	"\x1cdiscovery/v1/discovery.proto\x12\fdiscovery.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8b\x01\n" +
+	"\n" +
+	"Pagination\x12\x19\n" +
+	"\x05limit\x18\x01 \x01(\x05H\x00R\x05limit\x88\x01\x01\x12\x17\n" +
+	"\x04skip\x18\x02 \x01(\x05H\x01R\x04skip\x88\x01\x01\x126\n" +
+	"\n" +
+	"sort_order\x18\x03 \x01(\x0e2\x17.discovery.v1.SortOrderR\tsortOrderB\b\n" +
+	"\x06_limitB\a\n" +
+	"\x05_skip\"\xe0\x01\n" +
+	"\x10FindHostsRequest\x12\x19\n" +
+	"\bfind_all\x18\x01 \x01(\bR\afindAll\x12\x1b\n" +
+	"\x06domain\x18\x02 \x01(\tH\x00R\x06domain\x88\x01\x01\x12\x16\n" +
+	"\x06topics\x18\x03 \x03(\tR\x06topics\x12&\n" +
+	"\fidentity_key\x18\x04 \x01(\tH\x01R\videntityKey\x88\x01\x01\x128\n" +
+	"\n" +
+	"pagination\x18\x05 \x01(\v2\x18.discovery.v1.PaginationR\n" +
+	"paginationB\t\n" +
+	"\a_domainB\x0f\n" +
+	"\r_identity_key\"\xf6\x01\n" +
+	"\x13FindTrackersRequest\x12\x19\n" +
+	"\bfind_all\x18\x01 \x01(\bR\afindAll\x12\x1b\n" +
+	"\x06domain\x18\x02 \x01(\tH\x00R\x06domain\x88\x01\x01\x12\x1d\n" +
+	"\aservice\x18\x03 \x01(\tH\x01R\aservice\x88\x01\x01\x12&\n" +
+	"\fidentity_key\x18\x04 \x01(\tH\x02R\videntityKey\x88\x01\x01\x128\n" +
+	"\n" +
+	"pagination\x18\x05 \x01(\v2\x18.discovery.v1.PaginationR\n" +
+	"paginationB\t\n" +
+	"\a_domainB\n" +
+	"\n" +
+	"\b_serviceB\x0f\n" +
+	"\r_identity_key\"S\n" +
+	"\x06Output\x12\x12\n" +
+	"\x04txid\x18\x01 \x01(\tR\x04txid\x12!\n" +
+	"\foutput_index\x18\x02 \x01(\rR\voutputIndex\x12\x12\n" +
+	"\x04beef\x18\x03 \x01(\fR\x04beef\"@\n" +
+	"\x0eLookupResponse\x12.\n" +
+	"\aoutputs\x18\x01 \x03(\v2\x14.discovery.v1.OutputR\aoutputs\"h\n" +
+	"\x15SubscribeHostsRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12!\n" +
+	"\fidentity_key\x18\x03 \x01(\tR\videntityKey\"o\n" +
+	"\x18SubscribeTrackersRequest\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12!\n" +
+	"\fidentity_key\x18\x03 \x01(\tR\videntityKey\"\xb8\x02\n" +
+	"\vRecordEvent\x121\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1d.discovery.v1.RecordEventTypeR\x04type\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x12\n" +
+	"\x04txid\x18\x03 \x01(\tR\x04txid\x12!\n" +
+	"\foutput_index\x18\x04 \x01(\rR\voutputIndex\x12!\n" +
+	"\fidentity_key\x18\x05 \x01(\tR\videntityKey\x12\x16\n" +
+	"\x06domain\x18\x06 \x01(\tR\x06domain\x12\x14\n" +
+	"\x05topic\x18\a \x01(\tR\x05topic\x12\x18\n" +
+	"\aservice\x18\b \x01(\tR\aservice\x128\n" +
+	"\ttimestamp\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp*P\n" +
+	"\tSortOrder\x12\x1a\n" +
+	"\x16SORT_ORDER_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eSORT_ORDER_ASC\x10\x01\x12\x13\n" +
+	"\x0fSORT_ORDER_DESC\x10\x02*s\n" +
+	"\x0fRecordEventType\x12!\n" +
+	"\x1dRECORD_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aRECORD_EVENT_TYPE_ADMITTED\x10\x01\x12\x1d\n" +
+	"\x19RECORD_EVENT_TYPE_REMOVED\x10\x022\xdc\x02\n" +
+	"\x10DiscoveryService\x12I\n" +
+	"\tFindHosts\x12\x1e.discovery.v1.FindHostsRequest\x1a\x1c.discovery.v1.LookupResponse\x12O\n" +
+	"\fFindTrackers\x12!.discovery.v1.FindTrackersRequest\x1a\x1c.discovery.v1.LookupResponse\x12R\n" +
+	"\x0eSubscribeHosts\x12#.discovery.v1.SubscribeHostsRequest\x1a\x19.discovery.v1.RecordEvent0\x01\x12X\n" +
+	"\x11SubscribeTrackers\x12&.discovery.v1.SubscribeTrackersRequest\x1a\x19.discovery.v1.RecordEvent0\x01B]Z[github.com/bsv-blockchain/go-overlay-discovery-services/pkg/grpcapi/discoveryv1;discoveryv1b\x06proto3"
+
+var (
+	file_discovery_v1_discovery_proto_rawDescOnce sync.Once
+	file_discovery_v1_discovery_proto_rawDescData []byte
+)
+
+func file_discovery_v1_discovery_proto_rawDescGZIP() []byte {
+	file_discovery_v1_discovery_proto_rawDescOnce.Do(func() {
+		file_discovery_v1_discovery_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_discovery_v1_discovery_proto_rawDesc), len(file_discovery_v1_discovery_proto_rawDesc)))
+	})
+	return file_discovery_v1_discovery_proto_rawDescData
+}
+
+var file_discovery_v1_discovery_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_discovery_v1_discovery_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_discovery_v1_discovery_proto_goTypes = []any{
+	(SortOrder)(0),                   // 0: discovery.v1.SortOrder
+	(RecordEventType)(0),             // 1: discovery.v1.RecordEventType
+	(*Pagination)(nil),               // 2: discovery.v1.Pagination
+	(*FindHostsRequest)(nil),         // 3: discovery.v1.FindHostsRequest
+	(*FindTrackersRequest)(nil),      // 4: discovery.v1.FindTrackersRequest
+	(*Output)(nil),                   // 5: discovery.v1.Output
+	(*LookupResponse)(nil),           // 6: discovery.v1.LookupResponse
+	(*SubscribeHostsRequest)(nil),    // 7: discovery.v1.SubscribeHostsRequest
+	(*SubscribeTrackersRequest)(nil), // 8: discovery.v1.SubscribeTrackersRequest
+	(*RecordEvent)(nil),              // 9: discovery.v1.RecordEvent
+	(*timestamppb.Timestamp)(nil),    // 10: google.protobuf.Timestamp
+}
+var file_discovery_v1_discovery_proto_depIdxs = []int32{
+	0,  // 0: discovery.v1.Pagination.sort_order:type_name -> discovery.v1.SortOrder
+	2,  // 1: discovery.v1.FindHostsRequest.pagination:type_name -> discovery.v1.Pagination
+	2,  // 2: discovery.v1.FindTrackersRequest.pagination:type_name -> discovery.v1.Pagination
+	5,  // 3: discovery.v1.LookupResponse.outputs:type_name -> discovery.v1.Output
+	1,  // 4: discovery.v1.RecordEvent.type:type_name -> discovery.v1.RecordEventType
+	10, // 5: discovery.v1.RecordEvent.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 6: discovery.v1.DiscoveryService.FindHosts:input_type -> discovery.v1.FindHostsRequest
+	4,  // 7: discovery.v1.DiscoveryService.FindTrackers:input_type -> discovery.v1.FindTrackersRequest
+	7,  // 8: discovery.v1.DiscoveryService.SubscribeHosts:input_type -> discovery.v1.SubscribeHostsRequest
+	8,  // 9: discovery.v1.DiscoveryService.SubscribeTrackers:input_type -> discovery.v1.SubscribeTrackersRequest
+	6,  // 10: discovery.v1.DiscoveryService.FindHosts:output_type -> discovery.v1.LookupResponse
+	6,  // 11: discovery.v1.DiscoveryService.FindTrackers:output_type -> discovery.v1.LookupResponse
+	9,  // 12: discovery.v1.DiscoveryService.SubscribeHosts:output_type -> discovery.v1.RecordEvent
+	9,  // 13: discovery.v1.DiscoveryService.SubscribeTrackers:output_type -> discovery.v1.RecordEvent
+	10, // [10:14] is the sub-list for method output_type
+	6,  // [6:10] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_discovery_v1_discovery_proto_init() }
+func file_discovery_v1_discovery_proto_init() {
+	if File_discovery_v1_discovery_proto != nil {
+		return
+	}
+	file_discovery_v1_discovery_proto_msgTypes[0].OneofWrappers = []any{}
+	file_discovery_v1_discovery_proto_msgTypes[1].OneofWrappers = []any{}
+	file_discovery_v1_discovery_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_discovery_v1_discovery_proto_rawDesc), len(file_discovery_v1_discovery_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_discovery_v1_discovery_proto_goTypes,
+		DependencyIndexes: file_discovery_v1_discovery_proto_depIdxs,
+		EnumInfos:         file_discovery_v1_discovery_proto_enumTypes,
+		MessageInfos:      file_discovery_v1_discovery_proto_msgTypes,
+	}.Build()
+	File_discovery_v1_discovery_proto = out.File
+	file_discovery_v1_discovery_proto_goTypes = nil
+	file_discovery_v1_discovery_proto_depIdxs = nil
+}
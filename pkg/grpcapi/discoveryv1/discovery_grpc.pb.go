@@ -0,0 +1,258 @@
+// Discovery API for SHIP host and SLAP tracker lookups and advertisement subscriptions.
+//
+// The Go code in pkg/grpcapi/discoveryv1 is generated with go generate ./pkg/grpcapi.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: discovery/v1/discovery.proto
+
+package discoveryv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DiscoveryService_FindHosts_FullMethodName         = "/discovery.v1.DiscoveryService/FindHosts"
+	DiscoveryService_FindTrackers_FullMethodName      = "/discovery.v1.DiscoveryService/FindTrackers"
+	DiscoveryService_SubscribeHosts_FullMethodName    = "/discovery.v1.DiscoveryService/SubscribeHosts"
+	DiscoveryService_SubscribeTrackers_FullMethodName = "/discovery.v1.DiscoveryService/SubscribeTrackers"
+)
+
+// DiscoveryServiceClient is the client API for DiscoveryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DiscoveryService answers SHIP and SLAP lookups and streams new advertisements.
+type DiscoveryServiceClient interface {
+	// FindHosts returns SHIP advertisements through the ls_ship lookup service.
+	FindHosts(ctx context.Context, in *FindHostsRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	// FindTrackers returns SLAP advertisements through the ls_slap lookup service.
+	FindTrackers(ctx context.Context, in *FindTrackersRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	// SubscribeHosts streams SHIP advertisements as they are admitted and removed.
+	SubscribeHosts(ctx context.Context, in *SubscribeHostsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RecordEvent], error)
+	// SubscribeTrackers streams SLAP advertisements as they are admitted and removed.
+	SubscribeTrackers(ctx context.Context, in *SubscribeTrackersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RecordEvent], error)
+}
+
+type discoveryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDiscoveryServiceClient(cc grpc.ClientConnInterface) DiscoveryServiceClient {
+	return &discoveryServiceClient{cc}
+}
+
+func (c *discoveryServiceClient) FindHosts(ctx context.Context, in *FindHostsRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, DiscoveryService_FindHosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *discoveryServiceClient) FindTrackers(ctx context.Context, in *FindTrackersRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, DiscoveryService_FindTrackers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *discoveryServiceClient) SubscribeHosts(ctx context.Context, in *SubscribeHostsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RecordEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DiscoveryService_ServiceDesc.Streams[0], DiscoveryService_SubscribeHosts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeHostsRequest, RecordEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DiscoveryService_SubscribeHostsClient = grpc.ServerStreamingClient[RecordEvent]
+
+func (c *discoveryServiceClient) SubscribeTrackers(ctx context.Context, in *SubscribeTrackersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RecordEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DiscoveryService_ServiceDesc.Streams[1], DiscoveryService_SubscribeTrackers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeTrackersRequest, RecordEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DiscoveryService_SubscribeTrackersClient = grpc.ServerStreamingClient[RecordEvent]
+
+// DiscoveryServiceServer is the server API for DiscoveryService service.
+// All implementations must embed UnimplementedDiscoveryServiceServer
+// for forward compatibility.
+//
+// DiscoveryService answers SHIP and SLAP lookups and streams new advertisements.
+type DiscoveryServiceServer interface {
+	// FindHosts returns SHIP advertisements through the ls_ship lookup service.
+	FindHosts(context.Context, *FindHostsRequest) (*LookupResponse, error)
+	// FindTrackers returns SLAP advertisements through the ls_slap lookup service.
+	FindTrackers(context.Context, *FindTrackersRequest) (*LookupResponse, error)
+	// SubscribeHosts streams SHIP advertisements as they are admitted and removed.
+	SubscribeHosts(*SubscribeHostsRequest, grpc.ServerStreamingServer[RecordEvent]) error
+	// SubscribeTrackers streams SLAP advertisements as they are admitted and removed.
+	SubscribeTrackers(*SubscribeTrackersRequest, grpc.ServerStreamingServer[RecordEvent]) error
+	mustEmbedUnimplementedDiscoveryServiceServer()
+}
+
+// UnimplementedDiscoveryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDiscoveryServiceServer struct{}
+
+func (UnimplementedDiscoveryServiceServer) FindHosts(context.Context, *FindHostsRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindHosts not implemented")
+}
+func (UnimplementedDiscoveryServiceServer) FindTrackers(context.Context, *FindTrackersRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindTrackers not implemented")
+}
+func (UnimplementedDiscoveryServiceServer) SubscribeHosts(*SubscribeHostsRequest, grpc.ServerStreamingServer[RecordEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeHosts not implemented")
+}
+func (UnimplementedDiscoveryServiceServer) SubscribeTrackers(*SubscribeTrackersRequest, grpc.ServerStreamingServer[RecordEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTrackers not implemented")
+}
+func (UnimplementedDiscoveryServiceServer) mustEmbedUnimplementedDiscoveryServiceServer() {}
+func (UnimplementedDiscoveryServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeDiscoveryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DiscoveryServiceServer will
+// result in compilation errors.
+type UnsafeDiscoveryServiceServer interface {
+	mustEmbedUnimplementedDiscoveryServiceServer()
+}
+
+func RegisterDiscoveryServiceServer(s grpc.ServiceRegistrar, srv DiscoveryServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDiscoveryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DiscoveryService_ServiceDesc, srv)
+}
+
+func _DiscoveryService_FindHosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindHostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiscoveryServiceServer).FindHosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DiscoveryService_FindHosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiscoveryServiceServer).FindHosts(ctx, req.(*FindHostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DiscoveryService_FindTrackers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindTrackersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiscoveryServiceServer).FindTrackers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DiscoveryService_FindTrackers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiscoveryServiceServer).FindTrackers(ctx, req.(*FindTrackersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DiscoveryService_SubscribeHosts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeHostsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DiscoveryServiceServer).SubscribeHosts(m, &grpc.GenericServerStream[SubscribeHostsRequest, RecordEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DiscoveryService_SubscribeHostsServer = grpc.ServerStreamingServer[RecordEvent]
+
+func _DiscoveryService_SubscribeTrackers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTrackersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DiscoveryServiceServer).SubscribeTrackers(m, &grpc.GenericServerStream[SubscribeTrackersRequest, RecordEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DiscoveryService_SubscribeTrackersServer = grpc.ServerStreamingServer[RecordEvent]
+
+// DiscoveryService_ServiceDesc is the grpc.ServiceDesc for DiscoveryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DiscoveryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "discovery.v1.DiscoveryService",
+	HandlerType: (*DiscoveryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FindHosts",
+			Handler:    _DiscoveryService_FindHosts_Handler,
+		},
+		{
+			MethodName: "FindTrackers",
+			Handler:    _DiscoveryService_FindTrackers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeHosts",
+			Handler:       _DiscoveryService_SubscribeHosts_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeTrackers",
+			Handler:       _DiscoveryService_SubscribeTrackers_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "discovery/v1/discovery.proto",
+}
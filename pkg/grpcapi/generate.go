@@ -0,0 +1,3 @@
+package grpcapi
+
+//go:generate protoc --proto_path=../../proto --go_out=. --go_opt=module=github.com/bsv-blockchain/go-overlay-discovery-services/pkg/grpcapi --go-grpc_out=. --go-grpc_opt=module=github.com/bsv-blockchain/go-overlay-discovery-services/pkg/grpcapi discovery/v1/discovery.proto
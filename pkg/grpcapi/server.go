@@ -0,0 +1,346 @@
+// Package grpcapi serves the SHIP and SLAP lookup services and topic manager subscriptions
+// over gRPC, using the DiscoveryService defined in proto/discovery/v1/discovery.proto.
+package grpcapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/grpcapi/discoveryv1"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/stream"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// eventBufferSize is the number of events buffered per subscriber before events are dropped.
+const eventBufferSize = 64
+
+// Static error variables for err113 compliance
+var (
+	errUnexpectedAnswer = errors.New("unexpected lookup answer")
+)
+
+// Server implements discoveryv1.DiscoveryServiceServer on top of the SHIP and SLAP lookup
+// services and topic managers. Lookups are translated into the JSON lookup questions the
+// lookup services answer, so results match those of the overlay engine's lookup endpoint.
+// Subscriptions deliver matching record events until the client cancels the stream; slow
+// subscribers whose buffer is full miss events rather than blocking the topic managers.
+// Response headers are sent once a subscription is registered, so clients can wait for
+// them before expecting events. SetLogger must be called before the server is registered.
+type Server struct {
+	discoveryv1.UnimplementedDiscoveryServiceServer
+
+	// shipLookup answers FindHosts (optional)
+	shipLookup *ship.LookupService
+	// slapLookup answers FindTrackers (optional)
+	slapLookup *slap.LookupService
+	// shipTopicManager publishes events for SubscribeHosts (optional)
+	shipTopicManager *ship.TopicManager
+	// slapTopicManager publishes events for SubscribeTrackers (optional)
+	slapTopicManager *slap.TopicManager
+	// logger receives lookup failures
+	logger *slog.Logger
+}
+
+// NewServer creates a gRPC discovery server. Any component may be nil, in which case the
+// methods needing it return codes.Unimplemented.
+func NewServer(shipLookup *ship.LookupService, slapLookup *slap.LookupService, shipTopicManager *ship.TopicManager, slapTopicManager *slap.TopicManager) *Server {
+	return &Server{
+		shipLookup:       shipLookup,
+		slapLookup:       slapLookup,
+		shipTopicManager: shipTopicManager,
+		slapTopicManager: slapTopicManager,
+		logger:           shared.Logger(context.Background(), nil),
+	}
+}
+
+// SetLogger sets the logger of lookup failures reported as internal errors; nil discards them.
+func (s *Server) SetLogger(logger *slog.Logger) {
+	s.logger = shared.Logger(context.Background(), logger)
+}
+
+// Register registers the discovery service on a gRPC server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	discoveryv1.RegisterDiscoveryServiceServer(registrar, s)
+}
+
+// FindHosts implements discoveryv1.DiscoveryServiceServer.
+func (s *Server) FindHosts(ctx context.Context, req *discoveryv1.FindHostsRequest) (*discoveryv1.LookupResponse, error) {
+	if s.shipLookup == nil {
+		return nil, status.Error(codes.Unimplemented, "SHIP lookups are not served")
+	}
+
+	query := types.SHIPQuery{
+		Domain:      req.Domain,
+		Topics:      req.GetTopics(),
+		IdentityKey: req.IdentityKey,
+	}
+	if findAll := req.GetFindAll(); findAll {
+		query.FindAll = &findAll
+	}
+	query.Limit, query.Skip, query.SortOrder = pagination(req.GetPagination())
+
+	return s.lookupOutputs(ctx, s.shipLookup, ship.Service, query)
+}
+
+// FindTrackers implements discoveryv1.DiscoveryServiceServer.
+func (s *Server) FindTrackers(ctx context.Context, req *discoveryv1.FindTrackersRequest) (*discoveryv1.LookupResponse, error) {
+	if s.slapLookup == nil {
+		return nil, status.Error(codes.Unimplemented, "SLAP lookups are not served")
+	}
+
+	query := types.SLAPQuery{
+		Domain:      req.Domain,
+		Service:     req.Service,
+		IdentityKey: req.IdentityKey,
+	}
+	if findAll := req.GetFindAll(); findAll {
+		query.FindAll = &findAll
+	}
+	query.Limit, query.Skip, query.SortOrder = pagination(req.GetPagination())
+
+	return s.lookupOutputs(ctx, s.slapLookup, slap.Service, query)
+}
+
+// SubscribeHosts implements discoveryv1.DiscoveryServiceServer.
+func (s *Server) SubscribeHosts(req *discoveryv1.SubscribeHostsRequest, srv grpc.ServerStreamingServer[discoveryv1.RecordEvent]) error {
+	if s.shipTopicManager == nil {
+		return status.Error(codes.Unimplemented, "SHIP subscriptions are not served")
+	}
+
+	filter := stream.Filter{
+		Protocol:    stream.ProtocolSHIP,
+		Topic:       req.GetTopic(),
+		Domain:      slap.CanonicalDomainPattern(req.GetDomain()),
+		IdentityKey: req.GetIdentityKey(),
+	}
+	events := make(chan types.RecordEvent, eventBufferSize)
+
+	ctx := srv.Context()
+	id, err := s.shipTopicManager.AddTopicHandler(ctx, s.shipTopicManager.Topic(), func(_ context.Context, message ship.TopicMessage) error {
+		queueEvent(events, filter, message.Payload)
+		return nil
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to subscribe to SHIP topic: %v", err)
+	}
+	defer func() {
+		_ = s.shipTopicManager.RemoveTopicHandler(context.WithoutCancel(ctx), id)
+	}()
+
+	return forwardEvents(ctx, events, srv)
+}
+
+// SubscribeTrackers implements discoveryv1.DiscoveryServiceServer.
+func (s *Server) SubscribeTrackers(req *discoveryv1.SubscribeTrackersRequest, srv grpc.ServerStreamingServer[discoveryv1.RecordEvent]) error {
+	if s.slapTopicManager == nil {
+		return status.Error(codes.Unimplemented, "SLAP subscriptions are not served")
+	}
+
+	service, domain := req.GetService(), slap.CanonicalDomainPattern(req.GetDomain())
+	if err := slap.ValidateSubscriptionPattern(service, domain); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	filter := stream.Filter{
+		Protocol:    stream.ProtocolSLAP,
+		Service:     service,
+		Domain:      domain,
+		IdentityKey: req.GetIdentityKey(),
+	}
+	if service == "" {
+		service = slap.WildcardService
+	}
+	if domain == "" {
+		domain = slap.WildcardDomain
+	}
+	events := make(chan types.RecordEvent, eventBufferSize)
+
+	ctx := srv.Context()
+	id, err := s.slapTopicManager.AddServiceHandler(ctx, service, domain, func(_ context.Context, message slap.ServiceMessage) error {
+		queueEvent(events, filter, message.Payload)
+		return nil
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to subscribe to SLAP service: %v", err)
+	}
+	defer func() {
+		_ = s.slapTopicManager.RemoveServiceHandler(context.WithoutCancel(ctx), id)
+	}()
+
+	return forwardEvents(ctx, events, srv)
+}
+
+// pagination converts request pagination to lookup query fields. Unset fields are left nil.
+func pagination(page *discoveryv1.Pagination) (*int, *int, *types.SortOrder) {
+	if page == nil {
+		return nil, nil, nil
+	}
+
+	var limit, skip *int
+	if page.Limit != nil {
+		v := int(page.GetLimit())
+		limit = &v
+	}
+	if page.Skip != nil {
+		v := int(page.GetSkip())
+		skip = &v
+	}
+
+	var order types.SortOrder
+	switch page.GetSortOrder() {
+	case discoveryv1.SortOrder_SORT_ORDER_ASC:
+		order = types.SortOrderAsc
+	case discoveryv1.SortOrder_SORT_ORDER_DESC:
+		order = types.SortOrderDesc
+	case discoveryv1.SortOrder_SORT_ORDER_UNSPECIFIED:
+		return limit, skip, nil
+	}
+
+	return limit, skip, &order
+}
+
+// lookupOutputs asks the lookup service the query as a JSON lookup question and converts
+// the answer to a LookupResponse.
+func (s *Server) lookupOutputs(ctx context.Context, service engineLookup, serviceName string, query any) (*discoveryv1.LookupResponse, error) {
+	raw, err := json.Marshal(query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode query: %v", err)
+	}
+
+	answer, err := service.Lookup(ctx, &lookup.LookupQuestion{Service: serviceName, Query: raw})
+	if err != nil {
+		return nil, s.lookupStatus(serviceName, err)
+	}
+
+	outputs, err := answerOutputs(answer)
+	if err != nil {
+		s.logger.Error("Failed to convert lookup answer", shared.LogKeyService, serviceName, shared.LogKeyError, err)
+		return nil, status.Error(codes.Internal, "failed to convert lookup answer")
+	}
+	return &discoveryv1.LookupResponse{Outputs: outputs}, nil
+}
+
+// engineLookup is the lookup method shared by the SHIP and SLAP lookup services.
+type engineLookup interface {
+	Lookup(ctx context.Context, question *lookup.LookupQuestion) (*lookup.LookupAnswer, error)
+}
+
+// lookupStatus maps a lookup failure to a gRPC status, reporting query validation failures
+// as invalid arguments and hiding the details of storage failures.
+func (s *Server) lookupStatus(serviceName string, err error) error {
+	if shared.IsInvalidQuery(err) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	s.logger.Error("Lookup failed", shared.LogKeyService, serviceName, shared.LogKeyError, err)
+	return status.Error(codes.Internal, "lookup failed")
+}
+
+// answerOutputs converts a lookup answer to outputs. Freeform answers carry UTXO references;
+// output-list answers carry the Atomic BEEF of each output's transaction.
+func answerOutputs(answer *lookup.LookupAnswer) ([]*discoveryv1.Output, error) {
+	switch answer.Type {
+	case lookup.AnswerTypeFreeform:
+		refs, ok := answer.Result.([]types.UTXOReference)
+		if !ok {
+			return nil, fmt.Errorf("%w: freeform result of type %T", errUnexpectedAnswer, answer.Result)
+		}
+		outputs := make([]*discoveryv1.Output, len(refs))
+		for i, ref := range refs {
+			outputs[i] = &discoveryv1.Output{Txid: ref.Txid, OutputIndex: uint32(ref.OutputIndex)} //nolint:gosec // output indexes are validated on admission
+		}
+		return outputs, nil
+
+	case lookup.AnswerTypeOutputList:
+		outputs := make([]*discoveryv1.Output, len(answer.Outputs))
+		for i, item := range answer.Outputs {
+			tx, err := transaction.NewTransactionFromBEEF(item.Beef)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse output BEEF: %w", err)
+			}
+			outputs[i] = &discoveryv1.Output{
+				// Match the txid encoding of stored records and freeform answers
+				Txid:        hex.EncodeToString(tx.TxID().CloneBytes()),
+				OutputIndex: item.OutputIndex,
+				Beef:        item.Beef,
+			}
+		}
+		return outputs, nil
+
+	case lookup.AnswerTypeFormula:
+	}
+	return nil, fmt.Errorf("%w: answer type %q", errUnexpectedAnswer, answer.Type)
+}
+
+// queueEvent queues a topic manager message payload for a subscriber if it is a record event
+// matching the filter, dropping it if the subscriber is not keeping up.
+func queueEvent(events chan<- types.RecordEvent, filter stream.Filter, payload any) {
+	event, ok := payload.(types.RecordEvent)
+	if !ok || !filter.Matches(event) {
+		return
+	}
+	select {
+	case events <- event:
+	default:
+		// Subscriber is not keeping up; drop the event rather than block delivery
+	}
+}
+
+// forwardEvents acknowledges the subscription with response headers, then sends queued
+// events to the client until it cancels the stream.
+func forwardEvents(ctx context.Context, events <-chan types.RecordEvent, srv grpc.ServerStreamingServer[discoveryv1.RecordEvent]) error {
+	if err := srv.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if err := srv.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toProtoEvent converts a record event to its protobuf form.
+func toProtoEvent(event types.RecordEvent) *discoveryv1.RecordEvent {
+	eventType := discoveryv1.RecordEventType_RECORD_EVENT_TYPE_UNSPECIFIED
+	switch event.Type {
+	case types.RecordEventAdmitted:
+		eventType = discoveryv1.RecordEventType_RECORD_EVENT_TYPE_ADMITTED
+	case types.RecordEventRemoved:
+		eventType = discoveryv1.RecordEventType_RECORD_EVENT_TYPE_REMOVED
+	}
+
+	return &discoveryv1.RecordEvent{
+		Type:        eventType,
+		Protocol:    event.Protocol,
+		Txid:        event.Txid,
+		OutputIndex: uint32(event.OutputIndex), //nolint:gosec // output indexes are validated on admission
+		IdentityKey: event.IdentityKey,
+		Domain:      event.Domain,
+		Topic:       event.Topic,
+		Service:     event.Service,
+		Timestamp:   timestamppb.New(event.Timestamp),
+	}
+}
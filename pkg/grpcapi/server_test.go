@@ -0,0 +1,247 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/grpcapi/discoveryv1"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/memstore"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Test helper functions
+
+// startServer serves the discovery server over an in-process bufconn listener and returns a
+// client connected to it.
+func startServer(t *testing.T, server *Server) discoveryv1.DiscoveryServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return discoveryv1.NewDiscoveryServiceClient(conn)
+}
+
+func createTestServer(t *testing.T) (discoveryv1.DiscoveryServiceClient, *ship.TopicManager, *slap.TopicManager) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	shipStorage := memstore.NewSHIPStorage()
+	require.NoError(t, shipStorage.ImportRecords(ctx, []types.SHIPRecord{
+		{Txid: "aa", OutputIndex: 0, IdentityKey: "alice", Domain: "https://a.example.com", Topic: "tm_meter", CreatedAt: base},
+		{Txid: "bb", OutputIndex: 1, IdentityKey: "bob", Domain: "https://b.example.com", Topic: "tm_meter", CreatedAt: base.Add(time.Minute)},
+		{Txid: "cc", OutputIndex: 0, IdentityKey: "alice", Domain: "https://a.example.com", Topic: "tm_chat", CreatedAt: base.Add(2 * time.Minute)},
+	}))
+	slapStorage := memstore.NewSLAPStorage()
+	require.NoError(t, slapStorage.ImportRecords(ctx, []types.SLAPRecord{
+		{Txid: "dd", OutputIndex: 2, IdentityKey: "carol", Domain: "https://c.example.com", Service: "ls_meter", CreatedAt: base},
+	}))
+
+	shipTopicManager := ship.NewTopicManager(nil, nil)
+	slapTopicManager := slap.NewTopicManager(nil, nil)
+	server := NewServer(ship.NewLookupService(shipStorage), slap.NewLookupService(slapStorage), shipTopicManager, slapTopicManager)
+
+	return startServer(t, server), shipTopicManager, slapTopicManager
+}
+
+func outpoints(response *discoveryv1.LookupResponse) []string {
+	ids := make([]string, len(response.GetOutputs()))
+	for i, output := range response.GetOutputs() {
+		ids[i] = output.GetTxid()
+	}
+	return ids
+}
+
+// Test lookups
+
+func TestFindHosts(t *testing.T) {
+	client, _, _ := createTestServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *discoveryv1.FindHostsRequest
+		want []string
+	}{
+		{"find all newest first", &discoveryv1.FindHostsRequest{FindAll: true}, []string{"cc", "bb", "aa"}},
+		{"topic filter", &discoveryv1.FindHostsRequest{Topics: []string{"tm_meter"}}, []string{"bb", "aa"}},
+		{"identity and domain filter", &discoveryv1.FindHostsRequest{
+			IdentityKey: proto.String("alice"),
+			Domain:      proto.String("https://a.example.com"),
+		}, []string{"cc", "aa"}},
+		{"pagination", &discoveryv1.FindHostsRequest{FindAll: true, Pagination: &discoveryv1.Pagination{
+			Limit:     proto.Int32(1),
+			Skip:      proto.Int32(1),
+			SortOrder: discoveryv1.SortOrder_SORT_ORDER_ASC,
+		}}, []string{"bb"}},
+		{"no matches", &discoveryv1.FindHostsRequest{Topics: []string{"tm_unknown"}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := client.FindHosts(ctx, tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, outpoints(response))
+		})
+	}
+}
+
+func TestFindTrackers(t *testing.T) {
+	client, _, _ := createTestServer(t)
+
+	response, err := client.FindTrackers(context.Background(), &discoveryv1.FindTrackersRequest{Service: proto.String("ls_meter")})
+	require.NoError(t, err)
+	require.Len(t, response.GetOutputs(), 1)
+	assert.Equal(t, "dd", response.GetOutputs()[0].GetTxid())
+	assert.Equal(t, uint32(2), response.GetOutputs()[0].GetOutputIndex())
+	assert.Empty(t, response.GetOutputs()[0].GetBeef())
+}
+
+func TestFind_InvalidPagination(t *testing.T) {
+	client, _, _ := createTestServer(t)
+
+	_, err := client.FindHosts(context.Background(), &discoveryv1.FindHostsRequest{
+		Pagination: &discoveryv1.Pagination{Skip: proto.Int32(-1)},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.FindTrackers(context.Background(), &discoveryv1.FindTrackersRequest{
+		Pagination: &discoveryv1.Pagination{Limit: proto.Int32(-5)},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// Test subscriptions
+
+func TestSubscribeHosts(t *testing.T) {
+	client, shipTopicManager, _ := createTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.SubscribeHosts(ctx, &discoveryv1.SubscribeHostsRequest{Topic: "tm_meter"})
+	require.NoError(t, err)
+	_, err = events.Header()
+	require.NoError(t, err)
+
+	for _, record := range []types.SHIPRecord{
+		{Txid: "ee", IdentityKey: "alice", Domain: "https://a.example.com", Topic: "tm_chat"},
+		{Txid: "ff", OutputIndex: 3, IdentityKey: "bob", Domain: "https://b.example.com", Topic: "tm_meter"},
+	} {
+		event := record.Event(types.RecordEventAdmitted)
+		event.Timestamp = time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, shipTopicManager.HandleTopicMessage(ctx, ship.TopicMessage{Topic: ship.Topic, Payload: event}))
+	}
+
+	event, err := events.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, discoveryv1.RecordEventType_RECORD_EVENT_TYPE_ADMITTED, event.GetType())
+	assert.Equal(t, "SHIP", event.GetProtocol())
+	assert.Equal(t, "ff", event.GetTxid())
+	assert.Equal(t, uint32(3), event.GetOutputIndex())
+	assert.Equal(t, "tm_meter", event.GetTopic())
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), event.GetTimestamp().AsTime())
+
+	// Ending the stream removes the subscription's handler
+	cancel()
+	_, err = events.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestSubscribeHosts_ConfiguredTopic(t *testing.T) {
+	shipTopicManager, err := ship.NewTopicManagerWithOptions(nil, nil, shared.WithTopic("tm_ship_test"))
+	require.NoError(t, err)
+	client := startServer(t, NewServer(nil, nil, shipTopicManager, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.SubscribeHosts(ctx, &discoveryv1.SubscribeHostsRequest{})
+	require.NoError(t, err)
+	_, err = events.Header()
+	require.NoError(t, err)
+	assert.True(t, shipTopicManager.IsSubscribedToTopic("tm_ship_test"))
+
+	event := types.SHIPRecord{Txid: "ee", Domain: "https://a.example.com", Topic: "tm_chat"}.Event(types.RecordEventAdmitted)
+	require.NoError(t, shipTopicManager.HandleTopicMessage(ctx, ship.TopicMessage{Topic: "tm_ship_test", Payload: event}))
+
+	received, err := events.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "ee", received.GetTxid())
+}
+
+func TestSubscribeTrackers(t *testing.T) {
+	client, _, slapTopicManager := createTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.SubscribeTrackers(ctx, &discoveryv1.SubscribeTrackersRequest{Service: "ls_meter"})
+	require.NoError(t, err)
+	_, err = events.Header()
+	require.NoError(t, err)
+	assert.True(t, slapTopicManager.IsSubscribedToService("ls_meter", slap.WildcardDomain))
+
+	event := types.SLAPRecord{Txid: "gg", IdentityKey: "carol", Domain: "https://c.example.com", Service: "ls_meter"}.Event(types.RecordEventRemoved)
+	require.NoError(t, slapTopicManager.HandleServiceMessage(ctx, slap.ServiceMessage{
+		Service: "ls_meter",
+		Domain:  "https://c.example.com",
+		Payload: event,
+	}))
+
+	received, err := events.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, discoveryv1.RecordEventType_RECORD_EVENT_TYPE_REMOVED, received.GetType())
+	assert.Equal(t, "gg", received.GetTxid())
+	assert.Equal(t, "ls_meter", received.GetService())
+}
+
+func TestSubscribeTrackers_InvalidPattern(t *testing.T) {
+	client, _, _ := createTestServer(t)
+
+	events, err := client.SubscribeTrackers(context.Background(), &discoveryv1.SubscribeTrackersRequest{Service: "ls_*_meter"})
+	require.NoError(t, err)
+	_, err = events.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// Test missing components
+
+func TestServer_Unimplemented(t *testing.T) {
+	client := startServer(t, NewServer(nil, nil, nil, nil))
+	ctx := context.Background()
+
+	_, err := client.FindHosts(ctx, &discoveryv1.FindHostsRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = client.FindTrackers(ctx, &discoveryv1.FindTrackersRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	events, err := client.SubscribeHosts(ctx, &discoveryv1.SubscribeHostsRequest{})
+	require.NoError(t, err)
+	_, err = events.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,64 @@
+// Package tokentest builds signed SHIP and SLAP advertisement transactions for tests.
+package tokentest
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/pushdrop"
+	"github.com/bsv-blockchain/go-sdk/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+// Advertisement is the domain and topic or service advertised by one output.
+type Advertisement struct {
+	// Domain is the advertised URI
+	Domain string
+	// Name is the advertised topic (SHIP) or service (SLAP)
+	Name string
+}
+
+// SignedBEEF returns a transaction with one correctly signed PushDrop output per advertisement,
+// all advertised by the same fresh identity key, along with its txid and the hex identity key.
+func SignedBEEF(t testing.TB, protocol overlay.Protocol, advertisements ...Advertisement) (*transaction.Beef, *chainhash.Hash, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	signerWallet, err := wallet.NewCompletedProtoWallet(key)
+	require.NoError(t, err)
+	identity, err := signerWallet.GetPublicKey(ctx, wallet.GetPublicKeyArgs{IdentityKey: true}, "")
+	require.NoError(t, err)
+
+	pushDrop := &pushdrop.PushDrop{Wallet: signerWallet}
+	tx := transaction.NewTransaction()
+	for _, advertisement := range advertisements {
+		fields := [][]byte{[]byte(protocol), identity.PublicKey.ToDER(), []byte(advertisement.Domain), []byte(advertisement.Name)}
+		lockingScript, err := pushDrop.Lock(ctx, fields, wallet.Protocol{
+			SecurityLevel: wallet.SecurityLevelEveryAppAndCounterparty,
+			Protocol:      string(protocol.ID()),
+		}, "1", wallet.Counterparty{Type: wallet.CounterpartyTypeAnyone}, true, true, pushdrop.LockBefore)
+		require.NoError(t, err)
+		tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1, LockingScript: lockingScript})
+	}
+
+	beef, err := transaction.NewBeefFromTransaction(tx)
+	require.NoError(t, err)
+
+	return beef, tx.TxID(), hex.EncodeToString(identity.PublicKey.ToDER())
+}
+
+// SignedAtomicBEEF returns the Atomic BEEF of a transaction built by SignedBEEF.
+func SignedAtomicBEEF(t testing.TB, protocol overlay.Protocol, advertisements ...Advertisement) []byte {
+	t.Helper()
+	beef, txid, _ := SignedBEEF(t, protocol, advertisements...)
+	atomic, err := beef.AtomicBytes(txid)
+	require.NoError(t, err)
+	return atomic
+}
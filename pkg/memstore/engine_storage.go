@@ -0,0 +1,390 @@
+// Package memstore provides in-memory storage backends for running the discovery services
+// without a database: an overlay engine output store and SHIP and SLAP record stores.
+// They are intended for local development, tests and demos; nothing survives a restart.
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Compile-time verification that EngineStorage implements engine.Storage
+var _ engine.Storage = (*EngineStorage)(nil)
+
+// outputKey identifies an output admitted into a topic. The same outpoint may be admitted
+// into several topics, each with its own spent state and history links.
+type outputKey struct {
+	outpoint transaction.Outpoint
+	topic    string
+}
+
+// appliedKey identifies a transaction applied to a topic.
+type appliedKey struct {
+	txid  chainhash.Hash
+	topic string
+}
+
+// interactionKey identifies the last GASP interaction with a peer for a topic.
+type interactionKey struct {
+	host  string
+	topic string
+}
+
+// EngineStorage is an in-memory implementation of the overlay engine's output storage.
+// Each transaction's BEEF is stored once and shared by its outputs in every topic.
+// It is safe for concurrent use.
+type EngineStorage struct {
+	mu           sync.RWMutex
+	outputs      map[outputKey]*engine.Output
+	beefs        map[chainhash.Hash]*transaction.Beef
+	applied      map[appliedKey]struct{}
+	interactions map[interactionKey]float64
+}
+
+// NewEngineStorage creates an empty in-memory engine storage.
+func NewEngineStorage() *EngineStorage {
+	return &EngineStorage{
+		outputs:      make(map[outputKey]*engine.Output),
+		beefs:        make(map[chainhash.Hash]*transaction.Beef),
+		applied:      make(map[appliedKey]struct{}),
+		interactions: make(map[interactionKey]float64),
+	}
+}
+
+// InsertOutputs adds the admitted outputs of a transaction to a topic. Outputs of mined
+// transactions record the block height and merkle root of their proof as validated.
+func (s *EngineStorage) InsertOutputs(_ context.Context, topic string, txid *chainhash.Hash, outputs []uint32, outpointsConsumed []*transaction.Outpoint, beef *transaction.Beef, ancillaryTxids []*chainhash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if beef != nil {
+		s.beefs[*txid] = beef
+	}
+
+	var (
+		blockHeight uint32
+		merkleRoot  *chainhash.Hash
+		merkleState = engine.MerkleStateUnmined
+	)
+	if beef != nil {
+		if tx := beef.FindTransactionByHash(txid); tx != nil && tx.MerklePath != nil {
+			root, err := tx.MerklePath.ComputeRoot(txid)
+			if err != nil {
+				return fmt.Errorf("failed to compute merkle root for %s: %w", txid, err)
+			}
+			blockHeight = tx.MerklePath.BlockHeight
+			merkleRoot = root
+			merkleState = engine.MerkleStateValidated
+		}
+	}
+
+	score := float64(time.Now().UnixMilli())
+	for _, vout := range outputs {
+		outpoint := transaction.Outpoint{Txid: *txid, Index: vout}
+		s.outputs[outputKey{outpoint: outpoint, topic: topic}] = &engine.Output{
+			Outpoint:        outpoint,
+			Topic:           topic,
+			OutputsConsumed: slices.Clone(outpointsConsumed),
+			BlockHeight:     blockHeight,
+			Score:           score,
+			AncillaryTxids:  slices.Clone(ancillaryTxids),
+			MerkleRoot:      merkleRoot,
+			MerkleState:     merkleState,
+		}
+	}
+
+	return nil
+}
+
+// FindOutput returns the output at the outpoint, optionally restricted to a topic and spent
+// state. Without a topic the output from any topic may be returned. Returns nil (no error)
+// if no such output exists.
+func (s *EngineStorage) FindOutput(_ context.Context, outpoint *transaction.Outpoint, topic *string, spent *bool, includeBEEF bool) (*engine.Output, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.findOutput(*outpoint, topic, spent, includeBEEF), nil
+}
+
+// FindOutputs returns the outputs at the outpoints in a topic. The result is aligned with
+// outpoints, holding nil for each outpoint without a matching output.
+func (s *EngineStorage) FindOutputs(_ context.Context, outpoints []*transaction.Outpoint, topic string, spent *bool, includeBEEF bool) ([]*engine.Output, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]*engine.Output, len(outpoints))
+	for i, outpoint := range outpoints {
+		results[i] = s.findOutput(*outpoint, &topic, spent, includeBEEF)
+	}
+	return results, nil
+}
+
+// FindOutputsForTransaction returns every output of the transaction in every topic.
+func (s *EngineStorage) FindOutputsForTransaction(_ context.Context, txid *chainhash.Hash, includeBEEF bool) ([]*engine.Output, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []*engine.Output
+	for key, output := range s.outputs {
+		if key.outpoint.Txid == *txid {
+			results = append(results, s.copyOutput(output, includeBEEF))
+		}
+	}
+	sortOutputs(results)
+	return results, nil
+}
+
+// FindUTXOsForTopic returns the unspent outputs of a topic admitted at or after the since
+// score, oldest first, returning at most limit outputs when limit is greater than zero.
+func (s *EngineStorage) FindUTXOsForTopic(_ context.Context, topic string, since float64, limit uint32, includeBEEF bool) ([]*engine.Output, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []*engine.Output
+	for key, output := range s.outputs {
+		if key.topic == topic && !output.Spent && output.Score >= since {
+			results = append(results, s.copyOutput(output, includeBEEF))
+		}
+	}
+	sortOutputs(results)
+	if limit > 0 && len(results) > int(limit) {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// DeleteOutput removes an output from a topic. The transaction's BEEF is dropped once no
+// output in any topic refers to it.
+func (s *EngineStorage) DeleteOutput(_ context.Context, outpoint *transaction.Outpoint, topic string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.outputs, outputKey{outpoint: *outpoint, topic: topic})
+	for key := range s.outputs {
+		if key.outpoint.Txid == outpoint.Txid {
+			return nil
+		}
+	}
+	delete(s.beefs, outpoint.Txid)
+	return nil
+}
+
+// MarkUTXOsAsSpent marks the outputs at the outpoints in a topic as spent.
+func (s *EngineStorage) MarkUTXOsAsSpent(_ context.Context, outpoints []*transaction.Outpoint, topic string, _ *chainhash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, outpoint := range outpoints {
+		if output, ok := s.outputs[outputKey{outpoint: *outpoint, topic: topic}]; ok {
+			output.Spent = true
+		}
+	}
+	return nil
+}
+
+// UpdateConsumedBy replaces the outputs recorded as consuming an output in a topic.
+func (s *EngineStorage) UpdateConsumedBy(_ context.Context, outpoint *transaction.Outpoint, topic string, consumedBy []*transaction.Outpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if output, ok := s.outputs[outputKey{outpoint: *outpoint, topic: topic}]; ok {
+		output.ConsumedBy = slices.Clone(consumedBy)
+	}
+	return nil
+}
+
+// UpdateTransactionBEEF replaces the stored BEEF of a transaction.
+func (s *EngineStorage) UpdateTransactionBEEF(_ context.Context, txid *chainhash.Hash, beef *transaction.Beef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.beefs[*txid] = beef
+	return nil
+}
+
+// UpdateOutputBlockHeight records the block an output's transaction was mined in.
+func (s *EngineStorage) UpdateOutputBlockHeight(_ context.Context, outpoint *transaction.Outpoint, topic string, blockHeight uint32, blockIndex uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if output, ok := s.outputs[outputKey{outpoint: *outpoint, topic: topic}]; ok {
+		output.BlockHeight = blockHeight
+		output.BlockIdx = blockIndex
+	}
+	return nil
+}
+
+// InsertAppliedTransaction records that a transaction was applied to a topic.
+func (s *EngineStorage) InsertAppliedTransaction(_ context.Context, tx *overlay.AppliedTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.applied[appliedKey{txid: *tx.Txid, topic: tx.Topic}] = struct{}{}
+	return nil
+}
+
+// DoesAppliedTransactionExist reports whether a transaction was already applied to a topic.
+func (s *EngineStorage) DoesAppliedTransactionExist(_ context.Context, tx *overlay.AppliedTransaction) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.applied[appliedKey{txid: *tx.Txid, topic: tx.Topic}]
+	return ok, nil
+}
+
+// UpdateLastInteraction records the score of the last synchronization with a peer for a topic.
+func (s *EngineStorage) UpdateLastInteraction(_ context.Context, host, topic string, since float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.interactions[interactionKey{host: host, topic: topic}] = since
+	return nil
+}
+
+// GetLastInteraction returns the score of the last synchronization with a peer for a topic,
+// or 0 if there has been none.
+func (s *EngineStorage) GetLastInteraction(_ context.Context, host, topic string) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.interactions[interactionKey{host: host, topic: topic}], nil
+}
+
+// FindOutpointsByMerkleState returns the outpoints of a topic in the given merkle validation
+// state, returning at most limit outpoints when limit is greater than zero.
+func (s *EngineStorage) FindOutpointsByMerkleState(_ context.Context, topic string, state engine.MerkleState, limit uint32) ([]*transaction.Outpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []*engine.Output
+	for key, output := range s.outputs {
+		if key.topic == topic && output.MerkleState == state {
+			matches = append(matches, output)
+		}
+	}
+	sortOutputs(matches)
+	if limit > 0 && len(matches) > int(limit) {
+		matches = matches[:limit]
+	}
+
+	outpoints := make([]*transaction.Outpoint, len(matches))
+	for i, output := range matches {
+		outpoint := output.Outpoint
+		outpoints[i] = &outpoint
+	}
+	return outpoints, nil
+}
+
+// ReconcileMerkleRoot compares the merkle roots of a topic's outputs mined at the block height
+// with the authoritative root, marking matching outputs validated and the others invalidated.
+// Outputs without a merkle root are left unmined.
+func (s *EngineStorage) ReconcileMerkleRoot(_ context.Context, topic string, blockHeight uint32, merkleRoot *chainhash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, output := range s.outputs {
+		if key.topic != topic || output.BlockHeight != blockHeight || output.MerkleRoot == nil {
+			continue
+		}
+		if output.MerkleRoot.IsEqual(merkleRoot) {
+			if output.MerkleState != engine.MerkleStateImmutable {
+				output.MerkleState = engine.MerkleStateValidated
+			}
+		} else {
+			output.MerkleState = engine.MerkleStateInvalidated
+		}
+	}
+	return nil
+}
+
+// LoadAncillaryBeef merges the stored BEEF of each of the output's ancillary transactions
+// into its BEEF.
+func (s *EngineStorage) LoadAncillaryBeef(_ context.Context, output *engine.Output) error {
+	if output == nil || output.Beef == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, txid := range output.AncillaryTxids {
+		beef, ok := s.beefs[*txid]
+		if !ok || output.Beef.FindTransactionByHash(txid) != nil {
+			continue
+		}
+		if err := output.Beef.MergeBeef(beef); err != nil {
+			return fmt.Errorf("failed to merge ancillary BEEF %s: %w", txid, err)
+		}
+	}
+	return nil
+}
+
+// findOutput returns a copy of the matching output, or nil. The caller must hold the lock.
+func (s *EngineStorage) findOutput(outpoint transaction.Outpoint, topic *string, spent *bool, includeBEEF bool) *engine.Output {
+	var candidates []*engine.Output
+	if topic != nil {
+		if output, ok := s.outputs[outputKey{outpoint: outpoint, topic: *topic}]; ok {
+			candidates = append(candidates, output)
+		}
+	} else {
+		for key, output := range s.outputs {
+			if key.outpoint == outpoint {
+				candidates = append(candidates, output)
+			}
+		}
+		sortOutputs(candidates)
+	}
+
+	for _, output := range candidates {
+		if spent == nil || output.Spent == *spent {
+			return s.copyOutput(output, includeBEEF)
+		}
+	}
+	return nil
+}
+
+// copyOutput returns a copy of a stored output, so callers cannot modify storage through it.
+// The caller must hold the lock.
+func (s *EngineStorage) copyOutput(output *engine.Output, includeBEEF bool) *engine.Output {
+	result := *output
+	result.OutputsConsumed = slices.Clone(output.OutputsConsumed)
+	result.ConsumedBy = slices.Clone(output.ConsumedBy)
+	result.AncillaryTxids = slices.Clone(output.AncillaryTxids)
+	result.Beef = nil
+	if beef, ok := s.beefs[output.Outpoint.Txid]; includeBEEF && ok {
+		result.Beef = beef.Clone()
+	}
+	return &result
+}
+
+// sortOutputs orders outputs oldest first, breaking ties by outpoint and topic so results are
+// deterministic.
+func sortOutputs(outputs []*engine.Output) {
+	slices.SortFunc(outputs, func(a, b *engine.Output) int {
+		switch {
+		case a.Score != b.Score:
+			if a.Score < b.Score {
+				return -1
+			}
+			return 1
+		case a.Outpoint != b.Outpoint:
+			if a.Outpoint.String() < b.Outpoint.String() {
+				return -1
+			}
+			return 1
+		case a.Topic < b.Topic:
+			return -1
+		case a.Topic > b.Topic:
+			return 1
+		}
+		return 0
+	})
+}
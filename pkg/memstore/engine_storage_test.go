@@ -0,0 +1,172 @@
+package memstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBeef returns a BEEF holding a transaction with the given number of outputs.
+func newTestBeef(t *testing.T, outputs int) (*transaction.Beef, *chainhash.Hash) {
+	t.Helper()
+	tx := transaction.NewTransaction()
+	for i := 0; i < outputs; i++ {
+		tx.AddOutput(&transaction.TransactionOutput{
+			Satoshis:      uint64(i + 1), //nolint:gosec // small test values
+			LockingScript: &script.Script{script.OpTRUE},
+		})
+	}
+	beef, err := transaction.NewBeefFromTransaction(tx)
+	require.NoError(t, err)
+	return beef, tx.TxID()
+}
+
+func TestEngineStorage_InsertAndFind(t *testing.T) {
+	ctx := context.Background()
+	storage := NewEngineStorage()
+	beef, txid := newTestBeef(t, 2)
+
+	require.NoError(t, storage.InsertOutputs(ctx, "tm_ship", txid, []uint32{0, 1}, nil, beef, nil))
+
+	output, err := storage.FindOutput(ctx, &transaction.Outpoint{Txid: *txid, Index: 1}, nil, nil, true)
+	require.NoError(t, err)
+	require.NotNil(t, output)
+	assert.Equal(t, "tm_ship", output.Topic)
+	assert.Equal(t, engine.MerkleStateUnmined, output.MerkleState)
+	require.NotNil(t, output.Beef)
+	assert.NotNil(t, output.Beef.FindTransactionByHash(txid))
+
+	withoutBeef, err := storage.FindOutput(ctx, &output.Outpoint, nil, nil, false)
+	require.NoError(t, err)
+	assert.Nil(t, withoutBeef.Beef)
+
+	otherTopic := "tm_slap"
+	missing, err := storage.FindOutput(ctx, &output.Outpoint, &otherTopic, nil, false)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	outputs, err := storage.FindOutputs(ctx, []*transaction.Outpoint{
+		{Txid: *txid, Index: 0},
+		{Txid: *txid, Index: 7},
+	}, "tm_ship", nil, false)
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+	assert.NotNil(t, outputs[0])
+	assert.Nil(t, outputs[1])
+
+	forTx, err := storage.FindOutputsForTransaction(ctx, txid, false)
+	require.NoError(t, err)
+	assert.Len(t, forTx, 2)
+}
+
+func TestEngineStorage_SpendAndDelete(t *testing.T) {
+	ctx := context.Background()
+	storage := NewEngineStorage()
+	beef, txid := newTestBeef(t, 2)
+	require.NoError(t, storage.InsertOutputs(ctx, "tm_ship", txid, []uint32{0, 1}, nil, beef, nil))
+
+	spentOutpoint := &transaction.Outpoint{Txid: *txid, Index: 0}
+	require.NoError(t, storage.MarkUTXOsAsSpent(ctx, []*transaction.Outpoint{spentOutpoint}, "tm_ship", txid))
+
+	utxos, err := storage.FindUTXOsForTopic(ctx, "tm_ship", 0, 0, false)
+	require.NoError(t, err)
+	require.Len(t, utxos, 1)
+	assert.Equal(t, uint32(1), utxos[0].Outpoint.Index)
+
+	unspent := false
+	found, err := storage.FindOutput(ctx, spentOutpoint, nil, &unspent, false)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	// The BEEF is kept until the last output of the transaction is deleted
+	require.NoError(t, storage.DeleteOutput(ctx, spentOutpoint, "tm_ship"))
+	remaining, err := storage.FindOutput(ctx, &transaction.Outpoint{Txid: *txid, Index: 1}, nil, nil, true)
+	require.NoError(t, err)
+	assert.NotNil(t, remaining.Beef)
+
+	require.NoError(t, storage.DeleteOutput(ctx, &remaining.Outpoint, "tm_ship"))
+	assert.Empty(t, storage.beefs)
+}
+
+func TestEngineStorage_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	storage := NewEngineStorage()
+	beef, txid := newTestBeef(t, 1)
+	require.NoError(t, storage.InsertOutputs(ctx, "tm_ship", txid, []uint32{0}, nil, beef, nil))
+
+	outpoint := &transaction.Outpoint{Txid: *txid, Index: 0}
+	output, err := storage.FindOutput(ctx, outpoint, nil, nil, false)
+	require.NoError(t, err)
+	output.Spent = true
+	output.ConsumedBy = append(output.ConsumedBy, outpoint)
+
+	again, err := storage.FindOutput(ctx, outpoint, nil, nil, false)
+	require.NoError(t, err)
+	assert.False(t, again.Spent)
+	assert.Empty(t, again.ConsumedBy)
+
+	require.NoError(t, storage.UpdateConsumedBy(ctx, outpoint, "tm_ship", []*transaction.Outpoint{outpoint}))
+	again, err = storage.FindOutput(ctx, outpoint, nil, nil, false)
+	require.NoError(t, err)
+	assert.Len(t, again.ConsumedBy, 1)
+}
+
+func TestEngineStorage_AppliedTransactionsAndInteractions(t *testing.T) {
+	ctx := context.Background()
+	storage := NewEngineStorage()
+	_, txid := newTestBeef(t, 1)
+	sameTxid := *txid
+
+	exists, err := storage.DoesAppliedTransactionExist(ctx, &overlay.AppliedTransaction{Txid: txid, Topic: "tm_ship"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, storage.InsertAppliedTransaction(ctx, &overlay.AppliedTransaction{Txid: txid, Topic: "tm_ship"}))
+
+	// Lookups compare transaction IDs by value, not by pointer
+	exists, err = storage.DoesAppliedTransactionExist(ctx, &overlay.AppliedTransaction{Txid: &sameTxid, Topic: "tm_ship"})
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = storage.DoesAppliedTransactionExist(ctx, &overlay.AppliedTransaction{Txid: txid, Topic: "tm_slap"})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	score, err := storage.GetLastInteraction(ctx, "https://peer.example.com", "tm_ship")
+	require.NoError(t, err)
+	assert.Zero(t, score)
+	require.NoError(t, storage.UpdateLastInteraction(ctx, "https://peer.example.com", "tm_ship", 42))
+	score, err = storage.GetLastInteraction(ctx, "https://peer.example.com", "tm_ship")
+	require.NoError(t, err)
+	assert.InDelta(t, 42, score, 0)
+}
+
+func TestEngineStorage_ReconcileMerkleRoot(t *testing.T) {
+	ctx := context.Background()
+	storage := NewEngineStorage()
+	beef, txid := newTestBeef(t, 1)
+	require.NoError(t, storage.InsertOutputs(ctx, "tm_ship", txid, []uint32{0}, nil, beef, nil))
+
+	outpoint := &transaction.Outpoint{Txid: *txid, Index: 0}
+	root := chainhash.DoubleHashH([]byte("root"))
+	storage.outputs[outputKey{outpoint: *outpoint, topic: "tm_ship"}].BlockHeight = 100
+	storage.outputs[outputKey{outpoint: *outpoint, topic: "tm_ship"}].MerkleRoot = &root
+
+	require.NoError(t, storage.ReconcileMerkleRoot(ctx, "tm_ship", 100, &root))
+	validated, err := storage.FindOutpointsByMerkleState(ctx, "tm_ship", engine.MerkleStateValidated, 0)
+	require.NoError(t, err)
+	assert.Len(t, validated, 1)
+
+	otherRoot := chainhash.DoubleHashH([]byte("other"))
+	require.NoError(t, storage.ReconcileMerkleRoot(ctx, "tm_ship", 100, &otherRoot))
+	invalidated, err := storage.FindOutpointsByMerkleState(ctx, "tm_ship", engine.MerkleStateInvalidated, 0)
+	require.NoError(t, err)
+	require.Len(t, invalidated, 1)
+	assert.Equal(t, *outpoint, *invalidated[0])
+}
@@ -0,0 +1,170 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// recordStore holds SHIP or SLAP records in creation order. It mirrors the MongoDB-backed
+// storage: results are sorted by creation time, newest first unless ascending order is
+// requested, and full record listings are oldest first.
+type recordStore[T any] struct {
+	mu        sync.RWMutex
+	records   []T
+	outpoint  func(T) types.UTXOReference
+	createdAt func(T) time.Time
+}
+
+// put stores a record, replacing any existing record for the same outpoint.
+func (s *recordStore[T]) put(record T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.putLocked(record)
+}
+
+// putLocked stores a record. The caller must hold the write lock.
+func (s *recordStore[T]) putLocked(record T) {
+	ref := s.outpoint(record)
+	if i := s.indexLocked(ref.Txid, ref.OutputIndex); i >= 0 {
+		s.records = slices.Delete(s.records, i, i+1)
+	}
+	s.records = append(s.records, record)
+}
+
+// putAll stores records as one operation.
+func (s *recordStore[T]) putAll(records []T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		s.putLocked(record)
+	}
+}
+
+// delete removes the record for the outpoint, if any.
+func (s *recordStore[T]) delete(txid string, outputIndex int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.indexLocked(txid, outputIndex); i >= 0 {
+		s.records = slices.Delete(s.records, i, i+1)
+	}
+}
+
+// find returns the record for the outpoint, or nil.
+func (s *recordStore[T]) find(txid string, outputIndex int) *T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if i := s.indexLocked(txid, outputIndex); i >= 0 {
+		record := s.records[i]
+		return &record
+	}
+	return nil
+}
+
+// query returns references to the records accepted by match, sorted by creation time and paginated.
+func (s *recordStore[T]) query(match func(T) bool, limit, skip *int, sortOrder *types.SortOrder) []types.UTXOReference {
+	matches := s.queryRecords(match, limit, skip, sortOrder)
+	results := make([]types.UTXOReference, len(matches))
+	for i, record := range matches {
+		results[i] = s.outpoint(record)
+	}
+	return results
+}
+
+// queryRecords returns the records accepted by match, sorted by creation time and paginated.
+func (s *recordStore[T]) queryRecords(match func(T) bool, limit, skip *int, sortOrder *types.SortOrder) []T {
+	matches := s.snapshot()
+	if match != nil {
+		matches = slices.DeleteFunc(matches, func(record T) bool { return !match(record) })
+	}
+
+	ascending := sortOrder != nil && *sortOrder == types.SortOrderAsc
+	slices.SortStableFunc(matches, func(a, b T) int {
+		if ascending {
+			return s.createdAt(a).Compare(s.createdAt(b))
+		}
+		return s.createdAt(b).Compare(s.createdAt(a))
+	})
+
+	return paginate(matches, limit, skip)
+}
+
+// list returns full records, oldest first with optional pagination.
+func (s *recordStore[T]) list(limit, skip *int) []T {
+	records := s.snapshot()
+	slices.SortStableFunc(records, func(a, b T) int {
+		return s.createdAt(a).Compare(s.createdAt(b))
+	})
+	return paginate(records, limit, skip)
+}
+
+// forEach calls fn for every record, oldest first, stopping at the first error. fn is called
+// on a snapshot, so it may modify the store.
+func (s *recordStore[T]) forEach(ctx context.Context, fn func(T) error) error {
+	for _, record := range s.list(nil, nil) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countBy returns the number of records for each value returned by key.
+func (s *recordStore[T]) countBy(key func(T) string) map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, record := range s.records {
+		counts[key(record)]++
+	}
+	return counts
+}
+
+// len returns the number of stored records.
+func (s *recordStore[T]) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.records)
+}
+
+// snapshot returns a copy of the stored records.
+func (s *recordStore[T]) snapshot() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.records)
+}
+
+// indexLocked returns the position of the record for the outpoint, or -1. The caller must hold the lock.
+func (s *recordStore[T]) indexLocked(txid string, outputIndex int) int {
+	return slices.IndexFunc(s.records, func(record T) bool {
+		ref := s.outpoint(record)
+		return ref.Txid == txid && ref.OutputIndex == outputIndex
+	})
+}
+
+// paginate applies skip and limit, ignoring values that are not positive.
+func paginate[T any](records []T, limit, skip *int) []T {
+	if skip != nil && *skip > 0 {
+		if *skip >= len(records) {
+			return []T{}
+		}
+		records = records[*skip:]
+	}
+	if limit != nil && *limit > 0 && len(records) > *limit {
+		records = records[:*limit]
+	}
+	return records
+}
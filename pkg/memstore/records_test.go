@@ -0,0 +1,176 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+var errStopIteration = errors.New("stop")
+
+func TestSHIPStorage_FindRecord(t *testing.T) {
+	ctx := context.Background()
+	storage := NewSHIPStorage()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, storage.ImportRecords(ctx, []types.SHIPRecord{
+		{Txid: "a", OutputIndex: 0, IdentityKey: "key1", Domain: "https://Example.com/", Topic: "tm_bridge", CreatedAt: base},
+		{Txid: "b", OutputIndex: 0, IdentityKey: "key2", Domain: "https://other.com", Topic: "tm_bridge", CreatedAt: base.Add(time.Minute)},
+		{Txid: "c", OutputIndex: 1, IdentityKey: "key1", Domain: "https://example.com", Topic: "tm_chat", CreatedAt: base.Add(2 * time.Minute)},
+	}))
+
+	// Newest first by default
+	all, err := storage.FindAll(ctx, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []types.UTXOReference{{Txid: "c", OutputIndex: 1}, {Txid: "b"}, {Txid: "a"}}, all)
+
+	asc := types.SortOrderAsc
+	limit, skip := 1, 1
+	page, err := storage.FindAll(ctx, &limit, &skip, &asc)
+	require.NoError(t, err)
+	assert.Equal(t, []types.UTXOReference{{Txid: "b"}}, page)
+
+	domain := "HTTPS://example.com:443"
+	byDomain, err := storage.FindRecord(ctx, types.SHIPQuery{Domain: &domain})
+	require.NoError(t, err)
+	assert.Equal(t, []types.UTXOReference{{Txid: "c", OutputIndex: 1}, {Txid: "a"}}, byDomain)
+
+	identityKey := "key1"
+	byTopic, err := storage.FindRecord(ctx, types.SHIPQuery{Topics: []string{"tm_bridge"}, IdentityKey: &identityKey})
+	require.NoError(t, err)
+	assert.Equal(t, []types.UTXOReference{{Txid: "a"}}, byTopic)
+}
+
+func TestSHIPStorage_QueryRecords(t *testing.T) {
+	ctx := context.Background()
+	storage := NewSHIPStorage()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := &types.TokenMaterial{LockingPublicKey: "02ab"}
+	require.NoError(t, storage.ImportRecords(ctx, []types.SHIPRecord{
+		{Txid: "a", OutputIndex: 0, IdentityKey: "key1", Domain: "https://example.com", Topic: "tm_bridge", CreatedAt: base, Token: token},
+		{Txid: "b", OutputIndex: 0, IdentityKey: "key2", Domain: "https://other.com", Topic: "tm_bridge", CreatedAt: base.Add(time.Minute)},
+		{Txid: "c", OutputIndex: 1, IdentityKey: "key1", Domain: "https://example.com", Topic: "tm_chat", CreatedAt: base.Add(2 * time.Minute)},
+	}))
+
+	// Same order and page as FindRecord
+	limit := 2
+	domain := "https://Example.com/"
+	query := types.SHIPQuery{Domain: &domain, Limit: &limit}
+	refs, err := storage.FindRecord(ctx, query)
+	require.NoError(t, err)
+	records, err := storage.QueryRecords(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, records, len(refs))
+	for i, record := range records {
+		assert.Equal(t, refs[i], types.UTXOReference{Txid: record.Txid, OutputIndex: record.OutputIndex})
+	}
+	assert.Equal(t, token, records[1].Token)
+}
+
+func TestSHIPStorage_StoreDeleteAndList(t *testing.T) {
+	ctx := context.Background()
+	storage := NewSHIPStorage()
+
+	require.NoError(t, storage.StoreSHIPRecord(ctx, "tx1", 0, "key", "https://example.com/", "tm_bridge"))
+	require.NoError(t, storage.StoreSHIPRecord(ctx, "tx2", 0, "key", "https://example.com", "tm_chat"))
+	// Storing the same outpoint again replaces the record
+	require.NoError(t, storage.StoreSHIPRecord(ctx, "tx1", 0, "key", "https://example.com", "tm_wallet"))
+	assert.Equal(t, 2, storage.Len())
+
+	record, err := storage.FindByOutpoint(ctx, "tx1", 0)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "tm_wallet", record.Topic)
+	assert.Equal(t, "https://example.com", record.Domain)
+	assert.False(t, record.CreatedAt.IsZero())
+
+	records, err := storage.ListRecords(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "tx2", records[0].Txid)
+
+	var visited int
+	err = storage.ForEachRecord(ctx, func(types.SHIPRecord) error {
+		visited++
+		return errStopIteration
+	})
+	require.ErrorIs(t, err, errStopIteration)
+	assert.Equal(t, 1, visited)
+
+	require.NoError(t, storage.DeleteSHIPRecord(ctx, "tx1", 0))
+	record, err = storage.FindByOutpoint(ctx, "tx1", 0)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+	assert.Equal(t, 1, storage.Len())
+}
+
+func TestSHIPStorage_Clock(t *testing.T) {
+	ctx := context.Background()
+	storage := NewSHIPStorage()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	storage.SetClock(shared.ClockFunc(func() time.Time { return now }))
+
+	require.NoError(t, storage.StoreSHIPRecord(ctx, "tx1", 0, "key", "https://example.com", "tm_bridge"))
+	// A backfilled record keeps its original creation time and sorts accordingly
+	original := now.Add(-24 * time.Hour)
+	require.NoError(t, storage.StoreSHIPRecordAt(ctx, "tx2", 0, "key", "https://example.com", "tm_bridge", nil, original))
+
+	records, err := storage.ListRecords(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "tx2", records[0].Txid)
+	assert.Equal(t, original, records[0].CreatedAt)
+	assert.Equal(t, now, records[1].CreatedAt)
+}
+
+func TestSLAPStorage_FindRecord(t *testing.T) {
+	ctx := context.Background()
+	storage := NewSLAPStorage()
+
+	require.NoError(t, storage.StoreSLAPRecord(ctx, "tx1", 0, "key1", "https://Example.com", "ls_bridge"))
+	require.NoError(t, storage.StoreSLAPRecord(ctx, "tx2", 0, "key2", "https://example.com", "ls_chat"))
+
+	service := "ls_bridge"
+	byService, err := storage.FindRecord(ctx, types.SLAPQuery{Service: &service})
+	require.NoError(t, err)
+	assert.Equal(t, []types.UTXOReference{{Txid: "tx1"}}, byService)
+
+	domain := "https://example.com/"
+	byDomain, err := storage.FindRecord(ctx, types.SLAPQuery{Domain: &domain})
+	require.NoError(t, err)
+	assert.Len(t, byDomain, 2)
+
+	require.NoError(t, storage.DeleteSLAPRecord(ctx, "tx1", 0))
+	byService, err = storage.FindRecord(ctx, types.SLAPQuery{Service: &service})
+	require.NoError(t, err)
+	assert.Empty(t, byService)
+}
+
+func TestSLAPStorage_Clock(t *testing.T) {
+	ctx := context.Background()
+	storage := NewSLAPStorage()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	storage.SetClock(shared.ClockFunc(func() time.Time { return now }))
+
+	require.NoError(t, storage.StoreSLAPRecord(ctx, "tx1", 0, "key", "https://example.com", "ls_bridge"))
+	original := now.Add(-time.Hour)
+	require.NoError(t, storage.StoreSLAPRecordAt(ctx, "tx2", 1, "key", "https://example.com", "ls_bridge", nil, original))
+
+	record, err := storage.FindByOutpoint(ctx, "tx1", 0)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, now, record.CreatedAt)
+
+	record, err = storage.FindByOutpoint(ctx, "tx2", 1)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, original, record.CreatedAt)
+}
@@ -0,0 +1,157 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
+)
+
+// Compile-time verification that SHIPStorage implements the ship storage interfaces
+var (
+	_ ship.StorageInterface     = (*SHIPStorage)(nil)
+	_ ship.RecordReader         = (*SHIPStorage)(nil)
+	_ ship.RecordQuerier        = (*SHIPStorage)(nil)
+	_ ship.TokenRecordStore     = (*SHIPStorage)(nil)
+	_ ship.BackdatedRecordStore = (*SHIPStorage)(nil)
+	_ ship.RecordLister         = (*SHIPStorage)(nil)
+	_ ship.RecordIterator       = (*SHIPStorage)(nil)
+	_ ship.RecordImporter       = (*SHIPStorage)(nil)
+	_ ship.RecordCounter        = (*SHIPStorage)(nil)
+)
+
+// SHIPStorage is an in-memory implementation of ship.StorageInterface with the same query
+// semantics as the MongoDB-backed ship.Storage. It is safe for concurrent use.
+type SHIPStorage struct {
+	store recordStore[types.SHIPRecord]
+	// clock stamps stored records (optional)
+	clock shared.Clock
+}
+
+// NewSHIPStorage creates an empty in-memory SHIP record store.
+func NewSHIPStorage() *SHIPStorage {
+	return &SHIPStorage{
+		store: recordStore[types.SHIPRecord]{
+			outpoint: func(r types.SHIPRecord) types.UTXOReference {
+				return types.UTXOReference{Txid: r.Txid, OutputIndex: r.OutputIndex}
+			},
+			createdAt: func(r types.SHIPRecord) time.Time { return r.CreatedAt },
+		},
+	}
+}
+
+// SetClock sets the clock stamping the creation time of stored records. A nil clock (the
+// default) uses the system time.
+func (s *SHIPStorage) SetClock(clock shared.Clock) {
+	s.clock = clock
+}
+
+// EnsureIndexes is a no-op; the in-memory store needs no indexes.
+func (s *SHIPStorage) EnsureIndexes(_ context.Context) error {
+	return nil
+}
+
+// StoreSHIPRecord stores a new SHIP record stamped with the time of the storage clock.
+func (s *SHIPStorage) StoreSHIPRecord(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string) error {
+	return s.StoreSHIPRecordWithToken(ctx, txid, outputIndex, identityKey, domain, topic, nil)
+}
+
+// StoreSHIPRecordWithToken stores a new SHIP record together with the raw token material it was
+// parsed from. The domain is stored in canonical form, and a record replaces any existing
+// record for the same outpoint.
+func (s *SHIPStorage) StoreSHIPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial) error {
+	return s.StoreSHIPRecordAt(ctx, txid, outputIndex, identityKey, domain, topic, token, shared.Now(s.clock))
+}
+
+// StoreSHIPRecordAt stores a new SHIP record created at createdAt rather than the time of the
+// storage clock, for backfilling records first seen elsewhere.
+func (s *SHIPStorage) StoreSHIPRecordAt(_ context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial, createdAt time.Time) error {
+	s.store.put(types.SHIPRecord{
+		Txid:        txid,
+		OutputIndex: outputIndex,
+		IdentityKey: identityKey,
+		Domain:      utils.CanonicalDomain(domain),
+		Topic:       topic,
+		CreatedAt:   createdAt,
+		Token:       token,
+	})
+	return nil
+}
+
+// DeleteSHIPRecord deletes the SHIP record for the outpoint, if any.
+func (s *SHIPStorage) DeleteSHIPRecord(_ context.Context, txid string, outputIndex int) error {
+	s.store.delete(txid, outputIndex)
+	return nil
+}
+
+// FindRecord finds SHIP records by domain, topics and identity key, with pagination and sorting.
+func (s *SHIPStorage) FindRecord(_ context.Context, query types.SHIPQuery) ([]types.UTXOReference, error) {
+	return s.store.query(matchSHIPQuery(query), query.Limit, query.Skip, query.SortOrder), nil
+}
+
+// QueryRecords returns the full SHIP records matching the query, in the order and page
+// FindRecord returns their references.
+func (s *SHIPStorage) QueryRecords(_ context.Context, query types.SHIPQuery) ([]types.SHIPRecord, error) {
+	return s.store.queryRecords(matchSHIPQuery(query), query.Limit, query.Skip, query.SortOrder), nil
+}
+
+// matchSHIPQuery returns a predicate accepting the SHIP records matching the query.
+func matchSHIPQuery(query types.SHIPQuery) func(types.SHIPRecord) bool {
+	var domain string
+	if query.Domain != nil {
+		domain = utils.CanonicalDomain(*query.Domain)
+	}
+
+	return func(r types.SHIPRecord) bool {
+		return (query.Domain == nil || r.Domain == domain) &&
+			(len(query.Topics) == 0 || slices.Contains(query.Topics, r.Topic)) &&
+			(query.IdentityKey == nil || r.IdentityKey == *query.IdentityKey)
+	}
+}
+
+// FindAll returns references to all SHIP records with optional pagination and sorting.
+func (s *SHIPStorage) FindAll(_ context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
+	return s.store.query(nil, limit, skip, sortOrder), nil
+}
+
+// FindByOutpoint returns the SHIP record stored for the outpoint.
+// Returns nil (no error) if no such record exists.
+func (s *SHIPStorage) FindByOutpoint(_ context.Context, txid string, outputIndex int) (*types.SHIPRecord, error) {
+	return s.store.find(txid, outputIndex), nil
+}
+
+// ListRecords returns full SHIP records, oldest first with optional pagination.
+func (s *SHIPStorage) ListRecords(_ context.Context, limit, skip *int) ([]types.SHIPRecord, error) {
+	return s.store.list(limit, skip), nil
+}
+
+// ForEachRecord calls fn for every SHIP record, oldest first, stopping at the first error.
+func (s *SHIPStorage) ForEachRecord(ctx context.Context, fn func(types.SHIPRecord) error) error {
+	return s.store.forEach(ctx, fn)
+}
+
+// ImportRecords writes complete SHIP records, preserving their creation time and token
+// material. Domains are canonicalized and a record replaces any existing record for the same outpoint.
+func (s *SHIPStorage) ImportRecords(_ context.Context, records []types.SHIPRecord) error {
+	canonical := make([]types.SHIPRecord, len(records))
+	for i, record := range records {
+		record.Domain = utils.CanonicalDomain(record.Domain)
+		canonical[i] = record
+	}
+	s.store.putAll(canonical)
+	return nil
+}
+
+// CountRecordsByTopic returns the number of stored SHIP records for each topic.
+func (s *SHIPStorage) CountRecordsByTopic(_ context.Context) (map[string]int, error) {
+	return s.store.countBy(func(record types.SHIPRecord) string { return record.Topic }), nil
+}
+
+// Len returns the number of stored SHIP records.
+func (s *SHIPStorage) Len() int {
+	return s.store.len()
+}
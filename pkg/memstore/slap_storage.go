@@ -0,0 +1,156 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
+)
+
+// Compile-time verification that SLAPStorage implements the slap storage interfaces
+var (
+	_ slap.StorageInterface     = (*SLAPStorage)(nil)
+	_ slap.RecordReader         = (*SLAPStorage)(nil)
+	_ slap.RecordQuerier        = (*SLAPStorage)(nil)
+	_ slap.TokenRecordStore     = (*SLAPStorage)(nil)
+	_ slap.BackdatedRecordStore = (*SLAPStorage)(nil)
+	_ slap.RecordLister         = (*SLAPStorage)(nil)
+	_ slap.RecordIterator       = (*SLAPStorage)(nil)
+	_ slap.RecordImporter       = (*SLAPStorage)(nil)
+	_ slap.RecordCounter        = (*SLAPStorage)(nil)
+)
+
+// SLAPStorage is an in-memory implementation of slap.StorageInterface with the same query
+// semantics as the MongoDB-backed slap.Storage. It is safe for concurrent use.
+type SLAPStorage struct {
+	store recordStore[types.SLAPRecord]
+	// clock stamps stored records (optional)
+	clock shared.Clock
+}
+
+// NewSLAPStorage creates an empty in-memory SLAP record store.
+func NewSLAPStorage() *SLAPStorage {
+	return &SLAPStorage{
+		store: recordStore[types.SLAPRecord]{
+			outpoint: func(r types.SLAPRecord) types.UTXOReference {
+				return types.UTXOReference{Txid: r.Txid, OutputIndex: r.OutputIndex}
+			},
+			createdAt: func(r types.SLAPRecord) time.Time { return r.CreatedAt },
+		},
+	}
+}
+
+// SetClock sets the clock stamping the creation time of stored records. A nil clock (the
+// default) uses the system time.
+func (s *SLAPStorage) SetClock(clock shared.Clock) {
+	s.clock = clock
+}
+
+// EnsureIndexes is a no-op; the in-memory store needs no indexes.
+func (s *SLAPStorage) EnsureIndexes(_ context.Context) error {
+	return nil
+}
+
+// StoreSLAPRecord stores a new SLAP record stamped with the time of the storage clock.
+func (s *SLAPStorage) StoreSLAPRecord(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string) error {
+	return s.StoreSLAPRecordWithToken(ctx, txid, outputIndex, identityKey, domain, service, nil)
+}
+
+// StoreSLAPRecordWithToken stores a new SLAP record together with the raw token material it was
+// parsed from. The domain is stored in canonical form, and a record replaces any existing
+// record for the same outpoint.
+func (s *SLAPStorage) StoreSLAPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial) error {
+	return s.StoreSLAPRecordAt(ctx, txid, outputIndex, identityKey, domain, service, token, shared.Now(s.clock))
+}
+
+// StoreSLAPRecordAt stores a new SLAP record created at createdAt rather than the time of the
+// storage clock, for backfilling records first seen elsewhere.
+func (s *SLAPStorage) StoreSLAPRecordAt(_ context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial, createdAt time.Time) error {
+	s.store.put(types.SLAPRecord{
+		Txid:        txid,
+		OutputIndex: outputIndex,
+		IdentityKey: identityKey,
+		Domain:      utils.CanonicalDomain(domain),
+		Service:     service,
+		CreatedAt:   createdAt,
+		Token:       token,
+	})
+	return nil
+}
+
+// DeleteSLAPRecord deletes the SLAP record for the outpoint, if any.
+func (s *SLAPStorage) DeleteSLAPRecord(_ context.Context, txid string, outputIndex int) error {
+	s.store.delete(txid, outputIndex)
+	return nil
+}
+
+// FindRecord finds SLAP records by domain, service and identity key, with pagination and sorting.
+func (s *SLAPStorage) FindRecord(_ context.Context, query types.SLAPQuery) ([]types.UTXOReference, error) {
+	return s.store.query(matchSLAPQuery(query), query.Limit, query.Skip, query.SortOrder), nil
+}
+
+// QueryRecords returns the full SLAP records matching the query, in the order and page
+// FindRecord returns their references.
+func (s *SLAPStorage) QueryRecords(_ context.Context, query types.SLAPQuery) ([]types.SLAPRecord, error) {
+	return s.store.queryRecords(matchSLAPQuery(query), query.Limit, query.Skip, query.SortOrder), nil
+}
+
+// matchSLAPQuery returns a predicate accepting the SLAP records matching the query.
+func matchSLAPQuery(query types.SLAPQuery) func(types.SLAPRecord) bool {
+	var domain string
+	if query.Domain != nil {
+		domain = utils.CanonicalDomain(*query.Domain)
+	}
+
+	return func(r types.SLAPRecord) bool {
+		return (query.Domain == nil || r.Domain == domain) &&
+			(query.Service == nil || r.Service == *query.Service) &&
+			(query.IdentityKey == nil || r.IdentityKey == *query.IdentityKey)
+	}
+}
+
+// FindAll returns references to all SLAP records with optional pagination and sorting.
+func (s *SLAPStorage) FindAll(_ context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
+	return s.store.query(nil, limit, skip, sortOrder), nil
+}
+
+// FindByOutpoint returns the SLAP record stored for the outpoint.
+// Returns nil (no error) if no such record exists.
+func (s *SLAPStorage) FindByOutpoint(_ context.Context, txid string, outputIndex int) (*types.SLAPRecord, error) {
+	return s.store.find(txid, outputIndex), nil
+}
+
+// ListRecords returns full SLAP records, oldest first with optional pagination.
+func (s *SLAPStorage) ListRecords(_ context.Context, limit, skip *int) ([]types.SLAPRecord, error) {
+	return s.store.list(limit, skip), nil
+}
+
+// ForEachRecord calls fn for every SLAP record, oldest first, stopping at the first error.
+func (s *SLAPStorage) ForEachRecord(ctx context.Context, fn func(types.SLAPRecord) error) error {
+	return s.store.forEach(ctx, fn)
+}
+
+// ImportRecords writes complete SLAP records, preserving their creation time and token
+// material. Domains are canonicalized and a record replaces any existing record for the same outpoint.
+func (s *SLAPStorage) ImportRecords(_ context.Context, records []types.SLAPRecord) error {
+	canonical := make([]types.SLAPRecord, len(records))
+	for i, record := range records {
+		record.Domain = utils.CanonicalDomain(record.Domain)
+		canonical[i] = record
+	}
+	s.store.putAll(canonical)
+	return nil
+}
+
+// CountRecordsByService returns the number of stored SLAP records for each service.
+func (s *SLAPStorage) CountRecordsByService(_ context.Context) (map[string]int, error) {
+	return s.store.countBy(func(record types.SLAPRecord) string { return record.Service }), nil
+}
+
+// Len returns the number of stored SLAP records.
+func (s *SLAPStorage) Len() int {
+	return s.store.len()
+}
@@ -0,0 +1,285 @@
+// Package metrics exports Prometheus metrics for SHIP and SLAP topic managers, lookup services
+// and storage. A Metrics value is a prometheus.Collector registered on a caller-provided
+// registry, and a shared.Observer set on the components it instruments:
+//
+//	m := metrics.New()
+//	registry.MustRegister(m)
+//	topicManager.SetObserver(m)
+//	lookupService.SetObserver(m)
+//	storage = metrics.InstrumentSHIPStorage(storage, m)
+//	m.WatchSHIPRecords(storage)
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+)
+
+// Namespace prefixes the name of every metric.
+const Namespace = "discovery"
+
+// Lookup outcomes reported in the result label of discovery_lookup_requests_total.
+const (
+	// ResultSuccess labels lookups that were answered
+	ResultSuccess = "success"
+	// ResultError labels lookups that failed
+	ResultError = "error"
+)
+
+// recordCountTimeout bounds the storage queries counting records at scrape time.
+const recordCountTimeout = 10 * time.Second
+
+// Compile-time verification that Metrics implements the interfaces it is used through
+var (
+	_ prometheus.Collector = (*Metrics)(nil)
+	_ shared.Observer      = (*Metrics)(nil)
+)
+
+// Metrics collects the discovery metrics:
+//
+//	discovery_outputs_admitted_total{protocol}                    outputs admitted by topic managers
+//	discovery_outputs_rejected_total{protocol,reason}             outputs rejected, by shared.RejectionReason
+//	discovery_outputs_removed_total{protocol,cause}               records removed because their output was spent or evicted
+//	discovery_lookup_requests_total{service,query_type,result}    lookup questions answered or failed
+//	discovery_lookup_duration_seconds{service,query_type}         lookup latency
+//	discovery_storage_operation_duration_seconds{protocol,operation}  storage latency
+//	discovery_storage_errors_total{protocol,operation}            failed storage operations
+//	discovery_ship_records{topic}                                 stored SHIP records per topic
+//	discovery_slap_records{service}                               stored SLAP records per service
+//
+// The record counts are computed at scrape time from the storages passed to WatchSHIPRecords
+// and WatchSLAPRecords. Protocol labels are lower case ("ship", "slap").
+type Metrics struct {
+	admitted        *prometheus.CounterVec
+	rejected        *prometheus.CounterVec
+	removed         *prometheus.CounterVec
+	lookups         *prometheus.CounterVec
+	lookupDuration  *prometheus.HistogramVec
+	storageDuration *prometheus.HistogramVec
+	storageErrors   *prometheus.CounterVec
+
+	shipRecordsDesc *prometheus.Desc
+	slapRecordsDesc *prometheus.Desc
+
+	// mutex protects the watched storages
+	mutex sync.RWMutex
+	// shipStorages are counted into discovery_ship_records
+	shipStorages []ship.StorageInterface
+	// slapStorages are counted into discovery_slap_records
+	slapStorages []slap.StorageInterface
+	// logger receives failures to count records (optional)
+	logger *slog.Logger
+}
+
+// New creates a Metrics collector. It must be registered on a registry to be exported.
+func New() *Metrics {
+	return &Metrics{
+		admitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "outputs_admitted_total",
+			Help:      "Number of outputs admitted by topic managers.",
+		}, []string{"protocol"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "outputs_rejected_total",
+			Help:      "Number of outputs rejected by topic managers, by reason.",
+		}, []string{"protocol", "reason"}),
+		removed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "outputs_removed_total",
+			Help:      "Number of records removed because their output was spent or evicted.",
+		}, []string{"protocol", "cause"}),
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "lookup_requests_total",
+			Help:      "Number of lookup questions, by query type and result.",
+		}, []string{"service", "query_type", "result"}),
+		lookupDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "lookup_duration_seconds",
+			Help:      "Time taken to answer lookup questions, by query type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "query_type"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "storage_operation_duration_seconds",
+			Help:      "Time taken by record storage operations.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"protocol", "operation"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "storage_errors_total",
+			Help:      "Number of failed record storage operations.",
+		}, []string{"protocol", "operation"}),
+		shipRecordsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "", "ship_records"),
+			"Number of stored SHIP records, by advertised topic.",
+			[]string{"topic"}, nil),
+		slapRecordsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "", "slap_records"),
+			"Number of stored SLAP records, by advertised service.",
+			[]string{"service"}, nil),
+	}
+}
+
+// collectors returns the metrics updated as events occur.
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.admitted, m.rejected, m.removed,
+		m.lookups, m.lookupDuration,
+		m.storageDuration, m.storageErrors,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+	ch <- m.shipRecordsDesc
+	ch <- m.slapRecordsDesc
+}
+
+// Collect implements prometheus.Collector. Record counts are queried from the watched
+// storages. If a storage cannot count its records, the failure is logged (see SetLogger) and
+// the records gauge of that protocol is left out of the scrape rather than reported partially.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+
+	m.mutex.RLock()
+	shipStorages, slapStorages, logger := m.shipStorages, m.slapStorages, shared.Logger(context.Background(), m.logger)
+	m.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordCountTimeout)
+	defer cancel()
+
+	if len(shipStorages) > 0 {
+		if counts, err := countRecords(ctx, shipStorages, countSHIPRecords); err != nil {
+			logger.Warn("Failed to count records", shared.LogKeyProtocol, "SHIP", shared.LogKeyError, err)
+		} else {
+			emitCounts(ch, m.shipRecordsDesc, counts)
+		}
+	}
+
+	if len(slapStorages) > 0 {
+		if counts, err := countRecords(ctx, slapStorages, countSLAPRecords); err != nil {
+			logger.Warn("Failed to count records", shared.LogKeyProtocol, "SLAP", shared.LogKeyError, err)
+		} else {
+			emitCounts(ch, m.slapRecordsDesc, counts)
+		}
+	}
+}
+
+// countRecords sums the record counts of storages, failing if any storage cannot be counted.
+func countRecords[S any](ctx context.Context, storages []S, count func(context.Context, S) (map[string]int, error)) (map[string]int, error) {
+	total := make(map[string]int)
+	for _, storage := range storages {
+		counts, err := count(ctx, storage)
+		if err != nil {
+			return nil, err
+		}
+		for label, n := range counts {
+			total[label] += n
+		}
+	}
+	return total, nil
+}
+
+// countSHIPRecords returns the SHIP record counts by topic of a storage implementing ship.RecordCounter.
+func countSHIPRecords(ctx context.Context, storage ship.StorageInterface) (map[string]int, error) {
+	counter, ok := storage.(ship.RecordCounter)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("CountRecordsByTopic")
+	}
+	return counter.CountRecordsByTopic(ctx)
+}
+
+// countSLAPRecords returns the SLAP record counts by service of a storage implementing slap.RecordCounter.
+func countSLAPRecords(ctx context.Context, storage slap.StorageInterface) (map[string]int, error) {
+	counter, ok := storage.(slap.RecordCounter)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("CountRecordsByService")
+	}
+	return counter.CountRecordsByService(ctx)
+}
+
+// emitCounts sends a gauge for each label value.
+func emitCounts(ch chan<- prometheus.Metric, desc *prometheus.Desc, counts map[string]int) {
+	for label, count := range counts {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count), label)
+	}
+}
+
+// SetLogger sets the logger receiving failures to count records at scrape time. A nil logger
+// (the default) discards them.
+func (m *Metrics) SetLogger(logger *slog.Logger) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.logger = logger
+}
+
+// WatchSHIPRecords adds a storage whose records are counted, by topic, into
+// discovery_ship_records at every scrape. The storage must implement ship.RecordCounter.
+func (m *Metrics) WatchSHIPRecords(storage ship.StorageInterface) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.shipStorages = append(m.shipStorages, storage)
+}
+
+// WatchSLAPRecords adds a storage whose records are counted, by service, into
+// discovery_slap_records at every scrape. The storage must implement slap.RecordCounter.
+func (m *Metrics) WatchSLAPRecords(storage slap.StorageInterface) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.slapStorages = append(m.slapStorages, storage)
+}
+
+// ObserveAdmittance implements shared.Observer.
+func (m *Metrics) ObserveAdmittance(protocol string, report shared.AdmittanceReport) {
+	protocol = protocolLabel(protocol)
+	if admitted := len(report.Instructions.OutputsToAdmit); admitted > 0 {
+		m.admitted.WithLabelValues(protocol).Add(float64(admitted))
+	}
+	for _, rejection := range report.Rejections {
+		m.rejected.WithLabelValues(protocol, string(rejection.Reason)).Inc()
+	}
+}
+
+// ObserveRemoval implements shared.Observer.
+func (m *Metrics) ObserveRemoval(protocol string, cause shared.RemovalCause) {
+	m.removed.WithLabelValues(protocolLabel(protocol), string(cause)).Inc()
+}
+
+// ObserveLookup implements shared.Observer.
+func (m *Metrics) ObserveLookup(service string, queryType shared.QueryType, duration time.Duration, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+	}
+	m.lookups.WithLabelValues(service, string(queryType), result).Inc()
+	m.lookupDuration.WithLabelValues(service, string(queryType)).Observe(duration.Seconds())
+}
+
+// observeStorage records the duration and outcome of a storage operation started at start.
+func (m *Metrics) observeStorage(protocol, operation string, start time.Time, err error) {
+	m.storageDuration.WithLabelValues(protocol, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.storageErrors.WithLabelValues(protocol, operation).Inc()
+	}
+}
+
+// protocolLabel normalizes a protocol identifier ("SHIP", "SLAP") to a label value.
+func protocolLabel(protocol string) string {
+	return strings.ToLower(protocol)
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/memstore"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+const testTxid = "bdf1e48e845a65ba8c139c9b94844de30716f38d53787ba0a435e8705c4216d5"
+
+// Static error variables for testing
+var errTestStorage = errors.New("storage error")
+
+// failingSLAPStorage fails every lookup query.
+type failingSLAPStorage struct {
+	slap.StorageInterface
+}
+
+func (failingSLAPStorage) FindAll(context.Context, *int, *int, *types.SortOrder) ([]types.UTXOReference, error) {
+	return nil, errTestStorage
+}
+
+// newRegistered returns metrics registered on a fresh registry.
+func newRegistered(t *testing.T) (*Metrics, *prometheus.Registry) {
+	t.Helper()
+	m := New()
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(m))
+	return m, registry
+}
+
+func TestMetrics_ObserveAdmittance(t *testing.T) {
+	m, _ := newRegistered(t)
+
+	m.ObserveAdmittance("SHIP", shared.AdmittanceReport{
+		Instructions: overlay.AdmittanceInstructions{OutputsToAdmit: []uint32{0, 2}},
+		Rejections: []shared.OutputRejection{
+			{OutputIndex: 1, Reason: shared.RejectionInvalidSignature},
+			{OutputIndex: 3, Reason: shared.RejectionInvalidSignature},
+			{OutputIndex: 4, Reason: shared.RejectionPolicy},
+		},
+	})
+
+	assert.InDelta(t, 2, testutil.ToFloat64(m.admitted.WithLabelValues("ship")), 0)
+	assert.InDelta(t, 2, testutil.ToFloat64(m.rejected.WithLabelValues("ship", string(shared.RejectionInvalidSignature))), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.rejected.WithLabelValues("ship", string(shared.RejectionPolicy))), 0)
+}
+
+func TestMetrics_LookupAndRemoval(t *testing.T) {
+	m, _ := newRegistered(t)
+	storage := memstore.NewSHIPStorage()
+	service := ship.NewLookupService(storage)
+	service.SetObserver(m)
+	ctx := context.Background()
+
+	_, err := service.Lookup(ctx, &lookup.LookupQuestion{Service: ship.Service, Query: json.RawMessage(`"findAll"`)})
+	require.NoError(t, err)
+	_, err = service.Lookup(ctx, &lookup.LookupQuestion{Service: ship.Service, Query: json.RawMessage(`{"domain":"https://a.example.com"}`)})
+	require.NoError(t, err)
+	_, err = service.Lookup(ctx, &lookup.LookupQuestion{Service: ship.Service, Query: json.RawMessage(`"everything"`)})
+	require.Error(t, err)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(m.lookups.WithLabelValues(ship.Service, string(shared.QueryTypeFindAll), ResultSuccess)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.lookups.WithLabelValues(ship.Service, string(shared.QueryTypeFiltered), ResultSuccess)), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.lookups.WithLabelValues(ship.Service, string(shared.QueryTypeInvalid), ResultError)), 0)
+	assert.Equal(t, 3, testutil.CollectAndCount(m.lookupDuration))
+
+	require.NoError(t, storage.StoreSHIPRecord(ctx, testTxid, 0, "02abc", "https://a.example.com", "tm_meter"))
+	outpoint, err := transaction.OutpointFromString(testTxid + ".0")
+	require.NoError(t, err)
+	require.NoError(t, service.OutputSpent(ctx, &engine.OutputSpent{Topic: ship.Topic, Outpoint: outpoint}))
+	require.NoError(t, service.OutputSpent(ctx, &engine.OutputSpent{Topic: "tm_other", Outpoint: outpoint}))
+	require.NoError(t, service.OutputEvicted(ctx, outpoint))
+
+	assert.InDelta(t, 1, testutil.ToFloat64(m.removed.WithLabelValues("ship", string(shared.RemovalSpent))), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.removed.WithLabelValues("ship", string(shared.RemovalEvicted))), 0)
+}
+
+func TestMetrics_LookupError(t *testing.T) {
+	m, _ := newRegistered(t)
+	service := slap.NewLookupService(failingSLAPStorage{memstore.NewSLAPStorage()})
+	service.SetObserver(m)
+
+	_, err := service.Lookup(context.Background(), &lookup.LookupQuestion{Service: slap.Service, Query: json.RawMessage(`{"findAll":true}`)})
+	require.Error(t, err)
+	assert.InDelta(t, 1, testutil.ToFloat64(m.lookups.WithLabelValues(slap.Service, string(shared.QueryTypeFindAll), ResultError)), 0)
+}
+
+func TestInstrumentStorage(t *testing.T) {
+	m, _ := newRegistered(t)
+	ctx := context.Background()
+
+	storage := InstrumentSHIPStorage(memstore.NewSHIPStorage(), m)
+	require.NoError(t, storage.StoreSHIPRecord(ctx, testTxid, 0, "02abc", "https://a.example.com", "tm_meter"))
+	_, err := storage.FindAll(ctx, nil, nil, nil)
+	require.NoError(t, err)
+
+	failing := InstrumentSLAPStorage(failingSLAPStorage{memstore.NewSLAPStorage()}, m)
+	_, err = failing.FindAll(ctx, nil, nil, nil)
+	require.ErrorIs(t, err, errTestStorage)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(m.storageDuration))
+	assert.InDelta(t, 1, testutil.ToFloat64(m.storageErrors.WithLabelValues("slap", OpFindAll)), 0)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.storageErrors))
+}
+
+func TestMetrics_RecordCounts(t *testing.T) {
+	m, registry := newRegistered(t)
+	ctx := context.Background()
+
+	shipStorage := memstore.NewSHIPStorage()
+	require.NoError(t, shipStorage.StoreSHIPRecord(ctx, testTxid, 0, "02abc", "https://a.example.com", "tm_meter"))
+	require.NoError(t, shipStorage.StoreSHIPRecord(ctx, testTxid, 1, "02abc", "https://b.example.com", "tm_meter"))
+	require.NoError(t, shipStorage.StoreSHIPRecord(ctx, testTxid, 2, "02abc", "https://a.example.com", "tm_other"))
+	slapStorage := memstore.NewSLAPStorage()
+	require.NoError(t, slapStorage.StoreSLAPRecord(ctx, testTxid, 3, "02abc", "https://a.example.com", "ls_meter"))
+
+	m.WatchSHIPRecords(shipStorage)
+	m.WatchSLAPRecords(slapStorage)
+
+	expected := `
+# HELP discovery_ship_records Number of stored SHIP records, by advertised topic.
+# TYPE discovery_ship_records gauge
+discovery_ship_records{topic="tm_meter"} 2
+discovery_ship_records{topic="tm_other"} 1
+# HELP discovery_slap_records Number of stored SLAP records, by advertised service.
+# TYPE discovery_slap_records gauge
+discovery_slap_records{service="ls_meter"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"discovery_ship_records", "discovery_slap_records"))
+}
+
+func TestMetrics_RecordCountsWithheldOnError(t *testing.T) {
+	m, registry := newRegistered(t)
+	ctx := context.Background()
+
+	counted := memstore.NewSHIPStorage()
+	require.NoError(t, counted.StoreSHIPRecord(ctx, testTxid, 0, "02abc", "https://a.example.com", "tm_meter"))
+	m.WatchSHIPRecords(counted)
+	// Embedding only the base interface hides the storage's RecordCounter
+	m.WatchSHIPRecords(struct{ ship.StorageInterface }{memstore.NewSHIPStorage()})
+	m.WatchSLAPRecords(memstore.NewSLAPStorage())
+
+	count, err := testutil.GatherAndCount(registry, "discovery_ship_records")
+	require.NoError(t, err)
+	assert.Zero(t, count, "partial SHIP counts must not be exported")
+}
+
+func TestMetrics_ObserveLookupDuration(t *testing.T) {
+	m, _ := newRegistered(t)
+	m.ObserveLookup(ship.Service, shared.QueryTypeFiltered, 250*time.Millisecond, nil)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.lookupDuration, "discovery_lookup_duration_seconds"))
+}
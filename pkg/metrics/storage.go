@@ -0,0 +1,332 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Storage operations reported in the operation label of the storage metrics.
+const (
+	OpStore          = "store"
+	OpStoreWithToken = "store_with_token"
+	OpStoreAt        = "store_at"
+	OpDelete         = "delete"
+	OpFindRecord     = "find_record"
+	OpQueryRecords   = "query_records"
+	OpFindAll        = "find_all"
+	OpFindByOutpoint = "find_by_outpoint"
+	OpListRecords    = "list_records"
+	OpForEachRecord  = "for_each_record"
+	OpImportRecords  = "import_records"
+	OpCountRecords   = "count_records"
+	OpEnsureIndexes  = "ensure_indexes"
+)
+
+// Compile-time verification that the instrumented storages implement the storage interfaces
+var (
+	_ ship.StorageInterface     = (*shipStorage)(nil)
+	_ ship.RecordReader         = (*shipStorage)(nil)
+	_ ship.RecordQuerier        = (*shipStorage)(nil)
+	_ ship.TokenRecordStore     = (*shipStorage)(nil)
+	_ ship.BackdatedRecordStore = (*shipStorage)(nil)
+	_ ship.RecordLister         = (*shipStorage)(nil)
+	_ ship.RecordIterator       = (*shipStorage)(nil)
+	_ ship.RecordImporter       = (*shipStorage)(nil)
+	_ ship.RecordCounter        = (*shipStorage)(nil)
+	_ slap.StorageInterface     = (*slapStorage)(nil)
+	_ slap.RecordReader         = (*slapStorage)(nil)
+	_ slap.RecordQuerier        = (*slapStorage)(nil)
+	_ slap.TokenRecordStore     = (*slapStorage)(nil)
+	_ slap.BackdatedRecordStore = (*slapStorage)(nil)
+	_ slap.RecordLister         = (*slapStorage)(nil)
+	_ slap.RecordIterator       = (*slapStorage)(nil)
+	_ slap.RecordImporter       = (*slapStorage)(nil)
+	_ slap.RecordCounter        = (*slapStorage)(nil)
+)
+
+// InstrumentSHIPStorage returns a storage recording the latency and errors of every operation
+// on storage in m, labelled with protocol "ship". It implements every optional ship storage
+// interface, such as ship.RecordReader; operations storage lacks fail with shared.ErrStorageUnsupported.
+func InstrumentSHIPStorage(storage ship.StorageInterface, m *Metrics) ship.StorageInterface {
+	return &shipStorage{next: storage, metrics: m}
+}
+
+// InstrumentSLAPStorage returns a storage recording the latency and errors of every operation
+// on storage in m, labelled with protocol "slap". It implements every optional slap storage
+// interface, such as slap.RecordReader; operations storage lacks fail with shared.ErrStorageUnsupported.
+func InstrumentSLAPStorage(storage slap.StorageInterface, m *Metrics) slap.StorageInterface {
+	return &slapStorage{next: storage, metrics: m}
+}
+
+// shipStorage instruments a ship.StorageInterface.
+type shipStorage struct {
+	next    ship.StorageInterface
+	metrics *Metrics
+}
+
+// observe records an operation on the SHIP storage.
+func (s *shipStorage) observe(operation string, start time.Time, err error) {
+	s.metrics.observeStorage("ship", operation, start, err)
+}
+
+func (s *shipStorage) StoreSHIPRecord(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string) error {
+	start := time.Now()
+	err := s.next.StoreSHIPRecord(ctx, txid, outputIndex, identityKey, domain, topic)
+	s.observe(OpStore, start, err)
+	return err
+}
+
+func (s *shipStorage) StoreSHIPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial) error {
+	next, ok := s.next.(ship.TokenRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSHIPRecordWithToken")
+	}
+	start := time.Now()
+	err := next.StoreSHIPRecordWithToken(ctx, txid, outputIndex, identityKey, domain, topic, token)
+	s.observe(OpStoreWithToken, start, err)
+	return err
+}
+
+func (s *shipStorage) StoreSHIPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial, createdAt time.Time) error {
+	next, ok := s.next.(ship.BackdatedRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSHIPRecordAt")
+	}
+	start := time.Now()
+	err := next.StoreSHIPRecordAt(ctx, txid, outputIndex, identityKey, domain, topic, token, createdAt)
+	s.observe(OpStoreAt, start, err)
+	return err
+}
+
+func (s *shipStorage) DeleteSHIPRecord(ctx context.Context, txid string, outputIndex int) error {
+	start := time.Now()
+	err := s.next.DeleteSHIPRecord(ctx, txid, outputIndex)
+	s.observe(OpDelete, start, err)
+	return err
+}
+
+func (s *shipStorage) FindRecord(ctx context.Context, query types.SHIPQuery) ([]types.UTXOReference, error) {
+	start := time.Now()
+	refs, err := s.next.FindRecord(ctx, query)
+	s.observe(OpFindRecord, start, err)
+	return refs, err
+}
+
+func (s *shipStorage) QueryRecords(ctx context.Context, query types.SHIPQuery) ([]types.SHIPRecord, error) {
+	next, ok := s.next.(ship.RecordQuerier)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("QueryRecords")
+	}
+	start := time.Now()
+	records, err := next.QueryRecords(ctx, query)
+	s.observe(OpQueryRecords, start, err)
+	return records, err
+}
+
+func (s *shipStorage) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
+	start := time.Now()
+	refs, err := s.next.FindAll(ctx, limit, skip, sortOrder)
+	s.observe(OpFindAll, start, err)
+	return refs, err
+}
+
+func (s *shipStorage) FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SHIPRecord, error) {
+	next, ok := s.next.(ship.RecordReader)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("FindByOutpoint")
+	}
+	start := time.Now()
+	record, err := next.FindByOutpoint(ctx, txid, outputIndex)
+	s.observe(OpFindByOutpoint, start, err)
+	return record, err
+}
+
+func (s *shipStorage) ListRecords(ctx context.Context, limit, skip *int) ([]types.SHIPRecord, error) {
+	next, ok := s.next.(ship.RecordLister)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("ListRecords")
+	}
+	start := time.Now()
+	records, err := next.ListRecords(ctx, limit, skip)
+	s.observe(OpListRecords, start, err)
+	return records, err
+}
+
+func (s *shipStorage) ForEachRecord(ctx context.Context, fn func(types.SHIPRecord) error) error {
+	next, ok := s.next.(ship.RecordIterator)
+	if !ok {
+		return shared.UnsupportedStorageError("ForEachRecord")
+	}
+	start := time.Now()
+	err := next.ForEachRecord(ctx, fn)
+	s.observe(OpForEachRecord, start, err)
+	return err
+}
+
+func (s *shipStorage) ImportRecords(ctx context.Context, records []types.SHIPRecord) error {
+	next, ok := s.next.(ship.RecordImporter)
+	if !ok {
+		return shared.UnsupportedStorageError("ImportRecords")
+	}
+	start := time.Now()
+	err := next.ImportRecords(ctx, records)
+	s.observe(OpImportRecords, start, err)
+	return err
+}
+
+func (s *shipStorage) CountRecordsByTopic(ctx context.Context) (map[string]int, error) {
+	next, ok := s.next.(ship.RecordCounter)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("CountRecordsByTopic")
+	}
+	start := time.Now()
+	counts, err := next.CountRecordsByTopic(ctx)
+	s.observe(OpCountRecords, start, err)
+	return counts, err
+}
+
+func (s *shipStorage) EnsureIndexes(ctx context.Context) error {
+	start := time.Now()
+	err := s.next.EnsureIndexes(ctx)
+	s.observe(OpEnsureIndexes, start, err)
+	return err
+}
+
+// slapStorage instruments a slap.StorageInterface.
+type slapStorage struct {
+	next    slap.StorageInterface
+	metrics *Metrics
+}
+
+// observe records an operation on the SLAP storage.
+func (s *slapStorage) observe(operation string, start time.Time, err error) {
+	s.metrics.observeStorage("slap", operation, start, err)
+}
+
+func (s *slapStorage) StoreSLAPRecord(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string) error {
+	start := time.Now()
+	err := s.next.StoreSLAPRecord(ctx, txid, outputIndex, identityKey, domain, service)
+	s.observe(OpStore, start, err)
+	return err
+}
+
+func (s *slapStorage) StoreSLAPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial) error {
+	next, ok := s.next.(slap.TokenRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSLAPRecordWithToken")
+	}
+	start := time.Now()
+	err := next.StoreSLAPRecordWithToken(ctx, txid, outputIndex, identityKey, domain, service, token)
+	s.observe(OpStoreWithToken, start, err)
+	return err
+}
+
+func (s *slapStorage) StoreSLAPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial, createdAt time.Time) error {
+	next, ok := s.next.(slap.BackdatedRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSLAPRecordAt")
+	}
+	start := time.Now()
+	err := next.StoreSLAPRecordAt(ctx, txid, outputIndex, identityKey, domain, service, token, createdAt)
+	s.observe(OpStoreAt, start, err)
+	return err
+}
+
+func (s *slapStorage) DeleteSLAPRecord(ctx context.Context, txid string, outputIndex int) error {
+	start := time.Now()
+	err := s.next.DeleteSLAPRecord(ctx, txid, outputIndex)
+	s.observe(OpDelete, start, err)
+	return err
+}
+
+func (s *slapStorage) FindRecord(ctx context.Context, query types.SLAPQuery) ([]types.UTXOReference, error) {
+	start := time.Now()
+	refs, err := s.next.FindRecord(ctx, query)
+	s.observe(OpFindRecord, start, err)
+	return refs, err
+}
+
+func (s *slapStorage) QueryRecords(ctx context.Context, query types.SLAPQuery) ([]types.SLAPRecord, error) {
+	next, ok := s.next.(slap.RecordQuerier)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("QueryRecords")
+	}
+	start := time.Now()
+	records, err := next.QueryRecords(ctx, query)
+	s.observe(OpQueryRecords, start, err)
+	return records, err
+}
+
+func (s *slapStorage) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
+	start := time.Now()
+	refs, err := s.next.FindAll(ctx, limit, skip, sortOrder)
+	s.observe(OpFindAll, start, err)
+	return refs, err
+}
+
+func (s *slapStorage) FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SLAPRecord, error) {
+	next, ok := s.next.(slap.RecordReader)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("FindByOutpoint")
+	}
+	start := time.Now()
+	record, err := next.FindByOutpoint(ctx, txid, outputIndex)
+	s.observe(OpFindByOutpoint, start, err)
+	return record, err
+}
+
+func (s *slapStorage) ListRecords(ctx context.Context, limit, skip *int) ([]types.SLAPRecord, error) {
+	next, ok := s.next.(slap.RecordLister)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("ListRecords")
+	}
+	start := time.Now()
+	records, err := next.ListRecords(ctx, limit, skip)
+	s.observe(OpListRecords, start, err)
+	return records, err
+}
+
+func (s *slapStorage) ForEachRecord(ctx context.Context, fn func(types.SLAPRecord) error) error {
+	next, ok := s.next.(slap.RecordIterator)
+	if !ok {
+		return shared.UnsupportedStorageError("ForEachRecord")
+	}
+	start := time.Now()
+	err := next.ForEachRecord(ctx, fn)
+	s.observe(OpForEachRecord, start, err)
+	return err
+}
+
+func (s *slapStorage) ImportRecords(ctx context.Context, records []types.SLAPRecord) error {
+	next, ok := s.next.(slap.RecordImporter)
+	if !ok {
+		return shared.UnsupportedStorageError("ImportRecords")
+	}
+	start := time.Now()
+	err := next.ImportRecords(ctx, records)
+	s.observe(OpImportRecords, start, err)
+	return err
+}
+
+func (s *slapStorage) CountRecordsByService(ctx context.Context) (map[string]int, error) {
+	next, ok := s.next.(slap.RecordCounter)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("CountRecordsByService")
+	}
+	start := time.Now()
+	counts, err := next.CountRecordsByService(ctx)
+	s.observe(OpCountRecords, start, err)
+	return counts, err
+}
+
+func (s *slapStorage) EnsureIndexes(ctx context.Context) error {
+	start := time.Now()
+	err := s.next.EnsureIndexes(ctx)
+	s.observe(OpEnsureIndexes, start, err)
+	return err
+}
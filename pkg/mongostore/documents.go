@@ -0,0 +1,144 @@
+package mongostore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// outputDoc is the stored form of an engine.Output. Transaction IDs and outpoints are stored
+// as hex strings ("txid.index" for outpoints) so the documents stay readable and queryable.
+type outputDoc struct {
+	Txid            string             `bson:"txid"`
+	OutputIndex     uint32             `bson:"outputIndex"`
+	Topic           string             `bson:"topic"`
+	Spent           bool               `bson:"spent"`
+	OutputsConsumed []string           `bson:"outputsConsumed"`
+	ConsumedBy      []string           `bson:"consumedBy"`
+	BlockHeight     uint32             `bson:"blockHeight"`
+	BlockIdx        uint64             `bson:"blockIdx"`
+	Score           float64            `bson:"score"`
+	AncillaryTxids  []string           `bson:"ancillaryTxids"`
+	MerkleRoot      string             `bson:"merkleRoot,omitempty"`
+	MerkleState     engine.MerkleState `bson:"merkleState"`
+}
+
+// beefDoc is the stored BEEF of a transaction, keyed by its transaction ID.
+type beefDoc struct {
+	Txid string `bson:"_id"`
+	Beef []byte `bson:"beef"`
+}
+
+// newOutputDocs builds the documents for outputs admitted from a transaction. Outputs of mined
+// transactions record the block height and merkle root of their proof as validated.
+func newOutputDocs(topic string, txid *chainhash.Hash, outputs []uint32, outpointsConsumed []*transaction.Outpoint, beef *transaction.Beef, ancillaryTxids []*chainhash.Hash) ([]outputDoc, error) {
+	template := outputDoc{
+		Txid:            txid.String(),
+		Topic:           topic,
+		OutputsConsumed: outpointStrings(outpointsConsumed),
+		ConsumedBy:      []string{},
+		Score:           float64(time.Now().UnixMilli()),
+		AncillaryTxids:  make([]string, len(ancillaryTxids)),
+		MerkleState:     engine.MerkleStateUnmined,
+	}
+	for i, ancillary := range ancillaryTxids {
+		template.AncillaryTxids[i] = ancillary.String()
+	}
+
+	if beef != nil {
+		if tx := beef.FindTransactionByHash(txid); tx != nil && tx.MerklePath != nil {
+			root, err := tx.MerklePath.ComputeRoot(txid)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute merkle root for %s: %w", txid, err)
+			}
+			template.BlockHeight = tx.MerklePath.BlockHeight
+			template.MerkleRoot = root.String()
+			template.MerkleState = engine.MerkleStateValidated
+		}
+	}
+
+	docs := make([]outputDoc, len(outputs))
+	for i, vout := range outputs {
+		docs[i] = template
+		docs[i].OutputIndex = vout
+	}
+	return docs, nil
+}
+
+// outpoint returns the outpoint of the stored output.
+func (d outputDoc) outpoint() (*transaction.Outpoint, error) {
+	txid, err := chainhash.NewHashFromHex(d.Txid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored txid %q: %w", d.Txid, err)
+	}
+	return &transaction.Outpoint{Txid: *txid, Index: d.OutputIndex}, nil
+}
+
+// toOutput converts the document to an engine.Output without BEEF.
+func (d outputDoc) toOutput() (*engine.Output, error) {
+	outpoint, err := d.outpoint()
+	if err != nil {
+		return nil, err
+	}
+	outputsConsumed, err := parseOutpoints(d.OutputsConsumed)
+	if err != nil {
+		return nil, err
+	}
+	consumedBy, err := parseOutpoints(d.ConsumedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	ancillaryTxids := make([]*chainhash.Hash, len(d.AncillaryTxids))
+	for i, txid := range d.AncillaryTxids {
+		if ancillaryTxids[i], err = chainhash.NewHashFromHex(txid); err != nil {
+			return nil, fmt.Errorf("invalid stored ancillary txid %q: %w", txid, err)
+		}
+	}
+
+	var merkleRoot *chainhash.Hash
+	if d.MerkleRoot != "" {
+		if merkleRoot, err = chainhash.NewHashFromHex(d.MerkleRoot); err != nil {
+			return nil, fmt.Errorf("invalid stored merkle root %q: %w", d.MerkleRoot, err)
+		}
+	}
+
+	return &engine.Output{
+		Outpoint:        *outpoint,
+		Topic:           d.Topic,
+		Spent:           d.Spent,
+		OutputsConsumed: outputsConsumed,
+		ConsumedBy:      consumedBy,
+		BlockHeight:     d.BlockHeight,
+		BlockIdx:        d.BlockIdx,
+		Score:           d.Score,
+		AncillaryTxids:  ancillaryTxids,
+		MerkleRoot:      merkleRoot,
+		MerkleState:     d.MerkleState,
+	}, nil
+}
+
+// outpointStrings converts outpoints to their "txid.index" form.
+func outpointStrings(outpoints []*transaction.Outpoint) []string {
+	strs := make([]string, len(outpoints))
+	for i, outpoint := range outpoints {
+		strs[i] = outpoint.String()
+	}
+	return strs
+}
+
+// parseOutpoints parses outpoints stored in "txid.index" form.
+func parseOutpoints(strs []string) ([]*transaction.Outpoint, error) {
+	outpoints := make([]*transaction.Outpoint, len(strs))
+	for i, s := range strs {
+		outpoint, err := transaction.OutpointFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored outpoint %q: %w", s, err)
+		}
+		outpoints[i] = outpoint
+	}
+	return outpoints, nil
+}
@@ -0,0 +1,63 @@
+package mongostore
+
+import (
+	"testing"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOutputDoc_RoundTrip(t *testing.T) {
+	tx := transaction.NewTransaction()
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1, LockingScript: &script.Script{script.OpTRUE}})
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 2, LockingScript: &script.Script{script.OpTRUE}})
+	beef, err := transaction.NewBeefFromTransaction(tx)
+	require.NoError(t, err)
+	txid := tx.TxID()
+
+	consumed := &transaction.Outpoint{Txid: chainhash.DoubleHashH([]byte("parent")), Index: 3}
+	ancillary := chainhash.DoubleHashH([]byte("ancillary"))
+
+	docs, err := newOutputDocs("tm_ship", txid, []uint32{0, 1}, []*transaction.Outpoint{consumed}, beef, []*chainhash.Hash{&ancillary})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, uint32(1), docs[1].OutputIndex)
+	assert.Equal(t, engine.MerkleStateUnmined, docs[0].MerkleState)
+	assert.Empty(t, docs[0].MerkleRoot)
+
+	// Documents survive a BSON round trip unchanged
+	raw, err := bson.Marshal(docs[1])
+	require.NoError(t, err)
+	var decoded outputDoc
+	require.NoError(t, bson.Unmarshal(raw, &decoded))
+	assert.Equal(t, docs[1], decoded)
+
+	output, err := decoded.toOutput()
+	require.NoError(t, err)
+	assert.Equal(t, transaction.Outpoint{Txid: *txid, Index: 1}, output.Outpoint)
+	assert.Equal(t, "tm_ship", output.Topic)
+	require.Len(t, output.OutputsConsumed, 1)
+	assert.Equal(t, *consumed, *output.OutputsConsumed[0])
+	assert.Empty(t, output.ConsumedBy)
+	require.Len(t, output.AncillaryTxids, 1)
+	assert.Equal(t, ancillary, *output.AncillaryTxids[0])
+	assert.Nil(t, output.MerkleRoot)
+	assert.Nil(t, output.Beef)
+}
+
+func TestOutputDoc_InvalidStoredValues(t *testing.T) {
+	_, err := outputDoc{Txid: "not-hex"}.toOutput()
+	require.Error(t, err)
+
+	valid := chainhash.DoubleHashH([]byte("tx")).String()
+	_, err = outputDoc{Txid: valid, ConsumedBy: []string{"bad"}}.toOutput()
+	require.Error(t, err)
+
+	_, err = outputDoc{Txid: valid, MerkleRoot: "zz"}.toOutput()
+	require.Error(t, err)
+}
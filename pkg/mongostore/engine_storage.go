@@ -0,0 +1,472 @@
+// Package mongostore provides a MongoDB-backed overlay engine output store, so a discovery
+// server keeps the outputs, BEEF and sync state behind its SHIP and SLAP records across restarts.
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+)
+
+// Compile-time verification that EngineStorage implements engine.Storage
+var _ engine.Storage = (*EngineStorage)(nil)
+
+// EngineStorage implements the overlay engine's output storage on MongoDB. Outputs are stored
+// per topic in the "outputs" collection and each transaction's BEEF once in "beefs", alongside
+// the "appliedTransactions" and "interactions" bookkeeping collections.
+type EngineStorage struct {
+	outputs      *mongo.Collection
+	beefs        *mongo.Collection
+	applied      *mongo.Collection
+	interactions *mongo.Collection
+	// logger receives storage events (optional)
+	logger *slog.Logger
+}
+
+// NewEngineStorage constructs an EngineStorage using collections of the provided database.
+func NewEngineStorage(db *mongo.Database) *EngineStorage {
+	return &EngineStorage{
+		outputs:      db.Collection("outputs"),
+		beefs:        db.Collection("beefs"),
+		applied:      db.Collection("appliedTransactions"),
+		interactions: db.Collection("interactions"),
+	}
+}
+
+// SetLogger sets the logger receiving storage events, such as cursors that failed to close.
+// A nil logger (the default) discards them.
+func (s *EngineStorage) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// EnsureIndexes creates the indexes used by the engine's queries, including the unique
+// indexes that make repeated inserts idempotent. It should be called once during startup.
+func (s *EngineStorage) EnsureIndexes(ctx context.Context) error {
+	indexes := []struct {
+		collection *mongo.Collection
+		models     []mongo.IndexModel
+	}{
+		{s.outputs, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "txid", Value: 1}, {Key: "outputIndex", Value: 1}, {Key: "topic", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "topic", Value: 1}, {Key: "spent", Value: 1}, {Key: "score", Value: 1}}},
+			{Keys: bson.D{{Key: "topic", Value: 1}, {Key: "merkleState", Value: 1}}},
+			{Keys: bson.D{{Key: "topic", Value: 1}, {Key: "blockHeight", Value: 1}}},
+		}},
+		{s.applied, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "txid", Value: 1}, {Key: "topic", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+		{s.interactions, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "host", Value: 1}, {Key: "topic", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}}},
+	}
+
+	for _, index := range indexes {
+		if _, err := index.collection.Indexes().CreateMany(ctx, index.models); err != nil {
+			return fmt.Errorf("failed to create indexes for %s: %w", index.collection.Name(), err)
+		}
+	}
+	return nil
+}
+
+// InsertOutputs adds the admitted outputs of a transaction to a topic and stores its BEEF.
+// Reinserting an output replaces it, so a submission interrupted part way can be retried.
+func (s *EngineStorage) InsertOutputs(ctx context.Context, topic string, txid *chainhash.Hash, outputs []uint32, outpointsConsumed []*transaction.Outpoint, beef *transaction.Beef, ancillaryTxids []*chainhash.Hash) error {
+	if beef != nil {
+		if err := s.UpdateTransactionBEEF(ctx, txid, beef); err != nil {
+			return err
+		}
+	}
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	docs, err := newOutputDocs(topic, txid, outputs, outpointsConsumed, beef, ancillaryTxids)
+	if err != nil {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, len(docs))
+	for i, doc := range docs {
+		models[i] = mongo.NewReplaceOneModel().
+			SetFilter(outputFilter(doc.Txid, doc.OutputIndex, topic)).
+			SetReplacement(doc).
+			SetUpsert(true)
+	}
+	if _, err := s.outputs.BulkWrite(ctx, models); err != nil {
+		return fmt.Errorf("failed to insert outputs: %w", err)
+	}
+	return nil
+}
+
+// FindOutput returns the output at the outpoint, optionally restricted to a topic and spent
+// state. Returns nil (no error) if no such output exists.
+func (s *EngineStorage) FindOutput(ctx context.Context, outpoint *transaction.Outpoint, topic *string, spent *bool, includeBEEF bool) (*engine.Output, error) {
+	filter := bson.M{"txid": outpoint.Txid.String(), "outputIndex": outpoint.Index}
+	if topic != nil {
+		filter["topic"] = *topic
+	}
+	if spent != nil {
+		filter["spent"] = *spent
+	}
+
+	var doc outputDoc
+	err := s.outputs.FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "score", Value: 1}})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil //nolint:nilnil // nil,nil means no output stored
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find output: %w", err)
+	}
+
+	return s.toOutput(ctx, doc, includeBEEF)
+}
+
+// FindOutputs returns the outputs at the outpoints in a topic. The result is aligned with
+// outpoints, holding nil for each outpoint without a matching output.
+func (s *EngineStorage) FindOutputs(ctx context.Context, outpoints []*transaction.Outpoint, topic string, spent *bool, includeBEEF bool) ([]*engine.Output, error) {
+	results := make([]*engine.Output, len(outpoints))
+	if len(outpoints) == 0 {
+		return results, nil
+	}
+
+	keys := make(bson.A, len(outpoints))
+	for i, outpoint := range outpoints {
+		keys[i] = bson.M{"txid": outpoint.Txid.String(), "outputIndex": outpoint.Index}
+	}
+	filter := bson.M{"topic": topic, "$or": keys}
+	if spent != nil {
+		filter["spent"] = *spent
+	}
+
+	docs, err := s.findOutputDocs(ctx, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[transaction.Outpoint]outputDoc, len(docs))
+	for _, doc := range docs {
+		outpoint, err := doc.outpoint()
+		if err != nil {
+			return nil, err
+		}
+		found[*outpoint] = doc
+	}
+
+	for i, outpoint := range outpoints {
+		doc, ok := found[*outpoint]
+		if !ok {
+			continue
+		}
+		if results[i], err = s.toOutput(ctx, doc, includeBEEF); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// FindOutputsForTransaction returns every output of the transaction in every topic.
+func (s *EngineStorage) FindOutputsForTransaction(ctx context.Context, txid *chainhash.Hash, includeBEEF bool) ([]*engine.Output, error) {
+	docs, err := s.findOutputDocs(ctx, bson.M{"txid": txid.String()}, options.Find().SetSort(bson.D{{Key: "outputIndex", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	return s.toOutputs(ctx, docs, includeBEEF)
+}
+
+// FindUTXOsForTopic returns the unspent outputs of a topic admitted at or after the since
+// score, oldest first, returning at most limit outputs when limit is greater than zero.
+func (s *EngineStorage) FindUTXOsForTopic(ctx context.Context, topic string, since float64, limit uint32, includeBEEF bool) ([]*engine.Output, error) {
+	findOpts := options.Find().SetSort(bson.D{{Key: "score", Value: 1}})
+	if limit > 0 {
+		findOpts.SetLimit(int64(limit))
+	}
+
+	docs, err := s.findOutputDocs(ctx, bson.M{"topic": topic, "spent": false, "score": bson.M{"$gte": since}}, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	return s.toOutputs(ctx, docs, includeBEEF)
+}
+
+// DeleteOutput removes an output from a topic. The transaction's BEEF is deleted once no
+// output in any topic refers to it.
+func (s *EngineStorage) DeleteOutput(ctx context.Context, outpoint *transaction.Outpoint, topic string) error {
+	txid := outpoint.Txid.String()
+	if _, err := s.outputs.DeleteOne(ctx, outputFilter(txid, outpoint.Index, topic)); err != nil {
+		return fmt.Errorf("failed to delete output: %w", err)
+	}
+
+	remaining, err := s.outputs.CountDocuments(ctx, bson.M{"txid": txid}, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("failed to count outputs: %w", err)
+	}
+	if remaining == 0 {
+		if _, err := s.beefs.DeleteOne(ctx, bson.M{"_id": txid}); err != nil {
+			return fmt.Errorf("failed to delete BEEF: %w", err)
+		}
+	}
+	return nil
+}
+
+// MarkUTXOsAsSpent marks the outputs at the outpoints in a topic as spent.
+func (s *EngineStorage) MarkUTXOsAsSpent(ctx context.Context, outpoints []*transaction.Outpoint, topic string, _ *chainhash.Hash) error {
+	if len(outpoints) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, len(outpoints))
+	for i, outpoint := range outpoints {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(outputFilter(outpoint.Txid.String(), outpoint.Index, topic)).
+			SetUpdate(bson.M{"$set": bson.M{"spent": true}})
+	}
+	if _, err := s.outputs.BulkWrite(ctx, models); err != nil {
+		return fmt.Errorf("failed to mark outputs as spent: %w", err)
+	}
+	return nil
+}
+
+// UpdateConsumedBy replaces the outputs recorded as consuming an output in a topic.
+func (s *EngineStorage) UpdateConsumedBy(ctx context.Context, outpoint *transaction.Outpoint, topic string, consumedBy []*transaction.Outpoint) error {
+	return s.updateOutput(ctx, outpoint, topic, bson.M{"consumedBy": outpointStrings(consumedBy)}, "consumed by")
+}
+
+// UpdateTransactionBEEF replaces the stored BEEF of a transaction.
+func (s *EngineStorage) UpdateTransactionBEEF(ctx context.Context, txid *chainhash.Hash, beef *transaction.Beef) error {
+	beefBytes, err := beef.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to serialize BEEF: %w", err)
+	}
+
+	_, err = s.beefs.ReplaceOne(ctx, bson.M{"_id": txid.String()}, beefDoc{Txid: txid.String(), Beef: beefBytes}, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to store BEEF: %w", err)
+	}
+	return nil
+}
+
+// UpdateOutputBlockHeight records the block an output's transaction was mined in.
+func (s *EngineStorage) UpdateOutputBlockHeight(ctx context.Context, outpoint *transaction.Outpoint, topic string, blockHeight uint32, blockIndex uint64) error {
+	return s.updateOutput(ctx, outpoint, topic, bson.M{"blockHeight": blockHeight, "blockIdx": blockIndex}, "block height")
+}
+
+// InsertAppliedTransaction records that a transaction was applied to a topic.
+func (s *EngineStorage) InsertAppliedTransaction(ctx context.Context, tx *overlay.AppliedTransaction) error {
+	filter := bson.M{"txid": tx.Txid.String(), "topic": tx.Topic}
+	if _, err := s.applied.UpdateOne(ctx, filter, bson.M{"$setOnInsert": filter}, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to insert applied transaction: %w", err)
+	}
+	return nil
+}
+
+// DoesAppliedTransactionExist reports whether a transaction was already applied to a topic.
+func (s *EngineStorage) DoesAppliedTransactionExist(ctx context.Context, tx *overlay.AppliedTransaction) (bool, error) {
+	count, err := s.applied.CountDocuments(ctx, bson.M{"txid": tx.Txid.String(), "topic": tx.Topic}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to find applied transaction: %w", err)
+	}
+	return count > 0, nil
+}
+
+// UpdateLastInteraction records the score of the last synchronization with a peer for a topic.
+func (s *EngineStorage) UpdateLastInteraction(ctx context.Context, host, topic string, since float64) error {
+	filter := bson.M{"host": host, "topic": topic}
+	if _, err := s.interactions.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"score": since}}, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to update last interaction: %w", err)
+	}
+	return nil
+}
+
+// GetLastInteraction returns the score of the last synchronization with a peer for a topic,
+// or 0 if there has been none.
+func (s *EngineStorage) GetLastInteraction(ctx context.Context, host, topic string) (float64, error) {
+	var doc struct {
+		Score float64 `bson:"score"`
+	}
+	err := s.interactions.FindOne(ctx, bson.M{"host": host, "topic": topic}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find last interaction: %w", err)
+	}
+	return doc.Score, nil
+}
+
+// FindOutpointsByMerkleState returns the outpoints of a topic in the given merkle validation
+// state, returning at most limit outpoints when limit is greater than zero.
+func (s *EngineStorage) FindOutpointsByMerkleState(ctx context.Context, topic string, state engine.MerkleState, limit uint32) ([]*transaction.Outpoint, error) {
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "score", Value: 1}}).
+		SetProjection(bson.M{"txid": 1, "outputIndex": 1})
+	if limit > 0 {
+		findOpts.SetLimit(int64(limit))
+	}
+
+	docs, err := s.findOutputDocs(ctx, bson.M{"topic": topic, "merkleState": state}, findOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	outpoints := make([]*transaction.Outpoint, len(docs))
+	for i, doc := range docs {
+		if outpoints[i], err = doc.outpoint(); err != nil {
+			return nil, err
+		}
+	}
+	return outpoints, nil
+}
+
+// ReconcileMerkleRoot compares the merkle roots of a topic's outputs mined at the block height
+// with the authoritative root, marking matching outputs validated and the others invalidated.
+// Outputs without a merkle root are left unmined and immutable outputs are left unchanged.
+func (s *EngineStorage) ReconcileMerkleRoot(ctx context.Context, topic string, blockHeight uint32, merkleRoot *chainhash.Hash) error {
+	root := merkleRoot.String()
+	base := bson.M{
+		"topic":       topic,
+		"blockHeight": blockHeight,
+		"merkleState": bson.M{"$ne": engine.MerkleStateImmutable},
+	}
+
+	matching := bson.M{"merkleRoot": root}
+	for k, v := range base {
+		matching[k] = v
+	}
+	if _, err := s.outputs.UpdateMany(ctx, matching, bson.M{"$set": bson.M{"merkleState": engine.MerkleStateValidated}}); err != nil {
+		return fmt.Errorf("failed to validate outputs: %w", err)
+	}
+
+	mismatched := bson.M{"merkleRoot": bson.M{"$exists": true, "$ne": root}}
+	for k, v := range base {
+		mismatched[k] = v
+	}
+	if _, err := s.outputs.UpdateMany(ctx, mismatched, bson.M{"$set": bson.M{"merkleState": engine.MerkleStateInvalidated}}); err != nil {
+		return fmt.Errorf("failed to invalidate outputs: %w", err)
+	}
+	return nil
+}
+
+// LoadAncillaryBeef merges the stored BEEF of each of the output's ancillary transactions
+// into its BEEF.
+func (s *EngineStorage) LoadAncillaryBeef(ctx context.Context, output *engine.Output) error {
+	if output == nil || output.Beef == nil {
+		return nil
+	}
+
+	for _, txid := range output.AncillaryTxids {
+		if output.Beef.FindTransactionByHash(txid) != nil {
+			continue
+		}
+		beef, err := s.loadBeef(ctx, txid.String())
+		if err != nil {
+			return err
+		}
+		if beef == nil {
+			continue
+		}
+		if err := output.Beef.MergeBeef(beef); err != nil {
+			return fmt.Errorf("failed to merge ancillary BEEF %s: %w", txid, err)
+		}
+	}
+	return nil
+}
+
+// updateOutput sets fields on an output in a topic.
+func (s *EngineStorage) updateOutput(ctx context.Context, outpoint *transaction.Outpoint, topic string, fields bson.M, what string) error {
+	if _, err := s.outputs.UpdateOne(ctx, outputFilter(outpoint.Txid.String(), outpoint.Index, topic), bson.M{"$set": fields}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", what, err)
+	}
+	return nil
+}
+
+// findOutputDocs returns the output documents matching the filter.
+func (s *EngineStorage) findOutputDocs(ctx context.Context, filter bson.M, findOpts *options.FindOptions) ([]outputDoc, error) {
+	if findOpts == nil {
+		findOpts = options.Find()
+	}
+
+	cursor, err := s.outputs.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find outputs: %w", err)
+	}
+	defer shared.CloseCursor(shared.ContextWithLogger(ctx, s.logger), cursor, "output")
+
+	var docs []outputDoc
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode outputs: %w", err)
+	}
+	return docs, nil
+}
+
+// toOutputs converts output documents, loading each transaction's BEEF at most once.
+func (s *EngineStorage) toOutputs(ctx context.Context, docs []outputDoc, includeBEEF bool) ([]*engine.Output, error) {
+	outputs := make([]*engine.Output, len(docs))
+	beefs := make(map[string]*transaction.Beef)
+	for i, doc := range docs {
+		output, err := doc.toOutput()
+		if err != nil {
+			return nil, err
+		}
+		if includeBEEF {
+			beef, ok := beefs[doc.Txid]
+			if !ok {
+				if beef, err = s.loadBeef(ctx, doc.Txid); err != nil {
+					return nil, err
+				}
+				beefs[doc.Txid] = beef
+			}
+			if beef != nil {
+				output.Beef = beef.Clone()
+			}
+		}
+		outputs[i] = output
+	}
+	return outputs, nil
+}
+
+// toOutput converts an output document, loading its transaction's BEEF if requested.
+func (s *EngineStorage) toOutput(ctx context.Context, doc outputDoc, includeBEEF bool) (*engine.Output, error) {
+	outputs, err := s.toOutputs(ctx, []outputDoc{doc}, includeBEEF)
+	if err != nil {
+		return nil, err
+	}
+	return outputs[0], nil
+}
+
+// loadBeef returns the stored BEEF of a transaction, or nil if none is stored.
+func (s *EngineStorage) loadBeef(ctx context.Context, txid string) (*transaction.Beef, error) {
+	var doc beefDoc
+	err := s.beefs.FindOne(ctx, bson.M{"_id": txid}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil //nolint:nilnil // nil,nil means no BEEF stored
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find BEEF: %w", err)
+	}
+
+	beef, err := transaction.NewBeefFromBytes(doc.Beef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stored BEEF for %s: %w", txid, err)
+	}
+	return beef, nil
+}
+
+// outputFilter selects one output in a topic.
+func outputFilter(txid string, outputIndex uint32, topic string) bson.M {
+	return bson.M{"txid": txid, "outputIndex": outputIndex, "topic": topic}
+}
@@ -0,0 +1,166 @@
+package mongostore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// testTransaction returns a transaction with two outputs and its BEEF.
+func testTransaction(t *testing.T) (*chainhash.Hash, *transaction.Beef) {
+	t.Helper()
+	tx := transaction.NewTransaction()
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1, LockingScript: &script.Script{script.OpTRUE}})
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 2, LockingScript: &script.Script{script.OpTRUE}})
+	beef, err := transaction.NewBeefFromTransaction(tx)
+	require.NoError(t, err)
+	return tx.TxID(), beef
+}
+
+// outputDocument returns the stored form of an output, as returned by a mock cursor.
+func outputDocument(t *testing.T, txid *chainhash.Hash, outputIndex uint32) bson.D {
+	t.Helper()
+	docs, err := newOutputDocs("tm_ship", txid, []uint32{outputIndex}, nil, nil, nil)
+	require.NoError(t, err)
+	raw, err := bson.Marshal(docs[0])
+	require.NoError(t, err)
+	var doc bson.D
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+	return doc
+}
+
+func TestEngineStorage_InsertOutputsUpserts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("insert", func(mt *mtest.T) {
+		storage := NewEngineStorage(mt.DB)
+		txid, beef := testTransaction(t)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		require.NoError(mt, storage.InsertOutputs(context.Background(), "tm_ship", txid, []uint32{0, 1}, nil, beef, nil))
+
+		beefUpdate := mt.GetStartedEvent().Command
+		assert.Equal(mt, "beefs", beefUpdate.Lookup("update").StringValue())
+		assert.Equal(mt, txid.String(), beefUpdate.Lookup("updates", "0", "q", "_id").StringValue())
+		assert.True(mt, beefUpdate.Lookup("updates", "0", "upsert").Boolean())
+
+		outputsUpdate := mt.GetStartedEvent().Command
+		assert.Equal(mt, "outputs", outputsUpdate.Lookup("update").StringValue())
+		updates, err := outputsUpdate.Lookup("updates").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, updates, 2)
+		for i, update := range updates {
+			filter := update.Document().Lookup("q").Document()
+			assert.Equal(mt, txid.String(), filter.Lookup("txid").StringValue())
+			assert.Equal(mt, int64(i), filter.Lookup("outputIndex").AsInt64())
+			assert.Equal(mt, "tm_ship", filter.Lookup("topic").StringValue())
+			assert.True(mt, update.Document().Lookup("upsert").Boolean(), "reinserting an output must replace it")
+		}
+	})
+}
+
+func TestEngineStorage_FindOutputsAligned(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("find", func(mt *mtest.T) {
+		storage := NewEngineStorage(mt.DB)
+		first := chainhash.DoubleHashH([]byte("first"))
+		missing := chainhash.DoubleHashH([]byte("missing"))
+		last := chainhash.DoubleHashH([]byte("last"))
+		ns := mt.DB.Name() + ".outputs"
+		// Returned in a different order than requested
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			outputDocument(t, &last, 2),
+			outputDocument(t, &first, 0),
+		))
+
+		outputs, err := storage.FindOutputs(context.Background(), []*transaction.Outpoint{
+			{Txid: first, Index: 0},
+			{Txid: missing, Index: 1},
+			{Txid: last, Index: 2},
+		}, "tm_ship", nil, false)
+		require.NoError(mt, err)
+
+		require.Len(mt, outputs, 3)
+		require.NotNil(mt, outputs[0])
+		assert.Equal(mt, transaction.Outpoint{Txid: first, Index: 0}, outputs[0].Outpoint)
+		assert.Nil(mt, outputs[1], "an outpoint without a stored output must hold nil")
+		require.NotNil(mt, outputs[2])
+		assert.Equal(mt, transaction.Outpoint{Txid: last, Index: 2}, outputs[2].Outpoint)
+	})
+}
+
+func TestEngineStorage_DeleteOutput(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	txid := chainhash.DoubleHashH([]byte("tx"))
+	outpoint := &transaction.Outpoint{Txid: txid, Index: 0}
+
+	mt.Run("deletes BEEF of last output", func(mt *mtest.T) {
+		storage := NewEngineStorage(mt.DB)
+		ns := mt.DB.Name() + ".outputs"
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		require.NoError(mt, storage.DeleteOutput(context.Background(), outpoint, "tm_ship"))
+
+		assert.Equal(mt, "outputs", mt.GetStartedEvent().Command.Lookup("delete").StringValue())
+		assert.Equal(mt, "outputs", mt.GetStartedEvent().Command.Lookup("aggregate").StringValue())
+		beefDelete := mt.GetStartedEvent().Command
+		assert.Equal(mt, "beefs", beefDelete.Lookup("delete").StringValue())
+		assert.Equal(mt, txid.String(), beefDelete.Lookup("deletes", "0", "q", "_id").StringValue())
+	})
+
+	mt.Run("keeps BEEF of remaining outputs", func(mt *mtest.T) {
+		storage := NewEngineStorage(mt.DB)
+		ns := mt.DB.Name() + ".outputs"
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: 1}}),
+		)
+
+		require.NoError(mt, storage.DeleteOutput(context.Background(), outpoint, "tm_slap"))
+
+		mt.GetStartedEvent() // the output delete
+		mt.GetStartedEvent() // the remaining output count
+		assert.Nil(mt, mt.GetStartedEvent(), "BEEF referenced by another output must be kept")
+	})
+}
+
+func TestEngineStorage_ReconcileMerkleRoot(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("reconcile", func(mt *mtest.T) {
+		storage := NewEngineStorage(mt.DB)
+		root := chainhash.DoubleHashH([]byte("root"))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		require.NoError(mt, storage.ReconcileMerkleRoot(context.Background(), "tm_ship", 800000, &root))
+
+		validate := mt.GetStartedEvent().Command.Lookup("updates", "0").Document()
+		assert.Equal(mt, root.String(), validate.Lookup("q", "merkleRoot").StringValue())
+		assert.Equal(mt, int32(engine.MerkleStateValidated), validate.Lookup("u", "$set", "merkleState").Int32())
+
+		invalidate := mt.GetStartedEvent().Command.Lookup("updates", "0").Document()
+		assert.Equal(mt, root.String(), invalidate.Lookup("q", "merkleRoot", "$ne").StringValue())
+		assert.True(mt, invalidate.Lookup("q", "merkleRoot", "$exists").Boolean(), "outputs without a merkle root must stay unmined")
+		assert.Equal(mt, int32(engine.MerkleStateInvalidated), invalidate.Lookup("u", "$set", "merkleState").Int32())
+
+		for _, update := range []bson.Raw{validate, invalidate} {
+			assert.Equal(mt, "tm_ship", update.Lookup("q", "topic").StringValue())
+			assert.Equal(mt, int64(800000), update.Lookup("q", "blockHeight").AsInt64())
+			assert.Equal(mt, int32(engine.MerkleStateImmutable), update.Lookup("q", "merkleState", "$ne").Int32())
+			assert.True(mt, update.Lookup("multi").Boolean())
+		}
+	})
+}
@@ -0,0 +1,304 @@
+// Package rest implements resource-style JSON endpoints over stored SHIP and SLAP records,
+// for clients that want to browse discovery data without composing overlay lookup questions.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Pagination response headers set by list endpoints.
+const (
+	// HeaderLimit is the limit applied to the page
+	HeaderLimit = "X-Page-Limit"
+	// HeaderSkip is the number of records skipped before the page
+	HeaderSkip = "X-Page-Skip"
+	// HeaderCount is the number of records in the page
+	HeaderCount = "X-Result-Count"
+)
+
+// Error codes returned in ErrorResponse.Code.
+const (
+	// CodeInvalidRequest reports a malformed path or query parameter
+	CodeInvalidRequest = "invalid_request"
+	// CodeNotFound reports an unknown record or endpoint
+	CodeNotFound = "not_found"
+	// CodeMethodNotAllowed reports a request method other than GET
+	CodeMethodNotAllowed = "method_not_allowed"
+	// CodeInternal reports a storage failure
+	CodeInternal = "internal_error"
+	// CodeNotImplemented reports an endpoint the configured storage cannot serve
+	CodeNotImplemented = "not_implemented"
+)
+
+// ErrorResponse is the JSON body of every error response.
+type ErrorResponse struct {
+	// Code is a stable, machine-readable error code
+	Code string `json:"code"`
+	// Message describes the error
+	Message string `json:"message"`
+}
+
+// Handler is an http.Handler serving stored SHIP and SLAP records as JSON:
+//
+//	GET /ship/records                              SHIP records filtered by domain, topic and identityKey
+//	GET /ship/records/{txid}/{outputIndex}         a single SHIP record
+//	GET /ship/topics/{topic}/hosts                 SHIP records advertising hosts for a topic
+//	GET /ship/identities/{identityKey}/records     SHIP records advertised by an identity
+//	GET /slap/records                              SLAP records filtered by domain, service and identityKey
+//	GET /slap/records/{txid}/{outputIndex}         a single SLAP record
+//	GET /slap/services/{service}/trackers          SLAP records advertising trackers for a service
+//	GET /slap/identities/{identityKey}/records     SLAP records advertised by an identity
+//
+// List endpoints need storage implementing RecordQuerier and single record endpoints storage
+// implementing RecordReader; without it they respond with 501. List endpoints accept the limit,
+// skip and sortOrder parameters parsed by ParsePagination, report the page in the X-Page-Limit,
+// X-Page-Skip and X-Result-Count headers, and link the neighbouring pages in a Link header. Records are served without their token material, which
+// is kept for re-verifying signatures and is available from the overlay lookup services.
+// Errors are ErrorResponse bodies. SetLogger must be called before the handler serves requests.
+type Handler struct {
+	// shipStorage serves SHIP records (optional)
+	shipStorage ship.StorageInterface
+	// slapStorage serves SLAP records (optional)
+	slapStorage slap.StorageInterface
+	// mux routes requests to the endpoints
+	mux *http.ServeMux
+	// logger receives storage failures and response write errors
+	logger *slog.Logger
+}
+
+// NewHandler creates a handler serving records from the given storages. Either storage may
+// be nil, in which case that protocol's endpoints respond with 404.
+func NewHandler(shipStorage ship.StorageInterface, slapStorage slap.StorageInterface) *Handler {
+	h := &Handler{
+		shipStorage: shipStorage,
+		slapStorage: slapStorage,
+		mux:         http.NewServeMux(),
+		logger:      shared.Logger(context.Background(), nil),
+	}
+
+	h.mux.HandleFunc("/ship/records", h.listSHIPRecords)
+	h.mux.HandleFunc("/ship/records/{txid}/{outputIndex}", h.getSHIPRecord)
+	h.mux.HandleFunc("/ship/topics/{topic}/hosts", h.listSHIPRecords)
+	h.mux.HandleFunc("/ship/identities/{identityKey}/records", h.listSHIPRecords)
+	h.mux.HandleFunc("/slap/records", h.listSLAPRecords)
+	h.mux.HandleFunc("/slap/records/{txid}/{outputIndex}", h.getSLAPRecord)
+	h.mux.HandleFunc("/slap/services/{service}/trackers", h.listSLAPRecords)
+	h.mux.HandleFunc("/slap/identities/{identityKey}/records", h.listSLAPRecords)
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		h.writeError(w, http.StatusNotFound, CodeNotFound, "no such endpoint")
+	})
+
+	return h
+}
+
+// SetLogger sets the logger of storage failures; nil discards them.
+func (h *Handler) SetLogger(logger *slog.Logger) {
+	h.logger = shared.Logger(context.Background(), logger)
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		h.writeError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "only GET is supported")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// listSHIPRecords serves the SHIP list endpoints, with path parameters taking precedence
+// over the equivalent query parameters.
+func (h *Handler) listSHIPRecords(w http.ResponseWriter, r *http.Request) {
+	if h.shipStorage == nil {
+		h.writeError(w, http.StatusNotFound, CodeNotFound, "SHIP records are not served")
+		return
+	}
+
+	querier, ok := h.shipStorage.(ship.RecordQuerier)
+	if !ok {
+		h.writeError(w, http.StatusNotImplemented, CodeNotImplemented, "the SHIP storage cannot list records")
+		return
+	}
+
+	query, err := ParseSHIPQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+	if topic := r.PathValue("topic"); topic != "" {
+		query.Topics = []string{topic}
+	}
+	if identityKey := r.PathValue("identityKey"); identityKey != "" {
+		query.IdentityKey = &identityKey
+	}
+
+	records, err := querier.QueryRecords(r.Context(), query)
+	if err != nil {
+		h.writeStorageError(w, "SHIP", err)
+		return
+	}
+	for i := range records {
+		records[i] = shipRecordView(records[i])
+	}
+	writePage(h, w, r, Pagination{Limit: *query.Limit, Skip: *query.Skip, SortOrder: *query.SortOrder}, records)
+}
+
+// listSLAPRecords serves the SLAP list endpoints, with path parameters taking precedence
+// over the equivalent query parameters.
+func (h *Handler) listSLAPRecords(w http.ResponseWriter, r *http.Request) {
+	if h.slapStorage == nil {
+		h.writeError(w, http.StatusNotFound, CodeNotFound, "SLAP records are not served")
+		return
+	}
+
+	querier, ok := h.slapStorage.(slap.RecordQuerier)
+	if !ok {
+		h.writeError(w, http.StatusNotImplemented, CodeNotImplemented, "the SLAP storage cannot list records")
+		return
+	}
+
+	query, err := ParseSLAPQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+	if service := r.PathValue("service"); service != "" {
+		query.Service = &service
+	}
+	if identityKey := r.PathValue("identityKey"); identityKey != "" {
+		query.IdentityKey = &identityKey
+	}
+
+	records, err := querier.QueryRecords(r.Context(), query)
+	if err != nil {
+		h.writeStorageError(w, "SLAP", err)
+		return
+	}
+	for i := range records {
+		records[i] = slapRecordView(records[i])
+	}
+	writePage(h, w, r, Pagination{Limit: *query.Limit, Skip: *query.Skip, SortOrder: *query.SortOrder}, records)
+}
+
+// getSHIPRecord serves a single SHIP record.
+func (h *Handler) getSHIPRecord(w http.ResponseWriter, r *http.Request) {
+	if h.shipStorage == nil {
+		h.writeError(w, http.StatusNotFound, CodeNotFound, "SHIP records are not served")
+		return
+	}
+	reader, ok := h.shipStorage.(ship.RecordReader)
+	if !ok {
+		h.writeError(w, http.StatusNotImplemented, CodeNotImplemented, "the SHIP storage cannot look up single records")
+		return
+	}
+	getRecord(h, w, r, "SHIP", reader.FindByOutpoint, shipRecordView)
+}
+
+// shipRecordView returns a SHIP record as served, without its token material.
+func shipRecordView(record types.SHIPRecord) types.SHIPRecord {
+	record.Token = nil
+	return record
+}
+
+// getSLAPRecord serves a single SLAP record.
+func (h *Handler) getSLAPRecord(w http.ResponseWriter, r *http.Request) {
+	if h.slapStorage == nil {
+		h.writeError(w, http.StatusNotFound, CodeNotFound, "SLAP records are not served")
+		return
+	}
+	reader, ok := h.slapStorage.(slap.RecordReader)
+	if !ok {
+		h.writeError(w, http.StatusNotImplemented, CodeNotImplemented, "the SLAP storage cannot look up single records")
+		return
+	}
+	getRecord(h, w, r, "SLAP", reader.FindByOutpoint, slapRecordView)
+}
+
+// slapRecordView returns a SLAP record as served, without its token material.
+func slapRecordView(record types.SLAPRecord) types.SLAPRecord {
+	record.Token = nil
+	return record
+}
+
+// findByOutpoint returns the record stored for an outpoint, or nil if there is none.
+type findByOutpoint[T any] func(ctx context.Context, txid string, outputIndex int) (*T, error)
+
+// getRecord writes the record stored at the outpoint named by the request path, as returned by view.
+func getRecord[T any](h *Handler, w http.ResponseWriter, r *http.Request, protocol string, find findByOutpoint[T], view func(T) T) {
+	outputIndex, err := parseOutputIndex(r.PathValue("outputIndex"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	record, err := find(r.Context(), r.PathValue("txid"), outputIndex)
+	if err != nil {
+		h.writeStorageError(w, protocol, err)
+		return
+	}
+	if record == nil {
+		h.writeError(w, http.StatusNotFound, CodeNotFound, fmt.Sprintf("no %s record at %s.%d", protocol, r.PathValue("txid"), outputIndex))
+		return
+	}
+	h.writeJSON(w, http.StatusOK, view(*record))
+}
+
+// writePage writes a page of records with its pagination headers. A next link is included
+// when the page is full, since more records may follow.
+func writePage[T any](h *Handler, w http.ResponseWriter, r *http.Request, page Pagination, records []T) {
+	w.Header().Set(HeaderLimit, strconv.Itoa(page.Limit))
+	w.Header().Set(HeaderSkip, strconv.Itoa(page.Skip))
+	w.Header().Set(HeaderCount, strconv.Itoa(len(records)))
+
+	var links []string
+	if page.Skip > 0 {
+		links = append(links, pageLink(r.URL, page.Limit, max(page.Skip-page.Limit, 0), "prev"))
+	}
+	if len(records) == page.Limit {
+		links = append(links, pageLink(r.URL, page.Limit, page.Skip+page.Limit, "next"))
+	}
+	for _, link := range links {
+		w.Header().Add("Link", link)
+	}
+
+	h.writeJSON(w, http.StatusOK, records)
+}
+
+// pageLink returns a Link header value for the request URL with a different page.
+func pageLink(u *url.URL, limit, skip int, rel string) string {
+	values := u.Query()
+	values.Set("limit", strconv.Itoa(limit))
+	values.Set("skip", strconv.Itoa(skip))
+	return fmt.Sprintf("<%s?%s>; rel=%q", u.Path, values.Encode(), rel)
+}
+
+// writeStorageError logs a storage failure and reports it without internal details.
+func (h *Handler) writeStorageError(w http.ResponseWriter, protocol string, err error) {
+	h.logger.Error("Failed to query records", shared.LogKeyProtocol, protocol, shared.LogKeyError, err)
+	h.writeError(w, http.StatusInternalServerError, CodeInternal, fmt.Sprintf("failed to query %s records", protocol))
+}
+
+// writeError writes an ErrorResponse with the status code.
+func (h *Handler) writeError(w http.ResponseWriter, status int, code, message string) {
+	h.writeJSON(w, status, ErrorResponse{Code: code, Message: message})
+}
+
+// writeJSON writes v as a JSON body with the status code.
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Warn("Failed to write response", shared.LogKeyError, err)
+	}
+}
@@ -0,0 +1,227 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/memstore"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Test helper functions
+
+// testToken is token material stored with some records, which the handler must not serve.
+var testToken = &types.TokenMaterial{Fields: [][]byte{[]byte("SHIP")}, LockingPublicKey: "02abcd"}
+
+func createTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	shipStorage := memstore.NewSHIPStorage()
+	require.NoError(t, shipStorage.ImportRecords(ctx, []types.SHIPRecord{
+		{Txid: "aa", OutputIndex: 0, IdentityKey: "alice", Domain: "https://a.example.com", Topic: "tm_meter", CreatedAt: base},
+		{Txid: "bb", OutputIndex: 0, IdentityKey: "bob", Domain: "https://b.example.com", Topic: "tm_meter", CreatedAt: base.Add(time.Minute)},
+		{Txid: "cc", OutputIndex: 1, IdentityKey: "alice", Domain: "https://a.example.com", Topic: "tm_chat", CreatedAt: base.Add(2 * time.Minute), Token: testToken},
+	}))
+
+	slapStorage := memstore.NewSLAPStorage()
+	require.NoError(t, slapStorage.ImportRecords(ctx, []types.SLAPRecord{
+		{Txid: "dd", OutputIndex: 0, IdentityKey: "alice", Domain: "https://a.example.com", Service: "ls_meter", CreatedAt: base},
+		{Txid: "ee", OutputIndex: 2, IdentityKey: "carol", Domain: "https://c.example.com", Service: "ls_chat", CreatedAt: base.Add(time.Minute), Token: testToken},
+	}))
+
+	return NewHandler(shipStorage, slapStorage)
+}
+
+// get performs a request against the handler and returns the recorded response.
+func get(t *testing.T, handler http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&v))
+	return v
+}
+
+func txids[T any](records []T, txid func(T) string) []string {
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = txid(record)
+	}
+	return ids
+}
+
+func shipTxid(r types.SHIPRecord) string { return r.Txid }
+
+func slapTxid(r types.SLAPRecord) string { return r.Txid }
+
+// Test list endpoints
+
+func TestListSHIPRecords(t *testing.T) {
+	handler := createTestHandler(t)
+
+	tests := []struct {
+		name   string
+		target string
+		want   []string
+	}{
+		{"all records newest first", "/ship/records", []string{"cc", "bb", "aa"}},
+		{"ascending", "/ship/records?sortOrder=asc", []string{"aa", "bb", "cc"}},
+		{"domain filter", "/ship/records?domain=https://a.example.com", []string{"cc", "aa"}},
+		{"repeated topic filter", "/ship/records?topic=tm_chat&topic=tm_meter&identityKey=bob", []string{"bb"}},
+		{"hosts for topic", "/ship/topics/tm_meter/hosts", []string{"bb", "aa"}},
+		{"records by identity", "/ship/identities/alice/records?sortOrder=asc", []string{"aa", "cc"}},
+		{"path overrides query", "/ship/topics/tm_chat/hosts?topic=tm_meter", []string{"cc"}},
+		{"no matches", "/ship/topics/tm_unknown/hosts", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(t, handler, tt.target)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assert.Equal(t, tt.want, txids(decode[[]types.SHIPRecord](t, rec), shipTxid))
+		})
+	}
+}
+
+func TestListSLAPRecords(t *testing.T) {
+	handler := createTestHandler(t)
+
+	rec := get(t, handler, "/slap/services/ls_meter/trackers")
+	require.Equal(t, http.StatusOK, rec.Code)
+	records := decode[[]types.SLAPRecord](t, rec)
+	require.Len(t, records, 1)
+	assert.Equal(t, "https://a.example.com", records[0].Domain)
+
+	rec = get(t, handler, "/slap/identities/carol/records")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"ee"}, txids(decode[[]types.SLAPRecord](t, rec), slapTxid))
+
+	rec = get(t, handler, "/slap/records?domain=https://c.example.com&service=ls_meter")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, decode[[]types.SLAPRecord](t, rec))
+}
+
+func TestListRecords_PaginationHeaders(t *testing.T) {
+	handler := createTestHandler(t)
+
+	rec := get(t, handler, "/ship/records?limit=1&skip=1&sortOrder=asc")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"bb"}, txids(decode[[]types.SHIPRecord](t, rec), shipTxid))
+	assert.Equal(t, "1", rec.Header().Get(HeaderLimit))
+	assert.Equal(t, "1", rec.Header().Get(HeaderSkip))
+	assert.Equal(t, "1", rec.Header().Get(HeaderCount))
+	assert.Equal(t, []string{
+		`</ship/records?limit=1&skip=0&sortOrder=asc>; rel="prev"`,
+		`</ship/records?limit=1&skip=2&sortOrder=asc>; rel="next"`,
+	}, rec.Header().Values("Link"))
+
+	// A partial page has no next link
+	rec = get(t, handler, "/ship/records?limit=2&skip=2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(HeaderCount))
+	assert.Equal(t, []string{`</ship/records?limit=2&skip=0>; rel="prev"`}, rec.Header().Values("Link"))
+}
+
+// Test single record endpoints
+
+func TestGetRecord(t *testing.T) {
+	handler := createTestHandler(t)
+
+	rec := get(t, handler, "/ship/records/cc/1")
+	require.Equal(t, http.StatusOK, rec.Code)
+	record := decode[types.SHIPRecord](t, rec)
+	assert.Equal(t, "tm_chat", record.Topic)
+	assert.Equal(t, "alice", record.IdentityKey)
+
+	rec = get(t, handler, "/slap/records/ee/2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ls_chat", decode[types.SLAPRecord](t, rec).Service)
+}
+
+func TestRecords_OmitTokenMaterial(t *testing.T) {
+	handler := createTestHandler(t)
+
+	for _, target := range []string{"/ship/records", "/ship/records/cc/1", "/slap/records", "/slap/records/ee/2"} {
+		t.Run(target, func(t *testing.T) {
+			rec := get(t, handler, target)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.NotContains(t, rec.Body.String(), `"token"`)
+		})
+	}
+}
+
+// Test errors
+
+func TestHandler_Errors(t *testing.T) {
+	handler := createTestHandler(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		code   string
+	}{
+		{"invalid limit", http.MethodGet, "/ship/records?limit=abc", http.StatusBadRequest, CodeInvalidRequest},
+		{"invalid sort order", http.MethodGet, "/slap/records?sortOrder=up", http.StatusBadRequest, CodeInvalidRequest},
+		{"invalid output index", http.MethodGet, "/ship/records/aa/x", http.StatusBadRequest, CodeInvalidRequest},
+		{"missing record", http.MethodGet, "/ship/records/aa/7", http.StatusNotFound, CodeNotFound},
+		{"unknown endpoint", http.MethodGet, "/ship/unknown", http.StatusNotFound, CodeNotFound},
+		{"wrong method", http.MethodPost, "/ship/records", http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			require.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			body := decode[ErrorResponse](t, rec)
+			assert.Equal(t, tt.code, body.Code)
+			assert.NotEmpty(t, body.Message)
+		})
+	}
+}
+
+func TestHandler_NilStorage(t *testing.T) {
+	handler := NewHandler(memstore.NewSHIPStorage(), nil)
+
+	rec := get(t, handler, "/ship/records")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, decode[[]types.SHIPRecord](t, rec))
+
+	for _, target := range []string{"/slap/records", "/slap/records/aa/0", "/slap/services/ls_x/trackers"} {
+		rec = get(t, handler, target)
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+		assert.Equal(t, CodeNotFound, decode[ErrorResponse](t, rec).Code)
+	}
+}
+
+func TestHandler_StorageWithoutOptionalInterfaces(t *testing.T) {
+	// Embedding only the base interfaces hides the optional ones memstore implements
+	handler := NewHandler(
+		struct{ ship.StorageInterface }{memstore.NewSHIPStorage()},
+		struct{ slap.StorageInterface }{memstore.NewSLAPStorage()},
+	)
+
+	for _, target := range []string{"/ship/records", "/ship/records/aa/0", "/slap/records", "/slap/records/aa/0"} {
+		rec := get(t, handler, target)
+		assert.Equal(t, http.StatusNotImplemented, rec.Code, target)
+		assert.Equal(t, CodeNotImplemented, decode[ErrorResponse](t, rec).Code)
+	}
+}
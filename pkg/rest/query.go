@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Page sizes applied to list endpoints.
+const (
+	// DefaultPageSize is the number of records returned when no limit is given
+	DefaultPageSize = 100
+	// MaxPageSize is the largest limit a client may request
+	MaxPageSize = 1000
+)
+
+// Static error variables for err113 compliance
+var (
+	errInvalidLimit     = errors.New("limit must be an integer between 1 and 1000")
+	errInvalidSkip      = errors.New("skip must be a non-negative integer")
+	errInvalidSortOrder = errors.New("sortOrder must be 'asc' or 'desc'")
+	errInvalidIndex     = errors.New("output index must be a non-negative integer")
+)
+
+// Pagination is the page of results selected by the limit, skip and sortOrder query parameters.
+type Pagination struct {
+	// Limit is the maximum number of records returned
+	Limit int
+	// Skip is the number of matching records skipped
+	Skip int
+	// SortOrder orders records by creation time; newest first unless ascending
+	SortOrder types.SortOrder
+}
+
+// ParsePagination reads the limit, skip and sortOrder query parameters, defaulting to the
+// newest DefaultPageSize records.
+func ParsePagination(values url.Values) (Pagination, error) {
+	page := Pagination{Limit: DefaultPageSize, SortOrder: types.SortOrderDesc}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxPageSize {
+			return Pagination{}, fmt.Errorf("%w: got '%s'", errInvalidLimit, raw)
+		}
+		page.Limit = limit
+	}
+
+	if raw := values.Get("skip"); raw != "" {
+		skip, err := strconv.Atoi(raw)
+		if err != nil || skip < 0 {
+			return Pagination{}, fmt.Errorf("%w: got '%s'", errInvalidSkip, raw)
+		}
+		page.Skip = skip
+	}
+
+	if raw := values.Get("sortOrder"); raw != "" {
+		switch order := types.SortOrder(raw); order {
+		case types.SortOrderAsc, types.SortOrderDesc:
+			page.SortOrder = order
+		default:
+			return Pagination{}, fmt.Errorf("%w: got '%s'", errInvalidSortOrder, raw)
+		}
+	}
+
+	return page, nil
+}
+
+// ParseSHIPQuery builds a SHIPQuery from the domain, topic (repeatable), identityKey and
+// pagination query parameters.
+func ParseSHIPQuery(values url.Values) (types.SHIPQuery, error) {
+	page, err := ParsePagination(values)
+	if err != nil {
+		return types.SHIPQuery{}, err
+	}
+
+	query := types.SHIPQuery{
+		Domain:      optionalString(values, "domain"),
+		Topics:      values["topic"],
+		IdentityKey: optionalString(values, "identityKey"),
+	}
+	query.Limit, query.Skip, query.SortOrder = &page.Limit, &page.Skip, &page.SortOrder
+	return query, nil
+}
+
+// ParseSLAPQuery builds a SLAPQuery from the domain, service, identityKey and pagination
+// query parameters.
+func ParseSLAPQuery(values url.Values) (types.SLAPQuery, error) {
+	page, err := ParsePagination(values)
+	if err != nil {
+		return types.SLAPQuery{}, err
+	}
+
+	query := types.SLAPQuery{
+		Domain:      optionalString(values, "domain"),
+		Service:     optionalString(values, "service"),
+		IdentityKey: optionalString(values, "identityKey"),
+	}
+	query.Limit, query.Skip, query.SortOrder = &page.Limit, &page.Skip, &page.SortOrder
+	return query, nil
+}
+
+// optionalString returns the query parameter, or nil if it is absent or empty.
+func optionalString(values url.Values, key string) *string {
+	if v := values.Get(key); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// parseOutputIndex parses the output index path segment of a record URL.
+func parseOutputIndex(raw string) (int, error) {
+	index, err := strconv.Atoi(raw)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("%w: got '%s'", errInvalidIndex, raw)
+	}
+	return index, nil
+}
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+func TestParsePagination(t *testing.T) {
+	page, err := ParsePagination(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, Pagination{Limit: DefaultPageSize, SortOrder: types.SortOrderDesc}, page)
+
+	page, err = ParsePagination(url.Values{"limit": {"5"}, "skip": {"10"}, "sortOrder": {"asc"}})
+	require.NoError(t, err)
+	assert.Equal(t, Pagination{Limit: 5, Skip: 10, SortOrder: types.SortOrderAsc}, page)
+
+	tests := []struct {
+		name   string
+		values url.Values
+		err    error
+	}{
+		{"non-numeric limit", url.Values{"limit": {"ten"}}, errInvalidLimit},
+		{"zero limit", url.Values{"limit": {"0"}}, errInvalidLimit},
+		{"limit above maximum", url.Values{"limit": {"1001"}}, errInvalidLimit},
+		{"negative skip", url.Values{"skip": {"-1"}}, errInvalidSkip},
+		{"unknown sort order", url.Values{"sortOrder": {"newest"}}, errInvalidSortOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePagination(tt.values)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseSHIPQuery(t *testing.T) {
+	query, err := ParseSHIPQuery(url.Values{
+		"domain":      {"https://example.com"},
+		"topic":       {"tm_a", "tm_b"},
+		"identityKey": {"02abc"},
+		"limit":       {"3"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, query.Domain)
+	assert.Equal(t, "https://example.com", *query.Domain)
+	assert.Equal(t, []string{"tm_a", "tm_b"}, query.Topics)
+	require.NotNil(t, query.IdentityKey)
+	assert.Equal(t, "02abc", *query.IdentityKey)
+	assert.Equal(t, 3, *query.Limit)
+	assert.Equal(t, 0, *query.Skip)
+	assert.Nil(t, query.FindAll)
+}
+
+func TestParseSLAPQuery(t *testing.T) {
+	query, err := ParseSLAPQuery(url.Values{"service": {"ls_a"}, "domain": {""}})
+	require.NoError(t, err)
+	require.NotNil(t, query.Service)
+	assert.Equal(t, "ls_a", *query.Service)
+	assert.Nil(t, query.Domain)
+	assert.Nil(t, query.IdentityKey)
+
+	_, err = ParseSLAPQuery(url.Values{"skip": {"x"}})
+	require.ErrorIs(t, err, errInvalidSkip)
+}
@@ -0,0 +1,185 @@
+// Package server assembles a standalone SHIP and SLAP discovery server: an overlay engine with
+// the tm_ship and tm_slap topic managers and ls_ship and ls_slap lookup services, a storage
+// backend, and an HTTP API for submitting transactions and answering lookup questions.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/server/config/loaders"
+	"github.com/bsv-blockchain/go-sdk/transaction/chaintracker"
+)
+
+// Static error variables for err113 compliance
+var (
+	errUnknownBackend      = errors.New("storage backend must be memory or mongo")
+	errMissingMongoURI     = errors.New("storage.mongo_uri is required for the mongo backend")
+	errMissingDatabase     = errors.New("storage.database is required for the mongo backend")
+	errUnknownChainTracker = errors.New("chain_tracker.type must be whatsonchain or none")
+	errMissingAddr         = errors.New("server.addr is required")
+	errInvalidMaxBodyBytes = errors.New("server.max_body_bytes must be positive")
+	errInvalidShutdown     = errors.New("server.shutdown_timeout must be positive")
+	errUnknownNetwork      = errors.New("chain_tracker.network must be main or test")
+)
+
+// Storage backends for StorageConfig.Backend.
+const (
+	// BackendMemory keeps all state in memory; nothing survives a restart
+	BackendMemory = "memory"
+	// BackendMongo stores engine outputs and SHIP and SLAP records in MongoDB
+	BackendMongo = "mongo"
+)
+
+// Chain trackers for ChainTrackerConfig.Type.
+const (
+	// ChainTrackerWhatsOnChain verifies merkle proofs against WhatsOnChain block headers
+	ChainTrackerWhatsOnChain = "whatsonchain"
+	// ChainTrackerNone accepts every merkle proof; use it only for local development
+	ChainTrackerNone = "none"
+)
+
+// EnvPrefix is the prefix of environment variables overriding configuration values, so
+// server.addr is set by DISCOVERY_SERVER_ADDR.
+const EnvPrefix = "DISCOVERY"
+
+// Config configures the discovery server.
+type Config struct {
+	Server       HTTPConfig         `mapstructure:"server"`
+	Storage      StorageConfig      `mapstructure:"storage"`
+	ChainTracker ChainTrackerConfig `mapstructure:"chain_tracker"`
+	Discovery    DiscoveryConfig    `mapstructure:"discovery"`
+	Metrics      MetricsConfig      `mapstructure:"metrics"`
+}
+
+// HTTPConfig configures the HTTP listener.
+type HTTPConfig struct {
+	// Addr is the address to listen on, such as ":8080"
+	Addr string `mapstructure:"addr"`
+	// HostingURL is the public URL of this server, used by the engine when advertising
+	HostingURL string `mapstructure:"hosting_url"`
+	// ReadHeaderTimeout limits how long a client may take to send request headers
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	// ShutdownTimeout limits how long in-flight requests are given to finish on shutdown
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// MaxBodyBytes limits the size of submitted transactions and lookup questions
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+}
+
+// StorageConfig selects and configures the storage backend.
+type StorageConfig struct {
+	// Backend is BackendMemory or BackendMongo
+	Backend string `mapstructure:"backend"`
+	// MongoURI is the MongoDB connection URI for the mongo backend
+	MongoURI string `mapstructure:"mongo_uri"`
+	// Database is the MongoDB database name for the mongo backend
+	Database string `mapstructure:"database"`
+}
+
+// ChainTrackerConfig configures how merkle proofs of submitted transactions are verified.
+type ChainTrackerConfig struct {
+	// Type is ChainTrackerWhatsOnChain or ChainTrackerNone
+	Type string `mapstructure:"type"`
+	// Network is the WhatsOnChain network, "main" or "test"
+	Network string `mapstructure:"network"`
+	// APIKey is an optional WhatsOnChain API key
+	APIKey string `mapstructure:"api_key"`
+}
+
+// DiscoveryConfig configures the SHIP and SLAP components.
+type DiscoveryConfig struct {
+	// KeepTokenMaterial stores the raw token with each record so signatures can be re-verified later
+	KeepTokenMaterial bool `mapstructure:"keep_token_material"`
+}
+
+// MetricsConfig configures the Prometheus metrics endpoint.
+type MetricsConfig struct {
+	// Enabled serves the discovery, Go runtime and process metrics at GET /metrics
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// DefaultConfig returns the configuration used for values not set in a file or the
+// environment: an in-memory backend listening on :8080, verifying against WhatsOnChain mainnet
+// and serving metrics.
+func DefaultConfig() Config {
+	return Config{
+		Server: HTTPConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ShutdownTimeout:   10 * time.Second,
+			MaxBodyBytes:      10 << 20,
+		},
+		Storage: StorageConfig{
+			Backend:  BackendMemory,
+			MongoURI: "mongodb://localhost:27017",
+			Database: "overlay",
+		},
+		ChainTracker: ChainTrackerConfig{
+			Type:    ChainTrackerWhatsOnChain,
+			Network: "main",
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+	}
+}
+
+// LoadConfig loads the configuration from a YAML, JSON or .env file and from DISCOVERY_*
+// environment variables, which take precedence over the file. An empty path reads
+// config.yaml from the working directory if it exists, and uses only defaults and the
+// environment otherwise.
+func LoadConfig(path string) (Config, error) {
+	loader := loaders.NewLoader(DefaultConfig, EnvPrefix)
+	if path != "" {
+		if err := loader.SetConfigFilePath(path); err != nil {
+			return Config{}, fmt.Errorf("invalid config file: %w", err)
+		}
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that the configuration is complete and consistent.
+func (c Config) Validate() error {
+	if c.Server.Addr == "" {
+		return errMissingAddr
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		return fmt.Errorf("%w: %d", errInvalidMaxBodyBytes, c.Server.MaxBodyBytes)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("%w: %s", errInvalidShutdown, c.Server.ShutdownTimeout)
+	}
+
+	switch c.Storage.Backend {
+	case BackendMemory:
+	case BackendMongo:
+		if c.Storage.MongoURI == "" {
+			return errMissingMongoURI
+		}
+		if c.Storage.Database == "" {
+			return errMissingDatabase
+		}
+	default:
+		return fmt.Errorf("%w: %q", errUnknownBackend, c.Storage.Backend)
+	}
+
+	switch c.ChainTracker.Type {
+	case ChainTrackerWhatsOnChain:
+		switch chaintracker.Network(c.ChainTracker.Network) {
+		case chaintracker.MainNet, chaintracker.TestNet:
+		default:
+			return fmt.Errorf("%w: %q", errUnknownNetwork, c.ChainTracker.Network)
+		}
+	case ChainTrackerNone:
+	default:
+		return fmt.Errorf("%w: %q", errUnknownChainTracker, c.ChainTracker.Type)
+	}
+
+	return nil
+}
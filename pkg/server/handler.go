@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+)
+
+// XTopicsHeader is the header listing the topics a submitted transaction is tagged with,
+// as a JSON array of topic names.
+const XTopicsHeader = "X-Topics"
+
+// errorResponse is the JSON body of every error response.
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// Handler returns the HTTP API of the server, compatible with the overlay clients in go-sdk:
+//
+//	POST /submit                      submit a BEEF tagged with X-Topics, returns the STEAK
+//	POST /lookup                      answer a lookup question, returns the lookup answer
+//	GET  /listTopicManagers           metadata of the hosted topic managers
+//	GET  /listLookupServiceProviders  metadata of the hosted lookup services
+//	GET  /health                      liveness check
+//	GET  /ship/..., /slap/...         stored records, served by rest.Handler
+//	GET  /metrics                     Prometheus metrics, when enabled
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /submit", s.handleSubmit)
+	mux.HandleFunc("POST /lookup", s.handleLookup)
+	mux.HandleFunc("GET /listTopicManagers", func(w http.ResponseWriter, _ *http.Request) {
+		s.writeJSON(w, http.StatusOK, s.engine.ListTopicManagers())
+	})
+	mux.HandleFunc("GET /listLookupServiceProviders", func(w http.ResponseWriter, _ *http.Request) {
+		s.writeJSON(w, http.StatusOK, s.engine.ListLookupServiceProviders())
+	})
+	mux.Handle("/ship/", s.records)
+	mux.Handle("/slap/", s.records)
+	if s.registry != nil {
+		mux.Handle("GET /metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}))
+	}
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+// handleSubmit submits a BEEF to the topics named in the X-Topics header.
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var topics []string
+	if err := json.Unmarshal([]byte(r.Header.Get(XTopicsHeader)), &topics); err != nil || len(topics) == 0 {
+		s.writeError(w, http.StatusBadRequest, "The X-Topics header must be a non-empty JSON array of topic names")
+		return
+	}
+
+	beef, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.Server.MaxBodyBytes))
+	if err != nil {
+		s.writeBodyError(w, err)
+		return
+	}
+	if len(beef) == 0 {
+		s.writeError(w, http.StatusBadRequest, "The request body must be a BEEF")
+		return
+	}
+
+	steak, err := s.engine.Submit(r.Context(), overlay.TaggedBEEF{Beef: beef, Topics: topics}, engine.SubmitModeCurrent, nil)
+	switch {
+	case errors.Is(err, engine.ErrUnknownTopic):
+		s.writeError(w, http.StatusBadRequest, "One or more topics are not hosted by this server")
+	case errors.Is(err, engine.ErrInvalidBeef), errors.Is(err, engine.ErrInvalidTransaction):
+		s.writeError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		s.logger.Error("Failed to submit transaction", "topics", topics, shared.LogKeyError, err)
+		s.writeError(w, http.StatusInternalServerError, "The transaction could not be processed")
+	default:
+		s.writeJSON(w, http.StatusOK, steak)
+	}
+}
+
+// handleLookup answers a lookup question.
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	var question lookup.LookupQuestion
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.cfg.Server.MaxBodyBytes)).Decode(&question); err != nil {
+		s.writeBodyError(w, err)
+		return
+	}
+	if question.Service == "" {
+		s.writeError(w, http.StatusBadRequest, "The lookup question must name a service")
+		return
+	}
+
+	answer, err := s.engine.Lookup(r.Context(), &question)
+	switch {
+	case errors.Is(err, engine.ErrUnknownTopic):
+		s.writeError(w, http.StatusNotFound, "The lookup service is not hosted by this server")
+	case shared.IsInvalidQuery(err):
+		s.writeError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		s.logger.Error("Failed to answer lookup question", shared.LogKeyService, question.Service, shared.LogKeyError, err)
+		s.writeError(w, http.StatusInternalServerError, "The lookup question could not be answered")
+	default:
+		s.writeJSON(w, http.StatusOK, answer)
+	}
+}
+
+// writeBodyError reports a request body that could not be read or decoded.
+func (s *Server) writeBodyError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		s.writeError(w, http.StatusRequestEntityTooLarge, "The request body is too large")
+		return
+	}
+	s.writeError(w, http.StatusBadRequest, "The request body could not be read")
+}
+
+// writeError writes a JSON error body with the status code.
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, errorResponse{Message: message})
+}
+
+// writeJSON writes v as a JSON body with the status code.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("Failed to write response", shared.LogKeyError, err)
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/spv"
+	"github.com/bsv-blockchain/go-sdk/transaction/chaintracker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/memstore"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/metrics"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/mongostore"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/rest"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+)
+
+// Server is a standalone discovery server: an overlay engine hosting the SHIP and SLAP topic
+// managers and lookup services over HTTP.
+type Server struct {
+	cfg          Config
+	engine       *engine.Engine
+	shipLookup   *ship.LookupService
+	slapLookup   *slap.LookupService
+	shipManager  *ship.TopicManager
+	slapManager  *slap.TopicManager
+	records      *rest.Handler
+	registry     *prometheus.Registry
+	httpServer   *http.Server
+	closeBackend func(context.Context) error
+	// logger receives the events of the server and its components
+	logger *slog.Logger
+}
+
+// Option customizes a Server at construction.
+type Option func(*Server)
+
+// WithLogger sets the logger receiving the events of the server, its storage backend and its
+// SHIP and SLAP components. The default is slog.Default(); a nil logger discards events.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) { s.logger = shared.Logger(context.Background(), logger) }
+}
+
+// backend holds the storage selected by StorageConfig and a function releasing it.
+type backend struct {
+	engine engine.Storage
+	ship   ship.StorageInterface
+	slap   slap.StorageInterface
+	close  func(context.Context) error
+}
+
+// New builds a server from the configuration, connecting to the storage backend and creating
+// its indexes. The server does not listen until Run is called; call Close to release the
+// backend if Run is never called.
+func New(ctx context.Context, cfg Config, opts ...Option) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The server is an application, so it logs to the default logger unless given another
+	s := &Server{cfg: cfg, logger: slog.Default()}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	b, err := openBackend(ctx, cfg.Storage, s.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		b.ship = metrics.InstrumentSHIPStorage(b.ship, m)
+		b.slap = metrics.InstrumentSLAPStorage(b.slap, m)
+	}
+
+	s.shipLookup = ship.NewLookupService(b.ship)
+	s.slapLookup = slap.NewLookupService(b.slap)
+	s.records = rest.NewHandler(b.ship, b.slap)
+	s.closeBackend = b.close
+	s.shipLookup.SetLogger(s.logger)
+	s.slapLookup.SetLogger(s.logger)
+	s.records.SetLogger(s.logger)
+	s.shipLookup.SetKeepTokenMaterial(cfg.Discovery.KeepTokenMaterial)
+	s.slapLookup.SetKeepTokenMaterial(cfg.Discovery.KeepTokenMaterial)
+	// Answer with output lists carrying each token's BEEF, as overlay lookup resolvers expect
+	s.shipLookup.SetBEEFProvider(shared.NewEngineBEEFProvider(b.engine, ship.Topic))
+	s.slapLookup.SetBEEFProvider(shared.NewEngineBEEFProvider(b.engine, slap.Topic))
+	s.shipManager = ship.NewTopicManager(b.ship, s.shipLookup)
+	s.slapManager = slap.NewTopicManager(b.slap, s.slapLookup)
+	s.shipManager.SetLogger(s.logger)
+	s.slapManager.SetLogger(s.logger)
+	if m != nil {
+		s.registerMetrics(m, b)
+	}
+
+	s.engine = engine.NewEngine(&engine.Config{
+		Managers: map[string]engine.TopicManager{
+			ship.Topic: s.shipManager,
+			slap.Topic: s.slapManager,
+		},
+		LookupServices: map[string]engine.LookupService{
+			ship.Service: s.shipLookup,
+			slap.Service: s.slapLookup,
+		},
+		Storage:      b.engine,
+		ChainTracker: newChainTracker(cfg.ChainTracker, s.logger),
+		HostingURL:   cfg.Server.HostingURL,
+		SyncConfiguration: map[string]engine.SyncConfiguration{
+			ship.Topic: {Type: engine.SyncConfigurationNone},
+			slap.Topic: {Type: engine.SyncConfigurationNone},
+		},
+	})
+
+	s.httpServer = &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+	}
+
+	return s, nil
+}
+
+// registerMetrics reports the SHIP and SLAP components to m and registers it, with the Go
+// runtime and process collectors, on the registry served at /metrics.
+func (s *Server) registerMetrics(m *metrics.Metrics, b *backend) {
+	s.shipManager.SetObserver(m)
+	s.slapManager.SetObserver(m)
+	s.shipLookup.SetObserver(m)
+	s.slapLookup.SetObserver(m)
+	m.WatchSHIPRecords(b.ship)
+	m.WatchSLAPRecords(b.slap)
+	m.SetLogger(s.logger)
+
+	s.registry = prometheus.NewRegistry()
+	s.registry.MustRegister(m, collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+}
+
+// Engine returns the overlay engine hosting the SHIP and SLAP components.
+func (s *Server) Engine() *engine.Engine {
+	return s.engine
+}
+
+// Run serves HTTP until the context is canceled, then stops accepting connections, gives
+// in-flight requests up to the configured shutdown timeout to finish, and closes the server.
+// It returns nil after a clean shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.Info("Discovery server listening", "addr", s.cfg.Server.Addr, "backend", s.cfg.Storage.Backend)
+		serveErr <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		closeErr := s.Close(context.Background())
+		return errors.Join(fmt.Errorf("server stopped: %w", err), closeErr)
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down discovery server", "timeout", s.cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	var shutdownErr error
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		shutdownErr = fmt.Errorf("failed to shut down HTTP server: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		shutdownErr = errors.Join(shutdownErr, err)
+	}
+	return errors.Join(shutdownErr, s.Close(shutdownCtx))
+}
+
+// Close closes the topic managers and releases the storage backend.
+func (s *Server) Close(ctx context.Context) error {
+	return errors.Join(
+		s.shipManager.Close(ctx),
+		s.slapManager.Close(ctx),
+		s.closeBackend(ctx),
+	)
+}
+
+// openBackend connects to the configured storage backend, whose storages log to logger.
+func openBackend(ctx context.Context, cfg StorageConfig, logger *slog.Logger) (*backend, error) {
+	if cfg.Backend == BackendMemory {
+		return &backend{
+			engine: memstore.NewEngineStorage(),
+			ship:   memstore.NewSHIPStorage(),
+			slap:   memstore.NewSLAPStorage(),
+			close:  func(context.Context) error { return nil },
+		}, nil
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	disconnect := func(ctx context.Context) error {
+		if err := client.Disconnect(ctx); err != nil {
+			return fmt.Errorf("failed to disconnect from MongoDB: %w", err)
+		}
+		return nil
+	}
+
+	db := client.Database(cfg.Database)
+	engineStorage := mongostore.NewEngineStorage(db)
+	shipStorage := ship.NewStorage(db)
+	slapStorage := slap.NewStorage(db)
+	engineStorage.SetLogger(logger)
+	shipStorage.SetLogger(logger)
+	slapStorage.SetLogger(logger)
+
+	indexErr := errors.Join(
+		engineStorage.EnsureIndexes(ctx),
+		shipStorage.EnsureIndexes(ctx),
+		slapStorage.EnsureIndexes(ctx),
+	)
+	if indexErr != nil {
+		return nil, errors.Join(indexErr, disconnect(ctx))
+	}
+
+	return &backend{
+		engine: engineStorage,
+		ship:   shipStorage,
+		slap:   slapStorage,
+		close:  disconnect,
+	}, nil
+}
+
+// newChainTracker returns the chain tracker used to verify merkle proofs.
+func newChainTracker(cfg ChainTrackerConfig, logger *slog.Logger) chaintracker.ChainTracker {
+	if cfg.Type == ChainTrackerNone {
+		logger.Warn("Merkle proofs are not verified; use chain_tracker.type=none only for local development")
+		return &spv.GullibleHeadersClient{}
+	}
+	return chaintracker.NewWhatsOnChain(chaintracker.Network(cfg.Network), cfg.APIKey)
+}
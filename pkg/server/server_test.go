@@ -0,0 +1,289 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/internal/tokentest"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// newTestServer returns an in-memory server that accepts unproven transactions.
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Server.Addr = "127.0.0.1:0"
+	cfg.ChainTracker.Type = ChainTrackerNone
+
+	s, err := New(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+	return s
+}
+
+func TestNew_WithLogger(t *testing.T) {
+	var logs bytes.Buffer
+	cfg := DefaultConfig()
+	cfg.ChainTracker.Type = ChainTrackerNone
+
+	s, err := New(context.Background(), cfg, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+
+	assert.Contains(t, logs.String(), "Merkle proofs are not verified")
+}
+
+func TestLoadConfig_FileAndEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  addr: ":9090"
+  shutdown_timeout: 3s
+storage:
+  backend: mongo
+  database: from-file
+chain_tracker:
+  type: none
+`), 0o600))
+	t.Setenv("DISCOVERY_STORAGE_DATABASE", "from-env")
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, ":9090", cfg.Server.Addr)
+	assert.Equal(t, 3*time.Second, cfg.Server.ShutdownTimeout)
+	assert.Equal(t, BackendMongo, cfg.Storage.Backend)
+	assert.Equal(t, "from-env", cfg.Storage.Database)
+	assert.Equal(t, "mongodb://localhost:27017", cfg.Storage.MongoURI)
+	assert.Equal(t, ChainTrackerNone, cfg.ChainTracker.Type)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, DefaultConfig().Validate())
+
+	cfg := DefaultConfig()
+	cfg.Storage.Backend = "sqlite"
+	require.ErrorIs(t, cfg.Validate(), errUnknownBackend)
+
+	cfg = DefaultConfig()
+	cfg.Storage.Backend = BackendMongo
+	cfg.Storage.Database = ""
+	require.ErrorIs(t, cfg.Validate(), errMissingDatabase)
+
+	cfg = DefaultConfig()
+	cfg.ChainTracker.Type = "blockchair"
+	require.ErrorIs(t, cfg.Validate(), errUnknownChainTracker)
+
+	cfg = DefaultConfig()
+	cfg.ChainTracker.Network = "regtest"
+	require.ErrorIs(t, cfg.Validate(), errUnknownNetwork)
+
+	cfg = DefaultConfig()
+	cfg.Server.Addr = ""
+	require.ErrorIs(t, cfg.Validate(), errMissingAddr)
+
+	cfg = DefaultConfig()
+	cfg.Server.MaxBodyBytes = 0
+	require.ErrorIs(t, cfg.Validate(), errInvalidMaxBodyBytes)
+
+	cfg = DefaultConfig()
+	cfg.Server.ShutdownTimeout = -time.Second
+	require.ErrorIs(t, cfg.Validate(), errInvalidShutdown)
+}
+
+func TestHandler_SubmitAndLookup(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL+"/submit",
+		bytes.NewReader(tokentest.SignedAtomicBEEF(t, overlay.ProtocolSHIP, tokentest.Advertisement{Domain: "https://overlay.example.com", Name: "tm_meter"})))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(XTopicsHeader, `["tm_ship"]`)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var steak overlay.Steak
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&steak))
+	assert.Equal(t, []uint32{0}, steak[ship.Topic].OutputsToAdmit)
+
+	question, err := json.Marshal(lookup.LookupQuestion{Service: ship.Service, Query: json.RawMessage(`{"findAll":true}`)})
+	require.NoError(t, err)
+	resp, err = http.Post(ts.URL+"/lookup", "application/json", bytes.NewReader(question)) //nolint:noctx // test request
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var answer lookup.LookupAnswer
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&answer))
+	assert.Equal(t, lookup.AnswerTypeOutputList, answer.Type)
+	assert.Len(t, answer.Outputs, 1)
+}
+
+func TestHandler_Errors(t *testing.T) {
+	s := newTestServer(t)
+	handler := s.Handler()
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{
+			name:   "submit without topics",
+			req:    httptest.NewRequest(http.MethodPost, "/submit", bytes.NewReader([]byte{1})),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "lookup without service",
+			req:    httptest.NewRequest(http.MethodPost, "/lookup", bytes.NewReader([]byte(`{"query":{}}`))),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "lookup of unknown service",
+			req:    httptest.NewRequest(http.MethodPost, "/lookup", bytes.NewReader([]byte(`{"service":"ls_unknown","query":{}}`))),
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "lookup with invalid pagination",
+			req:    httptest.NewRequest(http.MethodPost, "/lookup", bytes.NewReader([]byte(`{"service":"ls_ship","query":{"sortOrder":"sideways"}}`))),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "lookup with malformed domain",
+			req:    httptest.NewRequest(http.MethodPost, "/lookup", bytes.NewReader([]byte(`{"service":"ls_slap","query":{"domain":42}}`))),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "lookup with unsupported string query",
+			req:    httptest.NewRequest(http.MethodPost, "/lookup", bytes.NewReader([]byte(`{"service":"ls_ship","query":"everything"}`))),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "wrong method",
+			req:    httptest.NewRequest(http.MethodGet, "/submit", nil),
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.req)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusMethodNotAllowed {
+				var body errorResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+				assert.NotEmpty(t, body.Message)
+			}
+		})
+	}
+}
+
+func TestHandler_ListTopicManagers(t *testing.T) {
+	s := newTestServer(t)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/listTopicManagers", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var managers map[string]json.RawMessage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&managers))
+	assert.Contains(t, managers, ship.Topic)
+	assert.Contains(t, managers, "tm_slap")
+}
+
+func TestServer_RunStopsOnCancel(t *testing.T) {
+	s := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+}
+
+func TestHandler_ServesRecords(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL+"/submit",
+		bytes.NewReader(tokentest.SignedAtomicBEEF(t, overlay.ProtocolSHIP, tokentest.Advertisement{Domain: "https://overlay.example.com", Name: "tm_meter"})))
+	require.NoError(t, err)
+	req.Header.Set(XTopicsHeader, `["tm_ship"]`)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/ship/topics/tm_meter/hosts") //nolint:noctx // test request
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var records []types.SHIPRecord
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "https://overlay.example.com", records[0].Domain)
+}
+
+func TestHandler_ServesMetrics(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL+"/submit",
+		bytes.NewReader(tokentest.SignedAtomicBEEF(t, overlay.ProtocolSHIP, tokentest.Advertisement{Domain: "https://overlay.example.com", Name: "tm_meter"})))
+	require.NoError(t, err)
+	req.Header.Set(XTopicsHeader, `["tm_ship"]`)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/metrics") //nolint:noctx // test request
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `discovery_outputs_admitted_total{protocol="ship"} 1`)
+	assert.Contains(t, string(body), `discovery_ship_records{topic="tm_meter"} 1`)
+	assert.Contains(t, string(body), `discovery_storage_operation_duration_seconds_count{operation="store",protocol="ship"} 1`)
+}
+
+func TestHandler_MetricsDisabled(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ChainTracker.Type = ChainTrackerNone
+	cfg.Metrics.Enabled = false
+	s, err := New(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
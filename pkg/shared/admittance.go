@@ -2,14 +2,20 @@ package shared
 
 import (
 	"context"
-	"log"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 
 	"github.com/bsv-blockchain/go-sdk/chainhash"
 	"github.com/bsv-blockchain/go-sdk/overlay"
 	"github.com/bsv-blockchain/go-sdk/transaction"
 	"github.com/bsv-blockchain/go-sdk/transaction/template/pushdrop"
+	"github.com/bsv-blockchain/go-sdk/wallet"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
@@ -20,106 +26,293 @@ type AdmittanceConfig struct {
 	Identifier string
 	// TopicPrefix is the required prefix for the topic/service field (e.g. "tm_" or "ls_").
 	TopicPrefix string
-	// EmojiAdmit is the emoji used for admit log messages.
+	// EmojiAdmit was the emoji used for admit log messages.
+	//
+	// Deprecated: admittance outcomes are logged as structured events to Logger; it is ignored.
 	EmojiAdmit string
-	// EmojiConsume is the emoji used for consume log messages.
+	// EmojiConsume was the emoji used for consume log messages.
+	//
+	// Deprecated: admittance outcomes are logged as structured events to Logger; it is ignored.
 	EmojiConsume string
-	// EmojiNone is the emoji used when nothing was admitted/consumed.
+	// EmojiNone was the emoji used when nothing was admitted/consumed.
+	//
+	// Deprecated: admittance outcomes are logged as structured events to Logger; it is ignored.
 	EmojiNone string
+	// Policy is evaluated for every output that passes the protocol checks (optional).
+	// Outputs it rejects are not admitted.
+	Policy AdmittancePolicy
+	// Verifier checks token signature linkage (optional). When nil, a verifier resolving the
+	// protocol from the token is created for each call.
+	Verifier *utils.TokenVerifier
+	// SignatureCache caches signature verification results by locking script (optional)
+	SignatureCache *SignatureCache
+	// VerifyWorkers is the maximum number of signatures verified concurrently for one
+	// transaction. Zero or one verifies sequentially.
+	VerifyWorkers int
+	// URISchemes decides which advertised URIs are acceptable (optional). When nil, the
+	// utils.DefaultURISchemeRegistry is used.
+	URISchemes *utils.URISchemeRegistry
+	// Naming decides which topic or service names are acceptable (optional). When nil, the
+	// utils.DefaultNamingPolicy is used.
+	Naming *utils.NamingPolicy
+	// TracerProvider provides the tracer for admittance spans (optional). When nil, spans
+	// join the provider of the span in the context, as described by Tracer.
+	TracerProvider trace.TracerProvider
+	// Logger receives admittance events (optional). When nil, the logger carried by the
+	// context is used, as described by Logger, and events are otherwise discarded.
+	Logger *slog.Logger
 }
 
 // IdentifyAdmissibleOutputs is the shared implementation for SHIP and SLAP topic managers.
 // It parses the BEEF transaction, validates PushDrop tokens, and returns admittance instructions.
 func IdentifyAdmissibleOutputs(ctx context.Context, beef *transaction.Beef, txid *chainhash.Hash, previousCoins []uint32, cfg AdmittanceConfig) (overlay.AdmittanceInstructions, error) {
-	outputsToAdmit := []uint32{}
+	report, err := IdentifyAdmissibleOutputsWithReport(ctx, beef, txid, previousCoins, cfg)
+	return report.Instructions, err
+}
+
+// IdentifyAdmissibleOutputsWithReport behaves like IdentifyAdmissibleOutputs but also reports
+// why each output that was not admitted was rejected.
+func IdentifyAdmissibleOutputsWithReport(ctx context.Context, beef *transaction.Beef, txid *chainhash.Hash, previousCoins []uint32, cfg AdmittanceConfig) (AdmittanceReport, error) {
+	ctx, span := Tracer(ctx, cfg.TracerProvider).Start(ctx, "IdentifyAdmissibleOutputs", trace.WithAttributes(
+		AttrProtocol.String(cfg.Identifier),
+		AttrTxid.String(txid.String()),
+	))
+	defer span.End()
+	cfg.Logger = Logger(ctx, cfg.Logger)
+
+	report := AdmittanceReport{
+		Txid: txid.String(),
+		Instructions: overlay.AdmittanceInstructions{
+			OutputsToAdmit: []uint32{},
+			CoinsToRetain:  []uint32{},
+		},
+	}
 
 	// Find the target transaction within the BEEF structure
 	parsedTransaction := beef.FindTransactionByHash(txid)
 	if parsedTransaction == nil {
 		if len(previousCoins) == 0 {
-			log.Printf("%s Error identifying admissible outputs: transaction %s not found in BEEF", cfg.EmojiNone, txid)
+			cfg.Logger.Warn("Transaction not found in BEEF", LogKeyProtocol, cfg.Identifier, LogKeyTxid, report.Txid)
 		}
-		return overlay.AdmittanceInstructions{
-			OutputsToAdmit: outputsToAdmit,
-			CoinsToRetain:  []uint32{},
-		}, nil
+		span.SetAttributes(attribute.Bool("discovery.transaction_found", false))
+		return report, nil
 	}
+	report.TransactionFound = true
 
-	// Check each output for token validity
+	// Check each output's token structure, then its signature, then the admittance policy
+	checks := make([]*outputCheck, 0, len(parsedTransaction.Outputs))
 	for i, output := range parsedTransaction.Outputs {
-		if idx, ok := validateOutput(ctx, i, output, parsedTransaction, cfg); ok {
-			outputsToAdmit = append(outputsToAdmit, idx)
+		if i < 0 || i > 0xFFFFFFFF {
+			break
 		}
+		checks = append(checks, checkOutputStructure(uint32(i), output, report.Txid, cfg))
 	}
 
-	logAdmittanceResults(outputsToAdmit, previousCoins, cfg)
+	verifySignatures(ctx, checks, report.Txid, cfg)
+
+	for _, check := range checks {
+		if check.rejection == nil {
+			check.rejection = checkOutputPolicy(ctx, check, report.Txid, cfg)
+		}
+		if check.rejection != nil {
+			report.Rejections = append(report.Rejections, *check.rejection)
+			continue
+		}
+		report.Instructions.OutputsToAdmit = append(report.Instructions.OutputsToAdmit, check.outputIndex)
+	}
 
-	return overlay.AdmittanceInstructions{
-		OutputsToAdmit: outputsToAdmit,
-		CoinsToRetain:  []uint32{},
-	}, nil
+	cfg.Logger.Info("Admittance checked",
+		LogKeyProtocol, cfg.Identifier,
+		LogKeyTxid, report.Txid,
+		"admitted", len(report.Instructions.OutputsToAdmit),
+		"rejected", len(report.Rejections),
+		"consumed", len(previousCoins),
+	)
+	span.SetAttributes(
+		AttrResultCount.Int(len(report.Instructions.OutputsToAdmit)),
+		AttrRejectedCount.Int(len(report.Rejections)),
+	)
+
+	return report, nil
+}
+
+// outputCheck tracks a single output through the admittance checks.
+type outputCheck struct {
+	outputIndex      uint32
+	rejection        *OutputRejection
+	fields           [][]byte
+	lockingPublicKey string
+	scriptHash       [sha256.Size]byte
+	domain           string
+	topic            string
+}
+
+// rejectOutput logs and returns the rejection of an output.
+func rejectOutput(outputIndex uint32, txid string, cfg AdmittanceConfig, reason RejectionReason, detail string) *OutputRejection {
+	cfg.Logger.Debug("Output not admitted",
+		LogKeyProtocol, cfg.Identifier,
+		LogKeyTxid, txid,
+		LogKeyOutputIndex, outputIndex,
+		LogKeyReason, reason,
+		"detail", detail,
+	)
+	return &OutputRejection{OutputIndex: outputIndex, Reason: reason, Detail: detail}
 }
 
-// validateOutput checks whether a single transaction output contains a valid PushDrop token
-// matching the protocol configuration. Returns the output index and true if valid.
-func validateOutput(ctx context.Context, i int, output *transaction.TransactionOutput, parsedTransaction *transaction.Transaction, cfg AdmittanceConfig) (uint32, bool) {
+// checkOutputStructure checks whether a single transaction output contains a well-formed
+// PushDrop token matching the protocol configuration. The returned check carries a rejection
+// if the output is not admissible; signature and policy checks are made separately.
+func checkOutputStructure(outputIndex uint32, output *transaction.TransactionOutput, txid string, cfg AdmittanceConfig) *outputCheck {
+	check := &outputCheck{outputIndex: outputIndex}
+	reject := func(reason RejectionReason, detail string) *outputCheck {
+		check.rejection = rejectOutput(outputIndex, txid, cfg, reason, detail)
+		return check
+	}
+
 	result := pushdrop.Decode(output.LockingScript)
-	if result == nil || len(result.Fields) != 5 {
-		return 0, false
+	if result == nil {
+		return reject(RejectionDecodeError, "locking script is not a PushDrop token")
+	}
+	if len(result.Fields) != 5 {
+		return reject(RejectionFieldCount, fmt.Sprintf("expected 5 fields, got %d", len(result.Fields)))
 	}
 
-	if utils.UTFBytesToString(result.Fields[0]) != cfg.Identifier {
-		return 0, false
+	if identifier := utils.UTFBytesToString(result.Fields[0]); identifier != cfg.Identifier {
+		return reject(RejectionWrongIdentifier, fmt.Sprintf("expected %q, got %q", cfg.Identifier, identifier))
 	}
 
-	if !utils.IsAdvertisableURI(utils.UTFBytesToString(result.Fields[2])) {
-		return 0, false
+	check.domain = utils.UTFBytesToString(result.Fields[2])
+	uriSchemes := cfg.URISchemes
+	if uriSchemes == nil {
+		uriSchemes = utils.DefaultURISchemeRegistry()
+	}
+	if _, err := uriSchemes.Parse(check.domain); err != nil {
+		return reject(RejectionInvalidURI, fmt.Sprintf("%q is not an advertisable URI: %v", check.domain, err))
 	}
 
-	topic := utils.UTFBytesToString(result.Fields[3])
-	if !utils.IsValidTopicOrServiceName(topic) || !strings.HasPrefix(topic, cfg.TopicPrefix) {
-		return 0, false
+	check.topic = utils.UTFBytesToString(result.Fields[3])
+	naming := cfg.Naming
+	if naming == nil {
+		naming = utils.DefaultNamingPolicy()
 	}
+	if err := naming.Validate(check.topic); err != nil {
+		return reject(RejectionInvalidTopic, fmt.Sprintf("%q is not a valid name: %v", check.topic, err))
+	}
+	if !strings.HasPrefix(check.topic, cfg.TopicPrefix) {
+		return reject(RejectionInvalidTopic, fmt.Sprintf("%q does not have prefix %q", check.topic, cfg.TopicPrefix))
+	}
+
+	check.fields = result.Fields
+	check.lockingPublicKey = result.LockingPublicKey.ToDERHex()
+	check.scriptHash = ScriptHash(*output.LockingScript)
+
+	return check
+}
 
-	lockingPublicKey := result.LockingPublicKey.ToDERHex()
-	tokenFields := make(utils.TokenFields, len(result.Fields))
-	copy(tokenFields, result.Fields)
+// verifySignatures checks the signature linkage of every structurally valid output, answering
+// from the signature cache where possible and verifying the rest on up to cfg.VerifyWorkers
+// goroutines. Outputs whose signatures fail are given a rejection.
+func verifySignatures(ctx context.Context, checks []*outputCheck, txid string, cfg AdmittanceConfig) {
+	ctx, span := Tracer(ctx, nil).Start(ctx, "VerifySignatures", trace.WithAttributes(AttrTxid.String(txid)))
+	defer span.End()
 
-	valid, sigErr := utils.IsTokenSignatureCorrectlyLinked(ctx, lockingPublicKey, tokenFields)
-	if sigErr != nil || !valid {
-		if sigErr == nil {
-			slog.Info("Invalid token signature linkage", "outputIndex", i, "txid", parsedTransaction.TxID())
+	verifier := cfg.Verifier
+	if verifier == nil {
+		verifier = newAdvertisementVerifier()
+	}
+
+	pending := make([]*outputCheck, 0, len(checks))
+	results := make(map[*outputCheck]signatureResult, len(checks))
+	for _, check := range checks {
+		if check.rejection != nil {
+			continue
+		}
+		if cfg.SignatureCache != nil {
+			if result, ok := cfg.SignatureCache.get(check.scriptHash); ok {
+				results[check] = result
+				continue
+			}
 		}
-		return 0, false
+		pending = append(pending, check)
 	}
+	span.SetAttributes(
+		attribute.Int("discovery.cached_signatures", len(results)),
+		attribute.Int("discovery.verified_signatures", len(pending)),
+	)
 
-	if i < 0 || i > 0xFFFFFFFF {
-		return 0, false
+	verified := make([]signatureResult, len(pending))
+	verify := func(i int) {
+		tokenFields := make(utils.TokenFields, len(pending[i].fields))
+		copy(tokenFields, pending[i].fields)
+		valid, err := verifier.Verify(ctx, pending[i].lockingPublicKey, tokenFields)
+		verified[i] = signatureResult{valid: valid, err: err}
 	}
-	return uint32(i), true
-}
 
-// logAdmittanceResults logs the outcome of the admittance check.
-func logAdmittanceResults(outputsToAdmit, previousCoins []uint32, cfg AdmittanceConfig) {
-	if len(outputsToAdmit) > 0 {
-		suffix := pluralSuffix(len(outputsToAdmit))
-		log.Printf("%s Admitted %d %s output%s!", cfg.EmojiAdmit, len(outputsToAdmit), cfg.Identifier, suffix)
+	if cfg.VerifyWorkers > 1 && len(pending) > 1 {
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, cfg.VerifyWorkers)
+		for i := range pending {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				verify(i)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range pending {
+			verify(i)
+		}
 	}
 
-	if len(previousCoins) > 0 {
-		suffix := pluralSuffix(len(previousCoins))
-		log.Printf("%s Consumed %d previous %s coin%s!", cfg.EmojiConsume, len(previousCoins), cfg.Identifier, suffix)
+	for i, check := range pending {
+		results[check] = verified[i]
+		// Cancellation is not a property of the token, so it must not be cached
+		if cfg.SignatureCache != nil && ctx.Err() == nil {
+			cfg.SignatureCache.add(check.scriptHash, verified[i])
+		}
 	}
 
-	if len(outputsToAdmit) == 0 && len(previousCoins) == 0 {
-		log.Printf("%s No %s outputs admitted and no previous %s coins consumed.", cfg.EmojiNone, cfg.Identifier, cfg.Identifier)
+	for check, result := range results {
+		if result.err != nil {
+			check.rejection = rejectOutput(check.outputIndex, txid, cfg, RejectionInvalidSignature, result.err.Error())
+		} else if !result.valid {
+			check.rejection = rejectOutput(check.outputIndex, txid, cfg, RejectionInvalidSignature, "signature is not linked to the identity key")
+		}
 	}
 }
 
-// pluralSuffix returns "s" when count != 1, empty string otherwise.
-func pluralSuffix(count int) string {
-	if count == 1 {
-		return ""
+// checkOutputPolicy evaluates the configured admittance policy for an output whose token and
+// signature are valid. Returns nil if the output is admissible.
+func checkOutputPolicy(ctx context.Context, check *outputCheck, txid string, cfg AdmittanceConfig) *OutputRejection {
+	if cfg.Policy == nil {
+		return nil
+	}
+
+	candidate := AdmittanceCandidate{
+		Protocol:       cfg.Identifier,
+		Txid:           txid,
+		OutputIndex:    check.outputIndex,
+		IdentityKey:    hex.EncodeToString(check.fields[1]),
+		Domain:         check.domain,
+		TopicOrService: check.topic,
 	}
-	return "s"
+	if err := cfg.Policy.Admit(ctx, candidate); err != nil {
+		return rejectOutput(check.outputIndex, txid, cfg, RejectionPolicy, err.Error())
+	}
+
+	return nil
+}
+
+// newAdvertisementVerifier returns a verifier for SHIP and SLAP tokens that resolves the
+// protocol ID from the token's first field, as utils.IsTokenSignatureCorrectlyLinked does.
+func newAdvertisementVerifier() *utils.TokenVerifier {
+	verifier, _ := utils.NewTokenVerifier(utils.TokenVerifierConfig{
+		SecurityLevel:    wallet.SecurityLevelEveryAppAndCounterparty,
+		KeyID:            "1",
+		IdentityKeyField: 1,
+	})
+	return verifier
 }
@@ -0,0 +1,209 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Common error variables for the built-in admittance policies.
+var (
+	ErrIdentityKeyNotAllowed = errors.New("identity key is not allowed")
+	ErrDomainNotAllowed      = errors.New("domain is not allowed")
+	ErrTopicNotAllowed       = errors.New("topic or service is not allowed")
+	ErrRateLimitExceeded     = errors.New("admittance rate limit exceeded for identity key")
+	ErrInvalidRateLimit      = errors.New("rate limit and window must be positive")
+)
+
+// AdmittanceCandidate describes a token output that passed the protocol checks
+// (field count, identifier, URI, topic prefix and signature) and is being evaluated
+// by the admittance policy.
+type AdmittanceCandidate struct {
+	// Protocol is the protocol identifier (e.g. "SHIP" or "SLAP")
+	Protocol string
+	// Txid is the transaction ID containing the output
+	Txid string
+	// OutputIndex is the index of the output within the transaction
+	OutputIndex uint32
+	// IdentityKey is the hex-encoded identity key of the advertiser
+	IdentityKey string
+	// Domain is the advertised domain
+	Domain string
+	// TopicOrService is the advertised topic (SHIP) or service (SLAP)
+	TopicOrService string
+}
+
+// AdmittancePolicy decides whether a candidate output is admitted.
+// Admit returns nil to admit the output, or an error describing why it was rejected.
+// Implementations must be safe for concurrent use.
+type AdmittancePolicy interface {
+	Admit(ctx context.Context, candidate AdmittanceCandidate) error
+}
+
+// AdmittancePolicyFunc adapts an ordinary function to the AdmittancePolicy interface.
+type AdmittancePolicyFunc func(ctx context.Context, candidate AdmittanceCandidate) error
+
+// Admit calls f(ctx, candidate).
+func (f AdmittancePolicyFunc) Admit(ctx context.Context, candidate AdmittanceCandidate) error {
+	return f(ctx, candidate)
+}
+
+// ChainAdmittancePolicies returns a policy that admits a candidate only if every policy
+// admits it. Policies are evaluated in order and evaluation stops at the first rejection,
+// so stateful policies such as rate limits should be placed last.
+func ChainAdmittancePolicies(policies ...AdmittancePolicy) AdmittancePolicy {
+	return AdmittancePolicyFunc(func(ctx context.Context, candidate AdmittanceCandidate) error {
+		for _, policy := range policies {
+			if err := policy.Admit(ctx, candidate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListPolicy admits candidates by comparing one of their fields against allow and deny lists.
+// A value on the deny list is always rejected. When the allow list is non-empty, only values
+// on it are admitted; an empty allow list admits every value that is not denied.
+type ListPolicy struct {
+	allow   map[string]struct{}
+	deny    map[string]struct{}
+	field   func(AdmittanceCandidate) string
+	errType error
+}
+
+// NewIdentityKeyPolicy creates a ListPolicy over the advertiser's identity key.
+func NewIdentityKeyPolicy(allow, deny []string) *ListPolicy {
+	return newListPolicy(allow, deny, func(c AdmittanceCandidate) string { return c.IdentityKey }, ErrIdentityKeyNotAllowed)
+}
+
+// NewDomainPolicy creates a ListPolicy over the advertised domain.
+func NewDomainPolicy(allow, deny []string) *ListPolicy {
+	return newListPolicy(allow, deny, func(c AdmittanceCandidate) string { return c.Domain }, ErrDomainNotAllowed)
+}
+
+// NewTopicPolicy creates a ListPolicy over the advertised topic (SHIP) or service (SLAP).
+func NewTopicPolicy(allow, deny []string) *ListPolicy {
+	return newListPolicy(allow, deny, func(c AdmittanceCandidate) string { return c.TopicOrService }, ErrTopicNotAllowed)
+}
+
+// newListPolicy builds a ListPolicy from the given lists and field accessor.
+func newListPolicy(allow, deny []string, field func(AdmittanceCandidate) string, errType error) *ListPolicy {
+	p := &ListPolicy{
+		allow:   make(map[string]struct{}, len(allow)),
+		deny:    make(map[string]struct{}, len(deny)),
+		field:   field,
+		errType: errType,
+	}
+	for _, value := range allow {
+		p.allow[value] = struct{}{}
+	}
+	for _, value := range deny {
+		p.deny[value] = struct{}{}
+	}
+	return p
+}
+
+// Admit implements AdmittancePolicy.
+func (p *ListPolicy) Admit(_ context.Context, candidate AdmittanceCandidate) error {
+	value := p.field(candidate)
+	if _, denied := p.deny[value]; denied {
+		return fmt.Errorf("%w: %s is denied", p.errType, value)
+	}
+	if len(p.allow) > 0 {
+		if _, allowed := p.allow[value]; !allowed {
+			return fmt.Errorf("%w: %s is not on the allow list", p.errType, value)
+		}
+	}
+	return nil
+}
+
+// RateLimitPolicy limits how many outputs each identity key may have admitted within a
+// sliding time window. Every admitted output counts once towards its identity key's limit:
+// resubmitting an output that was already admitted within the window is admitted again
+// without counting a second time. Outputs checked by Reindex are neither limited nor counted.
+type RateLimitPolicy struct {
+	limit      int
+	window     time.Duration
+	clock      Clock
+	admissions map[string][]admission
+	lastSweep  time.Time
+	mutex      sync.Mutex
+}
+
+// rateLimitBypassKey is the context key set by withoutRateLimit.
+type rateLimitBypassKey struct{}
+
+// withoutRateLimit returns a copy of ctx under which RateLimitPolicy admits every candidate
+// without counting it, for outputs that are being restored rather than newly submitted.
+func withoutRateLimit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitBypassKey{}, true)
+}
+
+// admission is an output admitted by a RateLimitPolicy and when it was admitted.
+type admission struct {
+	outpoint string
+	at       time.Time
+}
+
+// NewRateLimitPolicy creates a policy admitting at most limit outputs per identity key
+// within any window-long period, as measured by clock (the system clock when nil).
+// It returns ErrInvalidRateLimit unless both limit and window are positive.
+func NewRateLimitPolicy(limit int, window time.Duration, clock Clock) (*RateLimitPolicy, error) {
+	if limit <= 0 || window <= 0 {
+		return nil, fmt.Errorf("%w: limit %d, window %s", ErrInvalidRateLimit, limit, window)
+	}
+	return &RateLimitPolicy{
+		limit:      limit,
+		window:     window,
+		clock:      clock,
+		admissions: make(map[string][]admission),
+	}, nil
+}
+
+// Admit implements AdmittancePolicy.
+func (p *RateLimitPolicy) Admit(ctx context.Context, candidate AdmittanceCandidate) error {
+	if bypass, _ := ctx.Value(rateLimitBypassKey{}).(bool); bypass {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := Now(p.clock)
+	cutoff := now.Add(-p.window)
+
+	// Periodically forget identity keys with no admissions left in the window
+	if now.Sub(p.lastSweep) > p.window {
+		for key, admissions := range p.admissions {
+			if len(admissions) == 0 || !admissions[len(admissions)-1].at.After(cutoff) {
+				delete(p.admissions, key)
+			}
+		}
+		p.lastSweep = now
+	}
+
+	recent := p.admissions[candidate.IdentityKey]
+	firstRecent := 0
+	for firstRecent < len(recent) && !recent[firstRecent].at.After(cutoff) {
+		firstRecent++
+	}
+	recent = recent[firstRecent:]
+	p.admissions[candidate.IdentityKey] = recent
+
+	outpoint := fmt.Sprintf("%s.%d", candidate.Txid, candidate.OutputIndex)
+	for _, previous := range recent {
+		if previous.outpoint == outpoint {
+			return nil
+		}
+	}
+
+	if len(recent) >= p.limit {
+		return fmt.Errorf("%w: %d outputs admitted in the last %s", ErrRateLimitExceeded, len(recent), p.window)
+	}
+
+	p.admissions[candidate.IdentityKey] = append(recent, admission{outpoint: outpoint, at: now})
+	return nil
+}
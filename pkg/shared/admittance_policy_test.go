@@ -0,0 +1,43 @@
+package shared
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRateLimitPolicy_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		limit  int
+		window time.Duration
+	}{{0, time.Hour}, {-1, time.Hour}, {1, 0}, {1, -time.Second}} {
+		_, err := NewRateLimitPolicy(tc.limit, tc.window, nil)
+		require.ErrorIs(t, err, ErrInvalidRateLimit)
+	}
+}
+
+func TestRateLimitPolicy_SlidingWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy, err := NewRateLimitPolicy(2, time.Minute, ClockFunc(func() time.Time { return now }))
+	require.NoError(t, err)
+	ctx := context.Background()
+	candidate := func(identityKey, txid string, outputIndex uint32) AdmittanceCandidate {
+		return AdmittanceCandidate{IdentityKey: identityKey, Txid: txid, OutputIndex: outputIndex}
+	}
+
+	require.NoError(t, policy.Admit(ctx, candidate("02a", "aa", 0)))
+	now = now.Add(30 * time.Second)
+	require.NoError(t, policy.Admit(ctx, candidate("02a", "aa", 1)))
+
+	// Resubmitted outputs are admitted without counting again; new ones are limited
+	require.NoError(t, policy.Admit(ctx, candidate("02a", "aa", 0)))
+	require.ErrorIs(t, policy.Admit(ctx, candidate("02a", "bb", 0)), ErrRateLimitExceeded)
+	require.NoError(t, policy.Admit(ctx, candidate("02b", "bb", 0)))
+
+	// The first admission leaves the window
+	now = now.Add(31 * time.Second)
+	require.NoError(t, policy.Admit(ctx, candidate("02a", "bb", 0)))
+	require.ErrorIs(t, policy.Admit(ctx, candidate("02a", "cc", 0)), ErrRateLimitExceeded)
+}
@@ -0,0 +1,94 @@
+package shared
+
+import (
+	"sync"
+
+	"github.com/bsv-blockchain/go-sdk/overlay"
+)
+
+// RejectionReason categorizes why an output was not admitted.
+type RejectionReason string
+
+// Rejection reasons reported by IdentifyAdmissibleOutputsWithReport, in the order the checks run.
+const (
+	// RejectionDecodeError means the locking script is not a PushDrop token
+	RejectionDecodeError RejectionReason = "decode_error"
+	// RejectionFieldCount means the token does not have exactly five fields
+	RejectionFieldCount RejectionReason = "field_count"
+	// RejectionWrongIdentifier means the protocol identifier field does not match the topic manager
+	RejectionWrongIdentifier RejectionReason = "wrong_identifier"
+	// RejectionInvalidURI means the advertised domain is not an advertisable URI
+	RejectionInvalidURI RejectionReason = "invalid_uri"
+	// RejectionInvalidTopic means the topic or service name is invalid or has the wrong prefix
+	RejectionInvalidTopic RejectionReason = "invalid_topic"
+	// RejectionInvalidSignature means the signature is missing, malformed or not linked to the identity key
+	RejectionInvalidSignature RejectionReason = "invalid_signature"
+	// RejectionPolicy means the configured admittance policy rejected the output
+	RejectionPolicy RejectionReason = "policy"
+)
+
+// OutputRejection describes why a single output was not admitted.
+type OutputRejection struct {
+	// OutputIndex is the index of the rejected output
+	OutputIndex uint32 `json:"outputIndex"`
+	// Reason is the category of the failed check
+	Reason RejectionReason `json:"reason"`
+	// Detail is a human-readable description of the failure
+	Detail string `json:"detail"`
+}
+
+// AdmittanceReport is the result of IdentifyAdmissibleOutputsWithReport.
+type AdmittanceReport struct {
+	// Txid is the transaction that was evaluated
+	Txid string `json:"txid"`
+	// TransactionFound is false when the transaction was not present in the BEEF
+	TransactionFound bool `json:"transactionFound"`
+	// Instructions are the admittance instructions returned to the overlay engine
+	Instructions overlay.AdmittanceInstructions `json:"instructions"`
+	// Rejections lists every output that was not admitted, in output order
+	Rejections []OutputRejection `json:"rejections,omitempty"`
+}
+
+// AdmittanceStats is a snapshot of admittance counters.
+type AdmittanceStats struct {
+	// Admitted is the number of outputs admitted
+	Admitted uint64 `json:"admitted"`
+	// Rejected is the number of outputs rejected, by reason
+	Rejected map[RejectionReason]uint64 `json:"rejected"`
+}
+
+// AdmittanceCounters accumulates admittance outcomes across reports.
+// It is safe for concurrent use.
+type AdmittanceCounters struct {
+	mutex    sync.Mutex
+	admitted uint64
+	rejected map[RejectionReason]uint64
+}
+
+// NewAdmittanceCounters creates a new set of zeroed admittance counters.
+func NewAdmittanceCounters() *AdmittanceCounters {
+	return &AdmittanceCounters{rejected: make(map[RejectionReason]uint64)}
+}
+
+// Record adds the outcome of a report to the counters.
+func (c *AdmittanceCounters) Record(report AdmittanceReport) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.admitted += uint64(len(report.Instructions.OutputsToAdmit))
+	for _, rejection := range report.Rejections {
+		c.rejected[rejection.Reason]++
+	}
+}
+
+// Snapshot returns a copy of the current counter values.
+func (c *AdmittanceCounters) Snapshot() AdmittanceStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	rejected := make(map[RejectionReason]uint64, len(c.rejected))
+	for reason, count := range c.rejected {
+		rejected[reason] = count
+	}
+	return AdmittanceStats{Admitted: c.admitted, Rejected: rejected}
+}
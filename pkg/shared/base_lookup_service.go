@@ -2,21 +2,40 @@ package shared
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
 	"github.com/bsv-blockchain/go-sdk/overlay"
 	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
 	"github.com/bsv-blockchain/go-sdk/transaction"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
 // StoreRecordFunc is the function signature for storing a record parsed from PushDrop output.
 type StoreRecordFunc func(ctx context.Context, txid string, outputIndex int, identityKey, domain, fourthField string) error
 
+// StoreRecordWithTokenFunc is the function signature for storing a record together with the
+// raw token material it was parsed from.
+type StoreRecordWithTokenFunc func(ctx context.Context, txid string, outputIndex int, identityKey, domain, fourthField string, token *types.TokenMaterial) error
+
 // FindAllFunc is the function signature for retrieving all records with pagination.
 type FindAllFunc func(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error)
 
+// RecordEventFunc builds the RecordEvent describing a record parsed from PushDrop output.
+type RecordEventFunc func(txid string, outputIndex int, identityKey, domain, fourthField string) types.RecordEvent
+
+// FindEventFunc returns the RecordEvent describing the stored record for an outpoint,
+// or nil if no record exists.
+type FindEventFunc func(ctx context.Context, txid string, outputIndex int) (*types.RecordEvent, error)
+
+// RecordEventListener is notified after a record has been stored or deleted.
+type RecordEventListener func(ctx context.Context, event types.RecordEvent)
+
 // BaseLookupConfig holds protocol-specific configuration for a BaseLookupService.
 type BaseLookupConfig struct {
 	// Topic is the expected topic name (e.g. "tm_ship" or "tm_slap")
@@ -33,19 +52,51 @@ type BaseLookupConfig struct {
 	Documentation *string
 	// StoreRecord stores a parsed PushDrop record
 	StoreRecord StoreRecordFunc
+	// StoreRecordWithToken stores a parsed PushDrop record with its raw token material
+	StoreRecordWithToken StoreRecordWithTokenFunc
 	// DeleteRecord deletes a record by txid and output index
 	DeleteRecord DeleteRecordFunc
 	// FindAll returns all records with pagination
 	FindAll FindAllFunc
+	// RecordEvent builds the admitted event for a parsed record
+	RecordEvent RecordEventFunc
+	// FindEvent describes the stored record for an outpoint, or returns nil if there is none
+	FindEvent FindEventFunc
 }
 
 // BaseLookupService provides shared implementations for the engine.LookupService interface
 // methods that are structurally identical between SHIP and SLAP. Embed this in protocol-specific
 // LookupService types to eliminate code duplication.
+//
+// Its Set methods are not safe for concurrent use: call them before the lookup service is
+// registered with an engine.
 type BaseLookupService struct {
 	DiscoveryNoOps
 
 	Cfg BaseLookupConfig
+
+	// listener receives record events (optional)
+	listener RecordEventListener
+	// beefProvider resolves output BEEF for output-list answers (optional)
+	beefProvider BEEFProvider
+	// keepTokenMaterial stores the raw token material with each record
+	keepTokenMaterial bool
+	// uriSchemes, when set, limits stored records to advertisable URIs (optional)
+	uriSchemes *utils.URISchemeRegistry
+	// naming, when set, limits stored records to valid topic or service names (optional)
+	naming *utils.NamingPolicy
+	// observer receives lookup and removal events (optional)
+	observer Observer
+	// tracerProvider provides the tracer for spans (optional)
+	tracerProvider trace.TracerProvider
+	// logger receives storage and lookup events (optional)
+	logger *slog.Logger
+	// clock stamps record events (optional)
+	clock Clock
+	// maxResults caps the number of outpoints returned by a query (optional)
+	maxResults int
+	// signatureCache caches the signature results of re-verified token material (optional)
+	signatureCache *SignatureCache
 }
 
 // NewBaseLookupService creates a new BaseLookupService with the given configuration.
@@ -56,25 +107,220 @@ func NewBaseLookupService(cfg BaseLookupConfig) BaseLookupService {
 // OutputAdmittedByTopic processes a PushDrop output and stores the record if it matches
 // the expected topic and protocol identifier.
 func (b *BaseLookupService) OutputAdmittedByTopic(ctx context.Context, payload *engine.OutputAdmittedByTopic) error {
-	fields, err := ParsePushDropOutput(payload, b.Cfg.Topic, b.Cfg.Identifier)
+	ctx, span := b.startSpan(ctx, "OutputAdmittedByTopic",
+		AttrTopic.String(payload.Topic),
+		AttrOutputIndex.Int(int(payload.OutputIndex)),
+	)
+	err := b.outputAdmittedByTopic(ctx, payload)
+	EndSpan(span, err)
+	return err
+}
+
+// outputAdmittedByTopic implements OutputAdmittedByTopic.
+func (b *BaseLookupService) outputAdmittedByTopic(ctx context.Context, payload *engine.OutputAdmittedByTopic) error {
+	fields, err := ParsePushDropOutputContext(ctx, payload, b.Cfg.Topic, b.Cfg.Identifier)
 	if err != nil {
 		return err
 	}
 	if fields == nil {
 		return nil
 	}
+	if err := b.checkRecordFields(fields); err != nil {
+		Logger(ctx, b.logger).Debug("Record not stored",
+			LogKeyProtocol, b.Cfg.Identifier,
+			LogKeyTxid, fields.Txid,
+			LogKeyOutputIndex, fields.OutputIndex,
+			LogKeyReason, err,
+		)
+		return nil
+	}
+
+	return b.storeRecord(ctx, fields)
+}
+
+// checkRecordFields applies the optional URI scheme registry and naming policy to a parsed record.
+func (b *BaseLookupService) checkRecordFields(fields *PushDropFields) error {
+	if b.uriSchemes != nil {
+		if _, err := b.uriSchemes.Parse(fields.Domain); err != nil {
+			return err
+		}
+	}
+	if b.naming != nil {
+		return b.naming.Validate(fields.FourthField)
+	}
+	return nil
+}
+
+// storeRecord stores a parsed record and, when a listener is registered, emits an admitted event.
+func (b *BaseLookupService) storeRecord(ctx context.Context, fields *PushDropFields) error {
+	var err error
+	if b.keepTokenMaterial && b.Cfg.StoreRecordWithToken != nil {
+		err = b.Cfg.StoreRecordWithToken(ctx, fields.Txid, fields.OutputIndex, fields.IdentityKey, fields.Domain, fields.FourthField, fields.Token)
+	} else {
+		err = b.Cfg.StoreRecord(ctx, fields.Txid, fields.OutputIndex, fields.IdentityKey, fields.Domain, fields.FourthField)
+	}
+	if err != nil {
+		return err
+	}
+
+	if b.listener != nil && b.Cfg.RecordEvent != nil {
+		// Report the domain as storage holds it
+		event := b.Cfg.RecordEvent(fields.Txid, fields.OutputIndex, fields.IdentityKey, utils.CanonicalDomain(fields.Domain), fields.FourthField)
+		event.Type = types.RecordEventAdmitted
+		event.Timestamp = Now(b.clock)
+		b.listener(ctx, event)
+	}
 
-	return b.Cfg.StoreRecord(ctx, fields.Txid, fields.OutputIndex, fields.IdentityKey, fields.Domain, fields.FourthField)
+	return nil
 }
 
 // OutputSpent removes the record when the UTXO is spent.
 func (b *BaseLookupService) OutputSpent(ctx context.Context, payload *engine.OutputSpent) error {
-	return HandleOutputSpent(ctx, payload, b.Cfg.Topic, b.Cfg.DeleteRecord)
+	ctx, span := b.startSpan(ctx, "OutputSpent", AttrTopic.String(payload.Topic))
+	err := HandleOutputSpent(ctx, payload, b.Cfg.Topic, b.removeRecord(RemovalSpent))
+	EndSpan(span, err)
+	return err
 }
 
 // OutputEvicted removes the record when the UTXO is evicted from the mempool.
 func (b *BaseLookupService) OutputEvicted(ctx context.Context, outpoint *transaction.Outpoint) error {
-	return HandleOutputEvicted(ctx, outpoint, b.Cfg.DeleteRecord)
+	ctx, span := b.startSpan(ctx, "OutputEvicted")
+	err := HandleOutputEvicted(ctx, outpoint, b.removeRecord(RemovalEvicted))
+	EndSpan(span, err)
+	return err
+}
+
+// SetRecordEventListener sets the listener notified after records are stored or deleted.
+func (b *BaseLookupService) SetRecordEventListener(listener RecordEventListener) {
+	b.listener = listener
+}
+
+// SetKeepTokenMaterial stores records with their token material for re-verification; off by default.
+// Without a StoreRecordWithToken function, records are stored without it.
+func (b *BaseLookupService) SetKeepTokenMaterial(keep bool) {
+	b.keepTokenMaterial = keep
+}
+
+// SetSignatureCache sets the (shareable) cache of verified signatures used when stored token
+// material is re-verified; nil verifies every signature.
+func (b *BaseLookupService) SetSignatureCache(cache *SignatureCache) {
+	b.signatureCache = cache
+}
+
+// ReverifyConfig returns the configuration for re-verifying the token material stored by the
+// lookup service with Reverify.
+func (b *BaseLookupService) ReverifyConfig() ReverifyConfig {
+	return ReverifyConfig{Identifier: b.Cfg.Identifier, SignatureCache: b.signatureCache}
+}
+
+// SetBEEFProvider makes Lookup answer output lists with BEEF; nil answers freeform outpoint lists.
+func (b *BaseLookupService) SetBEEFProvider(provider BEEFProvider) {
+	b.beefProvider = provider
+}
+
+// SetURISchemes skips storing outputs whose URI the registry rejects; nil stores every output.
+func (b *BaseLookupService) SetURISchemes(registry *utils.URISchemeRegistry) {
+	b.uriSchemes = registry
+}
+
+// SetNamingPolicy skips storing outputs whose name the policy rejects; nil stores every output.
+func (b *BaseLookupService) SetNamingPolicy(policy *utils.NamingPolicy) {
+	b.naming = policy
+}
+
+// SetObserver sets the observer notified of lookups and removals; nil disables notifications.
+func (b *BaseLookupService) SetObserver(observer Observer) {
+	b.observer = observer
+}
+
+// SetTracerProvider sets the span provider; nil uses the context's, as described by Tracer.
+func (b *BaseLookupService) SetTracerProvider(provider trace.TracerProvider) {
+	b.tracerProvider = provider
+}
+
+// SetLogger sets the logger of storage, lookup and maintenance events; nil discards them.
+func (b *BaseLookupService) SetLogger(logger *slog.Logger) {
+	b.logger = logger
+}
+
+// SetClock sets the clock stamping record events; nil uses the system time.
+func (b *BaseLookupService) SetClock(clock Clock) {
+	b.clock = clock
+}
+
+// SetMaxResults caps the outpoints a query returns, whatever limit it asks for; zero is unbounded.
+func (b *BaseLookupService) SetMaxResults(maxResults int) {
+	b.maxResults = maxResults
+}
+
+// LimitResults returns the limit to apply to a query asking for limit results: limit itself,
+// lowered to the cap set by SetMaxResults, if any.
+func (b *BaseLookupService) LimitResults(limit *int) *int {
+	if b.maxResults <= 0 || (limit != nil && *limit <= b.maxResults) {
+		return limit
+	}
+	maxResults := b.maxResults
+	return &maxResults
+}
+
+// ContextWithLogger returns a copy of ctx carrying the logger set by SetLogger, for calling
+// the package-level helpers, such as Reverify, on behalf of the lookup service.
+func (b *BaseLookupService) ContextWithLogger(ctx context.Context) context.Context {
+	return ContextWithLogger(ctx, b.logger)
+}
+
+// startSpan starts a span for a lookup service operation, tagged with the protocol.
+func (b *BaseLookupService) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, AttrProtocol.String(b.Cfg.Identifier))
+	return Tracer(ctx, b.tracerProvider).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// removeRecord returns a function deleting a record and reporting its removal to the observer.
+func (b *BaseLookupService) removeRecord(cause RemovalCause) DeleteRecordFunc {
+	return func(ctx context.Context, txid string, outputIndex int) error {
+		if err := b.deleteRecord(ctx, txid, outputIndex); err != nil {
+			return err
+		}
+		if b.observer != nil {
+			b.observer.ObserveRemoval(b.Cfg.Identifier, cause)
+		}
+		return nil
+	}
+}
+
+// deleteRecord deletes a record and, when a listener is registered, emits a removal event
+// describing the record as it was stored.
+func (b *BaseLookupService) deleteRecord(ctx context.Context, txid string, outputIndex int) error {
+	if b.listener == nil {
+		return b.Cfg.DeleteRecord(ctx, txid, outputIndex)
+	}
+
+	event := types.RecordEvent{
+		Protocol:    b.Cfg.Identifier,
+		Txid:        txid,
+		OutputIndex: outputIndex,
+	}
+	if b.Cfg.FindEvent != nil {
+		stored, err := b.Cfg.FindEvent(ctx, txid, outputIndex)
+		if err != nil {
+			return err
+		}
+		if stored != nil {
+			event = *stored
+		}
+	}
+
+	if err := b.Cfg.DeleteRecord(ctx, txid, outputIndex); err != nil {
+		return err
+	}
+
+	// Records stored before domains were canonicalized may still hold another form
+	event.Domain = utils.CanonicalDomain(event.Domain)
+	event.Type = types.RecordEventRemoved
+	event.Timestamp = Now(b.clock)
+	b.listener(ctx, event)
+
+	return nil
 }
 
 // ServiceName returns the protocol service identifier.
@@ -82,9 +328,10 @@ func (b *BaseLookupService) ServiceName() string {
 	return b.Cfg.ServiceID
 }
 
-// FindAll delegates to the configured FindAll function (implements shared.QueryExecutor).
+// FindAll delegates to the configured FindAll function (implements shared.QueryExecutor),
+// applying the cap set by SetMaxResults.
 func (b *BaseLookupService) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
-	return b.Cfg.FindAll(ctx, limit, skip, sortOrder)
+	return b.Cfg.FindAll(ctx, b.LimitResults(limit), skip, sortOrder)
 }
 
 // GetDocumentation returns the protocol documentation string.
@@ -105,6 +352,32 @@ func (b *BaseLookupService) GetMetaData() *overlay.MetaData {
 
 // Lookup performs a lookup query using the shared ExecuteLookup framework.
 // The executor parameter should be the outer LookupService that implements QueryExecutor.
+// When a BEEF provider is set, the matching outpoints are returned as an output-list answer.
+// When an observer is set, it is notified of the query type, duration and outcome.
 func (b *BaseLookupService) Lookup(ctx context.Context, question *lookup.LookupQuestion, executor QueryExecutor) (*lookup.LookupAnswer, error) {
-	return ExecuteLookup(ctx, question, executor)
+	ctx, span := b.startSpan(ctx, "Lookup", AttrService.String(question.Service))
+	start := time.Now()
+	answer, err := b.lookup(ctx, question, executor)
+	if b.observer != nil {
+		b.observer.ObserveLookup(b.Cfg.ServiceID, ClassifyQuery(question.Query), time.Since(start), err)
+	}
+	if answer != nil && answer.Type == lookup.AnswerTypeOutputList {
+		span.SetAttributes(AttrResultCount.Int(len(answer.Outputs)))
+	}
+	EndSpan(span, err)
+	return answer, err
+}
+
+// lookup executes a lookup question and converts the answer for the BEEF provider, if any.
+func (b *BaseLookupService) lookup(ctx context.Context, question *lookup.LookupQuestion, executor QueryExecutor) (*lookup.LookupAnswer, error) {
+	answer, err := ExecuteLookup(ctx, question, executor)
+	if err != nil || b.beefProvider == nil {
+		return answer, err
+	}
+
+	utxos, ok := answer.Result.([]types.UTXOReference)
+	if !ok {
+		return answer, nil
+	}
+	return ConvertUTXOsToOutputListAnswer(b.ContextWithLogger(ctx), utxos, b.beefProvider)
 }
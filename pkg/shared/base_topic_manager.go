@@ -2,10 +2,14 @@ package shared
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/bsv-blockchain/go-sdk/chainhash"
 	"github.com/bsv-blockchain/go-sdk/overlay"
 	"github.com/bsv-blockchain/go-sdk/transaction"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
 // BaseTopicManagerConfig holds protocol-specific configuration for BaseTopicManagerOps.
@@ -18,24 +22,110 @@ type BaseTopicManagerConfig struct {
 	MetaDataDescription string
 	// Documentation is the documentation string returned by GetDocumentation
 	Documentation *string
+	// Clock stamps subscriptions (optional). When nil, the system time is used.
+	Clock Clock
 }
 
 // BaseTopicManagerOps provides shared implementations for engine.TopicManager interface methods
 // that are structurally identical between SHIP and SLAP. Embed this in protocol-specific
 // TopicManager types to eliminate code duplication.
+//
+// Its Set methods are not safe for concurrent use: call them before the topic manager starts
+// processing transactions.
 type BaseTopicManagerOps struct {
 	Cfg BaseTopicManagerConfig
+
+	// counters accumulates the outcome of every admittance check
+	counters *AdmittanceCounters
+	// observer receives the outcome of every admittance check (optional)
+	observer Observer
 }
 
 // NewBaseTopicManagerOps creates a new BaseTopicManagerOps with the given configuration.
 func NewBaseTopicManagerOps(cfg BaseTopicManagerConfig) BaseTopicManagerOps {
-	return BaseTopicManagerOps{Cfg: cfg}
+	if cfg.Admittance.Verifier == nil {
+		// Build the verifier once rather than on every IdentifyAdmissibleOutputs call
+		cfg.Admittance.Verifier = newAdvertisementVerifier()
+	}
+	return BaseTopicManagerOps{Cfg: cfg, counters: NewAdmittanceCounters()}
+}
+
+// SetAdmittancePolicy sets the policy checked after the protocol checks; nil admits every output.
+func (b *BaseTopicManagerOps) SetAdmittancePolicy(policy AdmittancePolicy) {
+	b.Cfg.Admittance.Policy = policy
+}
+
+// SetSignatureCache sets the (shareable) cache of verified signatures; nil verifies every signature.
+func (b *BaseTopicManagerOps) SetSignatureCache(cache *SignatureCache) {
+	b.Cfg.Admittance.SignatureCache = cache
+}
+
+// SetVerifyWorkers sets how many signatures of a transaction are verified concurrently; <= 1 is sequential.
+func (b *BaseTopicManagerOps) SetVerifyWorkers(workers int) {
+	b.Cfg.Admittance.VerifyWorkers = workers
+}
+
+// SetURISchemes sets the registry of admissible URIs; nil uses utils.DefaultURISchemeRegistry.
+func (b *BaseTopicManagerOps) SetURISchemes(registry *utils.URISchemeRegistry) {
+	b.Cfg.Admittance.URISchemes = registry
+}
+
+// SetNamingPolicy sets the policy for admissible names; nil uses utils.DefaultNamingPolicy.
+func (b *BaseTopicManagerOps) SetNamingPolicy(policy *utils.NamingPolicy) {
+	b.Cfg.Admittance.Naming = policy
+}
+
+// SetObserver sets the observer notified of every admittance outcome; nil disables notifications.
+func (b *BaseTopicManagerOps) SetObserver(observer Observer) {
+	b.observer = observer
+}
+
+// SetTracerProvider sets the admittance span provider; nil uses the context's, as described by Tracer.
+func (b *BaseTopicManagerOps) SetTracerProvider(provider trace.TracerProvider) {
+	b.Cfg.Admittance.TracerProvider = provider
+}
+
+// SetLogger sets the logger of admittance and record delivery events; nil discards them.
+func (b *BaseTopicManagerOps) SetLogger(logger *slog.Logger) {
+	b.Cfg.Admittance.Logger = logger
+}
+
+// SetClock sets the clock stamping subscriptions; nil uses the system time.
+func (b *BaseTopicManagerOps) SetClock(clock Clock) {
+	b.Cfg.Clock = clock
 }
 
 // IdentifyAdmissibleOutputs implements the engine.TopicManager interface.
 // It delegates to the shared IdentifyAdmissibleOutputs function with protocol-specific config.
 func (b *BaseTopicManagerOps) IdentifyAdmissibleOutputs(ctx context.Context, beef *transaction.Beef, txid *chainhash.Hash, previousCoins []uint32) (overlay.AdmittanceInstructions, error) {
-	return IdentifyAdmissibleOutputs(ctx, beef, txid, previousCoins, b.Cfg.Admittance)
+	report, err := b.IdentifyAdmissibleOutputsWithReport(ctx, beef, txid, previousCoins)
+	return report.Instructions, err
+}
+
+// IdentifyAdmissibleOutputsWithReport behaves like IdentifyAdmissibleOutputs but also returns
+// the reason each output was rejected. The outcome is added to the admittance counters and
+// reported to the observer, if any.
+func (b *BaseTopicManagerOps) IdentifyAdmissibleOutputsWithReport(ctx context.Context, beef *transaction.Beef, txid *chainhash.Hash, previousCoins []uint32) (AdmittanceReport, error) {
+	report, err := IdentifyAdmissibleOutputsWithReport(ctx, beef, txid, previousCoins, b.Cfg.Admittance)
+	if err != nil {
+		return report, err
+	}
+	if b.counters != nil {
+		b.counters.Record(report)
+	}
+	if b.observer != nil {
+		b.observer.ObserveAdmittance(b.Cfg.Admittance.Identifier, report)
+	}
+	return report, nil
+}
+
+// GetAdmittanceStats returns the number of outputs admitted and rejected (by reason)
+// since the topic manager was created.
+func (b *BaseTopicManagerOps) GetAdmittanceStats() AdmittanceStats {
+	if b.counters == nil {
+		return AdmittanceStats{Rejected: map[RejectionReason]uint64{}}
+	}
+	return b.counters.Snapshot()
 }
 
 // IdentifyNeededInputs implements the engine.TopicManager interface.
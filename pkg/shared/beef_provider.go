@@ -0,0 +1,116 @@
+package shared
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Common error variables for BEEF resolution.
+var (
+	ErrOutputBEEFNotFound = errors.New("output BEEF not found")
+	ErrInvalidOutpoint    = errors.New("invalid outpoint")
+)
+
+// BEEFProvider resolves the BEEF of the transaction containing a stored output, so lookup
+// answers can carry the full token for verification.
+type BEEFProvider interface {
+	// GetOutputBEEF returns the BEEF for the transaction containing the output.
+	// It returns an error wrapping ErrOutputBEEFNotFound if the output is unknown.
+	GetOutputBEEF(ctx context.Context, txid string, outputIndex int) ([]byte, error)
+}
+
+// BEEFProviderFunc adapts an ordinary function to the BEEFProvider interface.
+type BEEFProviderFunc func(ctx context.Context, txid string, outputIndex int) ([]byte, error)
+
+// GetOutputBEEF calls f(ctx, txid, outputIndex).
+func (f BEEFProviderFunc) GetOutputBEEF(ctx context.Context, txid string, outputIndex int) ([]byte, error) {
+	return f(ctx, txid, outputIndex)
+}
+
+// EngineBEEFProvider resolves BEEF from the overlay engine's output storage.
+type EngineBEEFProvider struct {
+	// Storage is the overlay engine storage holding admitted outputs
+	Storage engine.Storage
+	// Topic restricts resolution to outputs admitted under this topic (optional)
+	Topic string
+}
+
+// NewEngineBEEFProvider creates a BEEF provider backed by overlay engine storage.
+func NewEngineBEEFProvider(storage engine.Storage, topic string) *EngineBEEFProvider {
+	return &EngineBEEFProvider{Storage: storage, Topic: topic}
+}
+
+// GetOutputBEEF implements BEEFProvider, returning atomic BEEF for the output's transaction.
+func (p *EngineBEEFProvider) GetOutputBEEF(ctx context.Context, txid string, outputIndex int) ([]byte, error) {
+	// Stored txids are the hex encoding of the hash bytes, as produced by ParsePushDropOutput
+	txidBytes, err := hex.DecodeString(txid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutpoint, err)
+	}
+	hash, err := chainhash.NewHash(txidBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutpoint, err)
+	}
+	if outputIndex < 0 || outputIndex > 0xFFFFFFFF {
+		return nil, fmt.Errorf("%w: output index %d", ErrInvalidOutpoint, outputIndex)
+	}
+
+	var topic *string
+	if p.Topic != "" {
+		topic = &p.Topic
+	}
+
+	output, err := p.Storage.FindOutput(ctx, &transaction.Outpoint{Txid: *hash, Index: uint32(outputIndex)}, topic, nil, true)
+	if err != nil && !errors.Is(err, engine.ErrNotFound) {
+		return nil, err
+	}
+	if output == nil || output.Beef == nil {
+		return nil, fmt.Errorf("%w: %s.%d", ErrOutputBEEFNotFound, txid, outputIndex)
+	}
+
+	return output.Beef.AtomicBytes(hash)
+}
+
+// ConvertUTXOsToOutputListAnswer converts UTXO references to an output-list LookupAnswer,
+// resolving each output's BEEF through the provider. Outputs whose BEEF cannot be found are
+// omitted from the answer, and logged to the logger carried by ctx (see ContextWithLogger);
+// any other provider error fails the conversion.
+func ConvertUTXOsToOutputListAnswer(ctx context.Context, utxos []types.UTXOReference, provider BEEFProvider) (*lookup.LookupAnswer, error) {
+	outputs := make([]*lookup.OutputListItem, 0, len(utxos))
+	for _, utxo := range utxos {
+		if utxo.OutputIndex < 0 || utxo.OutputIndex > 0xFFFFFFFF {
+			return nil, fmt.Errorf("%w: output index %d", ErrInvalidOutpoint, utxo.OutputIndex)
+		}
+
+		beef, err := provider.GetOutputBEEF(ctx, utxo.Txid, utxo.OutputIndex)
+		if errors.Is(err, ErrOutputBEEFNotFound) {
+			Logger(ctx, nil).Warn("Omitting output without BEEF from lookup answer",
+				LogKeyTxid, utxo.Txid,
+				LogKeyOutputIndex, utxo.OutputIndex,
+			)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve BEEF for %s.%d: %w", utxo.Txid, utxo.OutputIndex, err)
+		}
+
+		outputs = append(outputs, &lookup.OutputListItem{
+			Beef:        beef,
+			OutputIndex: uint32(utxo.OutputIndex),
+		})
+	}
+
+	return &lookup.LookupAnswer{
+		Type:    lookup.AnswerTypeOutputList,
+		Outputs: outputs,
+	}, nil
+}
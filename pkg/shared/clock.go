@@ -0,0 +1,34 @@
+package shared
+
+import "time"
+
+// Clock tells the current time. Components stamp events and subscriptions with the time of
+// their clock, so that tests can control it.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now implements Clock.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock reading the system time.
+type SystemClock struct{}
+
+// Now implements Clock.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Now returns the current time of clock, or the system time when clock is nil.
+func Now(clock Clock) time.Time {
+	if clock == nil {
+		return time.Now()
+	}
+	return clock.Now()
+}
@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+var errTestStorage = errors.New("storage failed")
+
+// fakeRecords is a minimal record store backing a BaseLookupService in tests.
+type fakeRecords struct {
+	records  map[string]types.RecordEvent
+	storeErr error
+	// stores counts the stores of each outpoint, to detect duplicate inserts
+	stores map[string]int
+}
+
+func newFakeRecords() *fakeRecords {
+	return &fakeRecords{records: make(map[string]types.RecordEvent), stores: make(map[string]int)}
+}
+
+func (f *fakeRecords) key(txid string, outputIndex int) string {
+	return fmt.Sprintf("%s.%d", txid, outputIndex)
+}
+
+// lookupService returns a SHIP lookup service storing its records in f.
+func (f *fakeRecords) lookupService() *BaseLookupService {
+	service := NewBaseLookupService(BaseLookupConfig{
+		Topic:      "tm_ship",
+		ServiceID:  "ls_ship",
+		Identifier: "SHIP",
+		StoreRecord: func(_ context.Context, txid string, outputIndex int, identityKey, domain, topic string) error {
+			if f.storeErr != nil {
+				return f.storeErr
+			}
+			f.stores[f.key(txid, outputIndex)]++
+			f.records[f.key(txid, outputIndex)] = types.RecordEvent{
+				Protocol: "SHIP", Txid: txid, OutputIndex: outputIndex,
+				IdentityKey: identityKey, Domain: domain, Topic: topic,
+			}
+			return nil
+		},
+		DeleteRecord: func(_ context.Context, txid string, outputIndex int) error {
+			delete(f.records, f.key(txid, outputIndex))
+			return nil
+		},
+		FindAll: func(context.Context, *int, *int, *types.SortOrder) ([]types.UTXOReference, error) {
+			refs := make([]types.UTXOReference, 0, len(f.records))
+			for _, record := range f.records {
+				refs = append(refs, types.UTXOReference{Txid: record.Txid, OutputIndex: record.OutputIndex})
+			}
+			return refs, nil
+		},
+		FindEvent: func(_ context.Context, txid string, outputIndex int) (*types.RecordEvent, error) {
+			if event, ok := f.records[f.key(txid, outputIndex)]; ok {
+				return &event, nil
+			}
+			return nil, nil
+		},
+	})
+	return &service
+}
+
+// put stores a record directly, as if admitted earlier.
+func (f *fakeRecords) put(txid string, outputIndex int, topic string) {
+	f.records[f.key(txid, outputIndex)] = types.RecordEvent{Protocol: "SHIP", Txid: txid, OutputIndex: outputIndex, Topic: topic}
+}
+
+// shipAdmittance is the admittance configuration of the SHIP topic manager.
+func shipAdmittance() AdmittanceConfig {
+	return AdmittanceConfig{Identifier: "SHIP", TopicPrefix: "tm_"}
+}
@@ -0,0 +1,109 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrHandlerPanicked is returned in place of a handler's error when the handler panics.
+var ErrHandlerPanicked = errors.New("message handler panicked")
+
+// SubscriptionID identifies a single handler registered with a topic manager subscription.
+// It is returned when the handler is added and used to remove that handler independently
+// of other handlers on the same subscription.
+type SubscriptionID string
+
+// SubscriberID returns the ID of the single handler a topic manager's Subscribe method
+// registers for the subscription key. Its "subscriber:" prefix keeps it distinct from every
+// ID returned by HandlerID, whatever the key contains.
+func SubscriberID(key string) SubscriptionID {
+	return SubscriptionID("subscriber:" + key)
+}
+
+// HandlerID returns the ID of the additional handler registered on the subscription key
+// with the given sequence number.
+func HandlerID(sequence uint64, key string) SubscriptionID {
+	return SubscriptionID(fmt.Sprintf("handler#%d:%s", sequence, key))
+}
+
+// handlerEntry pairs a handler with the ID it was registered under.
+type handlerEntry[H any] struct {
+	id      SubscriptionID
+	handler H
+}
+
+// HandlerSet holds the handlers registered on one subscription in registration order.
+// It is not safe for concurrent use; callers guard it with their own mutex.
+type HandlerSet[H any] struct {
+	entries []handlerEntry[H]
+}
+
+// Set registers the handler under the given ID, replacing any handler already registered
+// under that ID in place, or appending it otherwise.
+func (s *HandlerSet[H]) Set(id SubscriptionID, handler H) {
+	for i := range s.entries {
+		if s.entries[i].id == id {
+			s.entries[i].handler = handler
+			return
+		}
+	}
+	s.entries = append(s.entries, handlerEntry[H]{id: id, handler: handler})
+}
+
+// Remove unregisters the handler with the given ID. Returns false if no such handler exists.
+func (s *HandlerSet[H]) Remove(id SubscriptionID) bool {
+	for i := range s.entries {
+		if s.entries[i].id == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IDs returns the IDs of all registered handlers in registration order.
+func (s *HandlerSet[H]) IDs() []SubscriptionID {
+	ids := make([]SubscriptionID, len(s.entries))
+	for i, entry := range s.entries {
+		ids[i] = entry.id
+	}
+	return ids
+}
+
+// Len returns the number of registered handlers.
+func (s *HandlerSet[H]) Len() int {
+	return len(s.entries)
+}
+
+// Handlers returns a copy of the registered handlers in registration order, so they can be
+// invoked after the caller releases its lock.
+func (s *HandlerSet[H]) Handlers() []H {
+	handlers := make([]H, len(s.entries))
+	for i, entry := range s.entries {
+		handlers[i] = entry.handler
+	}
+	return handlers
+}
+
+// DeliverToHandlers invokes deliver for every handler in order. A handler that fails or
+// panics does not prevent delivery to the remaining handlers; all failures are joined
+// into the returned error.
+func DeliverToHandlers[H any](handlers []H, deliver func(handler H) error) error {
+	var errs []error
+	for _, handler := range handlers {
+		if err := deliverIsolated(handler, deliver); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliverIsolated invokes deliver for a single handler, converting a panic into an error.
+func deliverIsolated[H any](handler H, deliver func(handler H) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrHandlerPanicked, r)
+		}
+	}()
+	return deliver(handler)
+}
@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Log attribute keys shared by every component, so that events from topic managers, lookup
+// services, storage and the advertiser can be filtered on the same fields.
+const (
+	// LogKeyProtocol is the protocol identifier, "SHIP" or "SLAP"
+	LogKeyProtocol = "protocol"
+	// LogKeyTopic is an overlay topic
+	LogKeyTopic = "topic"
+	// LogKeyService is a lookup service
+	LogKeyService = "service"
+	// LogKeyTxid is a transaction ID
+	LogKeyTxid = "txid"
+	// LogKeyOutputIndex is an output index
+	LogKeyOutputIndex = "outputIndex"
+	// LogKeyReason is the reason an output or record was rejected
+	LogKeyReason = "reason"
+	// LogKeyError is the error that caused the event
+	LogKeyError = "error"
+)
+
+// discardLogger drops every record; it is the default logger of every component.
+var discardLogger = slog.New(slog.DiscardHandler) //nolint:gochecknoglobals // immutable default
+
+// loggerKey is the context key of the logger set by ContextWithLogger.
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx carrying logger, for the helpers in this package
+// that take no logger of their own.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	if logger == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger for events under ctx. A non-nil logger is used as is; otherwise
+// the logger carried by ctx, if any, is used. With neither, events are discarded, so library
+// components stay silent unless given a logger.
+func Logger(ctx context.Context, logger *slog.Logger) *slog.Logger {
+	if logger != nil {
+		return logger
+	}
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return discardLogger
+}
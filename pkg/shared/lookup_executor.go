@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
 )
@@ -16,8 +17,21 @@ var (
 	ErrValidQueryMustBeProvided  = errors.New("a valid query must be provided")
 	ErrLookupServiceNotSupported = errors.New("lookup service not supported")
 	ErrInvalidStringQuery        = errors.New("invalid string query: only 'findAll' is supported")
+	ErrMalformedQuery            = errors.New("malformed query")
 )
 
+// IsInvalidQuery reports whether a lookup failed because the question was invalid, as opposed
+// to a storage or server failure, so that transports can report it as a client error.
+func IsInvalidQuery(err error) bool {
+	return errors.Is(err, ErrValidQueryMustBeProvided) ||
+		errors.Is(err, ErrLookupServiceNotSupported) ||
+		errors.Is(err, ErrInvalidStringQuery) ||
+		errors.Is(err, ErrMalformedQuery) ||
+		errors.Is(err, ErrQueryLimitInvalid) ||
+		errors.Is(err, ErrQuerySkipInvalid) ||
+		errors.Is(err, ErrQuerySortOrderInvalid)
+}
+
 // QueryExecutor defines the operations needed to execute a lookup query.
 // Both SHIP and SLAP lookup services implement this interface to share
 // the common Lookup method logic.
@@ -34,7 +48,26 @@ type QueryExecutor interface {
 // ExecuteLookup implements the common Lookup logic shared by SHIP and SLAP lookup services.
 // It validates the question, parses the query JSON, handles the "findAll" string shortcut,
 // and delegates typed query execution to the provided QueryExecutor.
-func ExecuteLookup(ctx context.Context, question *lookup.LookupQuestion, executor QueryExecutor) (*lookup.LookupAnswer, error) {
+// The lookup is recorded as a span under ctx, as described by Tracer.
+func ExecuteLookup(ctx context.Context, question *lookup.LookupQuestion, executor QueryExecutor) (answer *lookup.LookupAnswer, err error) {
+	ctx, span := Tracer(ctx, nil).Start(ctx, "ExecuteLookup", trace.WithAttributes(
+		AttrService.String(question.Service),
+		AttrQueryType.String(string(ClassifyQuery(question.Query))),
+	))
+	defer func() {
+		if answer != nil {
+			if utxos, ok := answer.Result.([]types.UTXOReference); ok {
+				span.SetAttributes(AttrResultCount.Int(len(utxos)))
+			}
+		}
+		EndSpan(span, err)
+	}()
+
+	return executeLookup(ctx, question, executor)
+}
+
+// executeLookup implements ExecuteLookup.
+func executeLookup(ctx context.Context, question *lookup.LookupQuestion, executor QueryExecutor) (*lookup.LookupAnswer, error) {
 	// Validate required fields
 	if len(question.Query) == 0 {
 		return nil, ErrValidQueryMustBeProvided
@@ -47,7 +80,7 @@ func ExecuteLookup(ctx context.Context, question *lookup.LookupQuestion, executo
 	// Parse the query from JSON
 	var queryInterface interface{}
 	if err := json.Unmarshal(question.Query, &queryInterface); err != nil {
-		return nil, fmt.Errorf("failed to parse query JSON: %w", err)
+		return nil, fmt.Errorf("%w: failed to parse query JSON: %w", ErrMalformedQuery, err)
 	}
 
 	// Handle legacy "findAll" string query
@@ -80,7 +113,7 @@ func ParseQueryJSON(query, target interface{}) error {
 	}
 
 	if err := json.Unmarshal(jsonBytes, target); err != nil {
-		return fmt.Errorf("failed to unmarshal query object: %w", err)
+		return fmt.Errorf("%w: failed to unmarshal query object: %w", ErrMalformedQuery, err)
 	}
 
 	return nil
@@ -0,0 +1,63 @@
+package shared
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// QueryType classifies a lookup question for instrumentation.
+type QueryType string
+
+// Query types reported to an Observer.
+const (
+	// QueryTypeFindAll is a "findAll" string query or an object query with findAll set
+	QueryTypeFindAll QueryType = "find_all"
+	// QueryTypeFiltered is an object query selecting records by its fields
+	QueryTypeFiltered QueryType = "filtered"
+	// QueryTypeInvalid is a query that is neither of the above
+	QueryTypeInvalid QueryType = "invalid"
+)
+
+// RemovalCause is the reason a record was removed from storage.
+type RemovalCause string
+
+// Removal causes reported to an Observer.
+const (
+	// RemovalSpent means the advertised output was spent
+	RemovalSpent RemovalCause = "spent"
+	// RemovalEvicted means the advertised output was evicted from the mempool
+	RemovalEvicted RemovalCause = "evicted"
+)
+
+// Observer receives instrumentation events from topic managers and lookup services, for
+// example to export metrics. Implementations must be safe for concurrent use and should
+// return quickly, since they are called on the processing path.
+type Observer interface {
+	// ObserveAdmittance is called with the outcome of every successful admittance check
+	ObserveAdmittance(protocol string, report AdmittanceReport)
+	// ObserveRemoval is called after a record is removed because its output was spent or evicted
+	ObserveRemoval(protocol string, cause RemovalCause)
+	// ObserveLookup is called after every lookup question, with the error it failed with, if any
+	ObserveLookup(service string, queryType QueryType, duration time.Duration, err error)
+}
+
+// ClassifyQuery returns the QueryType of a raw lookup query.
+func ClassifyQuery(query json.RawMessage) QueryType {
+	var queryInterface interface{}
+	if err := json.Unmarshal(query, &queryInterface); err != nil {
+		return QueryTypeInvalid
+	}
+
+	switch q := queryInterface.(type) {
+	case string:
+		if q == "findAll" {
+			return QueryTypeFindAll
+		}
+	case map[string]interface{}:
+		if findAll, ok := q["findAll"].(bool); ok && findAll {
+			return QueryTypeFindAll
+		}
+		return QueryTypeFiltered
+	}
+	return QueryTypeInvalid
+}
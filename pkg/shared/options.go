@@ -0,0 +1,96 @@
+package shared
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// Common error variables for component options.
+var (
+	// ErrUnsupportedOption reports an identifier option that the component being created cannot apply
+	ErrUnsupportedOption = errors.New("option is not supported by this component")
+)
+
+// Options holds the settings of a SHIP or SLAP lookup service or topic manager created with
+// options. The protocol packages fill in their defaults and apply the caller's Option values
+// over them. Topic managers reject topic and service settings they cannot apply with
+// ErrUnsupportedOption; other settings that do not apply to the component are ignored.
+type Options struct {
+	// Topic is the topic manager topic (e.g. "tm_ship")
+	Topic string
+	// Service is the lookup service identifier (e.g. "ls_ship")
+	Service string
+	// MetaDataName is the human-readable name returned by GetMetaData
+	MetaDataName string
+	// MetaDataDescription is the description returned by GetMetaData
+	MetaDataDescription string
+	// Documentation is the documentation string returned by GetDocumentation
+	Documentation string
+	// Logger receives the component's events (optional)
+	Logger *slog.Logger
+	// Clock stamps record events and subscriptions (optional)
+	Clock Clock
+	// MaxResults caps the number of outpoints a lookup query returns; zero leaves it unbounded
+	MaxResults int
+	// VerifyWorkers is the number of signatures a topic manager verifies concurrently
+	VerifyWorkers int
+}
+
+// Option customizes a lookup service or topic manager at construction.
+type Option func(*Options)
+
+// ApplyOptions returns defaults with opts applied in order.
+func ApplyOptions(defaults Options, opts []Option) Options {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&defaults)
+		}
+	}
+	return defaults
+}
+
+// WithTopic sets the topic manager topic. A lookup service only stores outputs admitted
+// under this topic; a SHIP topic manager publishes record events on it.
+func WithTopic(topic string) Option {
+	return func(o *Options) { o.Topic = topic }
+}
+
+// WithService sets the lookup service identifier answered by a lookup service.
+func WithService(service string) Option {
+	return func(o *Options) { o.Service = service }
+}
+
+// WithMetaData sets the name and description returned by GetMetaData.
+func WithMetaData(name, description string) Option {
+	return func(o *Options) {
+		o.MetaDataName = name
+		o.MetaDataDescription = description
+	}
+}
+
+// WithDocumentation sets the documentation returned by GetDocumentation.
+func WithDocumentation(documentation string) Option {
+	return func(o *Options) { o.Documentation = documentation }
+}
+
+// WithLogger sets the logger receiving the component's events, as SetLogger does.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) { o.Logger = logger }
+}
+
+// WithClock sets the clock stamping record events and subscriptions, as SetClock does.
+func WithClock(clock Clock) Option {
+	return func(o *Options) { o.Clock = clock }
+}
+
+// WithMaxResults caps the number of outpoints a lookup query returns, as
+// BaseLookupService.SetMaxResults does.
+func WithMaxResults(maxResults int) Option {
+	return func(o *Options) { o.MaxResults = maxResults }
+}
+
+// WithVerifyWorkers sets the number of signatures a topic manager verifies concurrently, as
+// BaseTopicManagerOps.SetVerifyWorkers does.
+func WithVerifyWorkers(workers int) Option {
+	return func(o *Options) { o.VerifyWorkers = workers }
+}
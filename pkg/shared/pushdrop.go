@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -8,6 +9,9 @@ import (
 	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
 	"github.com/bsv-blockchain/go-sdk/transaction"
 	"github.com/bsv-blockchain/go-sdk/transaction/template/pushdrop"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
 )
 
 // Common error variables for PushDrop processing.
@@ -25,6 +29,7 @@ type PushDropFields struct {
 	FourthField string // topic (SHIP) or service (SLAP)
 	Txid        string
 	OutputIndex int
+	Token       *types.TokenMaterial
 }
 
 // ParsePushDropOutput decodes a PushDrop locking script from an OutputAdmittedByTopic payload,
@@ -32,6 +37,27 @@ type PushDropFields struct {
 // Returns nil (no error) if the topic or identifier doesn't match, indicating the output
 // should be silently ignored.
 func ParsePushDropOutput(payload *engine.OutputAdmittedByTopic, expectedTopic, expectedIdentifier string) (*PushDropFields, error) {
+	return ParsePushDropOutputContext(context.Background(), payload, expectedTopic, expectedIdentifier)
+}
+
+// ParsePushDropOutputContext behaves like ParsePushDropOutput, recording the parse as a span
+// under ctx.
+func ParsePushDropOutputContext(ctx context.Context, payload *engine.OutputAdmittedByTopic, expectedTopic, expectedIdentifier string) (*PushDropFields, error) {
+	_, span := Tracer(ctx, nil).Start(ctx, "ParsePushDropOutput", trace.WithAttributes(
+		AttrProtocol.String(expectedIdentifier),
+		AttrTopic.String(payload.Topic),
+		AttrOutputIndex.Int(int(payload.OutputIndex)),
+	))
+	fields, err := parsePushDropOutput(payload, expectedTopic, expectedIdentifier)
+	if fields != nil {
+		span.SetAttributes(AttrTxid.String(fields.Txid))
+	}
+	EndSpan(span, err)
+	return fields, err
+}
+
+// parsePushDropOutput implements ParsePushDropOutput.
+func parsePushDropOutput(payload *engine.OutputAdmittedByTopic, expectedTopic, expectedIdentifier string) (*PushDropFields, error) {
 	// Only process the expected topic
 	if payload.Topic != expectedTopic {
 		return nil, nil //nolint:nilnil // nil,nil means silently skip
@@ -66,11 +92,18 @@ func ParsePushDropOutput(payload *engine.OutputAdmittedByTopic, expectedTopic, e
 		return nil, nil //nolint:nilnil // nil,nil means silently skip
 	}
 
+	token := &types.TokenMaterial{Fields: make([][]byte, len(result.Fields))}
+	copy(token.Fields, result.Fields)
+	if result.LockingPublicKey != nil {
+		token.LockingPublicKey = result.LockingPublicKey.ToDERHex()
+	}
+
 	return &PushDropFields{
 		IdentityKey: hex.EncodeToString(result.Fields[1]),
 		Domain:      string(result.Fields[2]),
 		FourthField: string(result.Fields[3]),
 		Txid:        hex.EncodeToString(tx.TxID().CloneBytes()),
 		OutputIndex: int(payload.OutputIndex),
+		Token:       token,
 	}, nil
 }
@@ -0,0 +1,203 @@
+package shared
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// UTXOSource lists the outputs the overlay engine currently holds unspent for a topic, and
+// looks up single outputs. engine.Storage implementations satisfy this interface.
+type UTXOSource interface {
+	FindUTXOsForTopic(ctx context.Context, topic string, since float64, limit uint32, includeBEEF bool) ([]*engine.Output, error)
+	FindOutput(ctx context.Context, outpoint *transaction.Outpoint, topic *string, spent *bool, includeBEEF bool) (*engine.Output, error)
+}
+
+// ReconcileReport describes the differences found between discovery storage and the
+// engine's UTXO set for a topic, and any repairs made.
+type ReconcileReport struct {
+	// Topic is the topic that was reconciled
+	Topic string `json:"topic"`
+	// EngineOutputs is the number of unspent outputs the engine holds for the topic
+	EngineOutputs int `json:"engineOutputs"`
+	// StoredRecords is the number of records in discovery storage
+	StoredRecords int `json:"storedRecords"`
+	// Orphans are stored records whose output the engine no longer holds
+	Orphans []types.UTXOReference `json:"orphans,omitempty"`
+	// Missing are engine outputs with no stored record
+	Missing []types.UTXOReference `json:"missing,omitempty"`
+	// RemovedOrphans is the number of orphaned records deleted during repair
+	RemovedOrphans int `json:"removedOrphans"`
+	// RestoredMissing is the number of missing records stored during repair
+	RestoredMissing int `json:"restoredMissing"`
+	// RepairErrors describes repairs that failed
+	RepairErrors []string `json:"repairErrors,omitempty"`
+}
+
+// Consistent reports whether storage and the engine agreed when the check ran.
+func (r ReconcileReport) Consistent() bool {
+	return len(r.Orphans) == 0 && len(r.Missing) == 0
+}
+
+// Reconcile compares the stored records against the unspent outputs the engine holds for
+// the lookup service's topic and reports orphans (stored but no longer held by the engine,
+// e.g. after a missed OutputSpent) and missing records (held by the engine but never stored,
+// e.g. after a crash between admittance and storage).
+//
+// The engine is listed before storage, so a record admitted between the two reads would look
+// orphaned; each candidate orphan is therefore looked up in the engine again and only reported
+// if the engine no longer holds it unspent.
+//
+// When repair is true, orphans are deleted (emitting removal events) and missing records are
+// parsed from the engine's BEEF and stored (emitting admitted events), unless a record was
+// stored for them in the meantime. Individual repair failures are collected in the report
+// rather than aborting the pass.
+func (b *BaseLookupService) Reconcile(ctx context.Context, source UTXOSource, repair bool) (ReconcileReport, error) {
+	report := ReconcileReport{Topic: b.Cfg.Topic}
+
+	outputs, err := source.FindUTXOsForTopic(ctx, b.Cfg.Topic, 0, 0, repair)
+	if err != nil {
+		return report, fmt.Errorf("failed to list engine outputs for %s: %w", b.Cfg.Topic, err)
+	}
+	report.EngineOutputs = len(outputs)
+
+	stored, err := b.Cfg.FindAll(ctx, nil, nil, nil)
+	if err != nil {
+		return report, fmt.Errorf("failed to list stored %s records: %w", b.Cfg.Identifier, err)
+	}
+	report.StoredRecords = len(stored)
+
+	engineOutputs := make(map[types.UTXOReference]*engine.Output, len(outputs))
+	for _, output := range outputs {
+		ref := types.UTXOReference{
+			Txid:        hex.EncodeToString(output.Outpoint.Txid[:]),
+			OutputIndex: int(output.Outpoint.Index),
+		}
+		engineOutputs[ref] = output
+	}
+
+	storedRecords := make(map[types.UTXOReference]struct{}, len(stored))
+	for _, ref := range stored {
+		storedRecords[ref] = struct{}{}
+		if _, ok := engineOutputs[ref]; ok {
+			continue
+		}
+		held, err := b.engineHolds(ctx, source, ref)
+		if err != nil {
+			return report, err
+		}
+		if !held {
+			report.Orphans = append(report.Orphans, ref)
+		}
+	}
+	for ref := range engineOutputs {
+		if _, ok := storedRecords[ref]; !ok {
+			report.Missing = append(report.Missing, ref)
+		}
+	}
+	sortUTXORefs(report.Orphans)
+	sortUTXORefs(report.Missing)
+
+	if !report.Consistent() {
+		Logger(ctx, b.logger).Warn("Discovery storage is inconsistent with engine",
+			LogKeyProtocol, b.Cfg.Identifier,
+			LogKeyTopic, b.Cfg.Topic,
+			"orphans", len(report.Orphans),
+			"missing", len(report.Missing),
+		)
+	}
+
+	if repair {
+		b.repairOrphans(ctx, &report)
+		b.repairMissing(ctx, &report, engineOutputs)
+	}
+
+	return report, nil
+}
+
+// engineHolds reports whether the engine currently holds the output unspent for the topic.
+func (b *BaseLookupService) engineHolds(ctx context.Context, source UTXOSource, ref types.UTXOReference) (bool, error) {
+	txid, err := hex.DecodeString(ref.Txid)
+	if err != nil || len(txid) != len(chainhash.Hash{}) {
+		// A record with a malformed txid cannot correspond to an engine output
+		return false, nil //nolint:nilerr // the record is reported as an orphan
+	}
+	outpoint := &transaction.Outpoint{Index: uint32(ref.OutputIndex)} //nolint:gosec // stored output indexes are non-negative
+	copy(outpoint.Txid[:], txid)
+
+	topic, spent := b.Cfg.Topic, false
+	output, err := source.FindOutput(ctx, outpoint, &topic, &spent, false)
+	if errors.Is(err, engine.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up engine output %s.%d: %w", ref.Txid, ref.OutputIndex, err)
+	}
+	return output != nil && !output.Spent, nil
+}
+
+// repairOrphans deletes every orphaned record in the report.
+func (b *BaseLookupService) repairOrphans(ctx context.Context, report *ReconcileReport) {
+	for _, ref := range report.Orphans {
+		if err := b.deleteRecord(ctx, ref.Txid, ref.OutputIndex); err != nil {
+			report.RepairErrors = append(report.RepairErrors, fmt.Sprintf("failed to delete orphan %s.%d: %v", ref.Txid, ref.OutputIndex, err))
+			continue
+		}
+		report.RemovedOrphans++
+	}
+}
+
+// repairMissing stores a record for every missing engine output in the report that still has
+// no record.
+func (b *BaseLookupService) repairMissing(ctx context.Context, report *ReconcileReport, engineOutputs map[types.UTXOReference]*engine.Output) {
+	for _, ref := range report.Missing {
+		if b.Cfg.FindEvent != nil {
+			existing, err := b.Cfg.FindEvent(ctx, ref.Txid, ref.OutputIndex)
+			if err != nil {
+				report.RepairErrors = append(report.RepairErrors, fmt.Sprintf("failed to restore %s.%d: %v", ref.Txid, ref.OutputIndex, err))
+				continue
+			}
+			if existing != nil {
+				continue
+			}
+		}
+
+		output := engineOutputs[ref]
+		if output.Beef == nil {
+			report.RepairErrors = append(report.RepairErrors, fmt.Sprintf("failed to restore %s.%d: engine returned no BEEF", ref.Txid, ref.OutputIndex))
+			continue
+		}
+
+		atomicBEEF, err := output.Beef.AtomicBytes(&output.Outpoint.Txid)
+		if err == nil {
+			err = b.OutputAdmittedByTopic(ctx, &engine.OutputAdmittedByTopic{
+				Topic:       b.Cfg.Topic,
+				OutputIndex: output.Outpoint.Index,
+				AtomicBEEF:  atomicBEEF,
+			})
+		}
+		if err != nil {
+			report.RepairErrors = append(report.RepairErrors, fmt.Sprintf("failed to restore %s.%d: %v", ref.Txid, ref.OutputIndex, err))
+			continue
+		}
+		report.RestoredMissing++
+	}
+}
+
+// sortUTXORefs orders references by txid and output index so reports are deterministic.
+func sortUTXORefs(refs []types.UTXOReference) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Txid != refs[j].Txid {
+			return refs[i].Txid < refs[j].Txid
+		}
+		return refs[i].OutputIndex < refs[j].OutputIndex
+	})
+}
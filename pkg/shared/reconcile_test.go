@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/internal/tokentest"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// fakeEngine is a UTXOSource listing a fixed snapshot of outputs. Outputs admitted after the
+// snapshot are only visible to FindOutput.
+type fakeEngine struct {
+	snapshot []*engine.Output
+	held     map[transaction.Outpoint]bool
+	// onFindOutput runs on every FindOutput call, to simulate concurrent admittance
+	onFindOutput func()
+}
+
+func (e *fakeEngine) FindUTXOsForTopic(context.Context, string, float64, uint32, bool) ([]*engine.Output, error) {
+	return e.snapshot, nil
+}
+
+func (e *fakeEngine) FindOutput(_ context.Context, outpoint *transaction.Outpoint, topic *string, _ *bool, _ bool) (*engine.Output, error) {
+	if e.onFindOutput != nil {
+		e.onFindOutput()
+	}
+	if e.held[*outpoint] {
+		return &engine.Output{Outpoint: *outpoint, Topic: *topic}, nil
+	}
+	return nil, engine.ErrNotFound
+}
+
+// outpointRef returns the storage reference of an outpoint.
+func outpointRef(txid *chainhash.Hash, index uint32) types.UTXOReference {
+	return types.UTXOReference{Txid: hex.EncodeToString(txid[:]), OutputIndex: int(index)}
+}
+
+func TestReconcile_RepairsStorage(t *testing.T) {
+	store := newFakeRecords()
+	service := store.lookupService()
+
+	beef, txid, identityKey := tokentest.SignedBEEF(t, overlay.ProtocolSHIP,
+		tokentest.Advertisement{Domain: "https://a.example.com", Name: "tm_a"},
+		tokentest.Advertisement{Domain: "https://b.example.com", Name: "tm_b"},
+		tokentest.Advertisement{Domain: "https://c.example.com", Name: "tm_c"},
+	)
+	inBoth, missing, admittedDuringRepair := outpointRef(txid, 0), outpointRef(txid, 1), outpointRef(txid, 2)
+
+	orphanHash := chainhash.Hash{0x01}
+	lateHash := chainhash.Hash{0x02}
+	orphan, late := outpointRef(&orphanHash, 0), outpointRef(&lateHash, 0)
+
+	store.put(inBoth.Txid, inBoth.OutputIndex, "tm_a")
+	store.put(orphan.Txid, orphan.OutputIndex, "tm_gone")
+	// Admitted by the engine after its snapshot was taken, so it is absent from the snapshot
+	store.put(late.Txid, late.OutputIndex, "tm_late")
+
+	source := &fakeEngine{held: map[transaction.Outpoint]bool{{Txid: lateHash, Index: 0}: true}}
+	for index := range uint32(3) {
+		source.snapshot = append(source.snapshot, &engine.Output{Outpoint: transaction.Outpoint{Txid: *txid, Index: index}, Topic: "tm_ship", Beef: beef})
+	}
+	// The third output is stored by a concurrent admittance while orphans are being checked
+	source.onFindOutput = func() {
+		store.put(admittedDuringRepair.Txid, admittedDuringRepair.OutputIndex, "tm_c")
+		store.stores[store.key(admittedDuringRepair.Txid, admittedDuringRepair.OutputIndex)] = 1
+	}
+
+	report, err := service.Reconcile(context.Background(), source, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []types.UTXOReference{orphan}, report.Orphans)
+	assert.ElementsMatch(t, []types.UTXOReference{missing, admittedDuringRepair}, report.Missing)
+	assert.Equal(t, 1, report.RemovedOrphans)
+	assert.Equal(t, 1, report.RestoredMissing)
+	assert.Empty(t, report.RepairErrors)
+
+	refs, err := service.FindAll(context.Background(), nil, nil, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []types.UTXOReference{inBoth, missing, admittedDuringRepair, late}, refs)
+
+	restored := store.records[store.key(missing.Txid, missing.OutputIndex)]
+	assert.Equal(t, identityKey, restored.IdentityKey)
+	assert.Equal(t, "https://b.example.com", restored.Domain)
+	assert.Equal(t, "tm_b", restored.Topic)
+	assert.Equal(t, 1, store.stores[store.key(admittedDuringRepair.Txid, admittedDuringRepair.OutputIndex)], "record stored concurrently must not be stored again")
+}
+
+func TestReconcile_ReportOnlyLeavesStorage(t *testing.T) {
+	store := newFakeRecords()
+	service := store.lookupService()
+	store.put(hex.EncodeToString(make([]byte, 32)), 0, "tm_gone")
+
+	report, err := service.Reconcile(context.Background(), &fakeEngine{}, false)
+	require.NoError(t, err)
+	assert.Len(t, report.Orphans, 1)
+	assert.Zero(t, report.RemovedOrphans)
+	assert.Len(t, store.records, 1)
+}
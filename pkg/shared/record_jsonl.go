@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Common error variables for record import.
+var (
+	ErrInvalidImportRecord = errors.New("invalid import record")
+)
+
+// importBatchSize is the number of records written to storage per call during import.
+const importBatchSize = 500
+
+// ForEachRecordFunc streams every stored record to fn, stopping at the first error.
+type ForEachRecordFunc[T any] func(ctx context.Context, fn func(T) error) error
+
+// ImportRecordsFunc writes a batch of complete records to storage, replacing existing ones.
+type ImportRecordsFunc[T any] func(ctx context.Context, records []T) error
+
+// ExportRecordsJSONL writes every record produced by forEach to w as line-delimited JSON,
+// one record per line, and returns the number of records written.
+func ExportRecordsJSONL[T any](ctx context.Context, w io.Writer, forEach ForEachRecordFunc[T]) (int, error) {
+	encoder := json.NewEncoder(w)
+	count := 0
+	err := forEach(ctx, func(record T) error {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write record %d: %w", count+1, err)
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// ImportRecordsJSONL reads line-delimited JSON records from r and writes them to storage in
+// batches, so the whole file is never held in memory. validate is called for every record
+// before it is written (optional). It returns the number of records imported; records in a
+// batch that failed to write are not counted.
+func ImportRecordsJSONL[T any](ctx context.Context, r io.Reader, importBatch ImportRecordsFunc[T], validate func(T) error) (int, error) {
+	decoder := json.NewDecoder(r)
+	batch := make([]T, 0, importBatchSize)
+	imported := 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := importBatch(ctx, batch); err != nil {
+			return err
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return imported, err
+		}
+
+		var record T
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return imported, fmt.Errorf("%w: record %d: %w", ErrInvalidImportRecord, line, err)
+		}
+		if validate != nil {
+			if err := validate(record); err != nil {
+				return imported, fmt.Errorf("%w: record %d: %w", ErrInvalidImportRecord, line, err)
+			}
+		}
+
+		batch = append(batch, record)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return imported, err
+			}
+		}
+	}
+
+	return imported, flush()
+}
@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// ReindexRejection describes an archived output that failed admittance during a reindex.
+type ReindexRejection struct {
+	// Txid is the transaction containing the rejected output
+	Txid string `json:"txid"`
+	OutputRejection
+}
+
+// ReindexReport summarizes a reindex pass over an archive.
+type ReindexReport struct {
+	// Transactions is the number of archive entries read
+	Transactions int `json:"transactions"`
+	// Stored is the number of records written to storage
+	Stored int `json:"stored"`
+	// AlreadyStored is the number of admissible outputs that already had a record
+	AlreadyStored int `json:"alreadyStored"`
+	// Rejections lists the archived outputs that failed admittance
+	Rejections []ReindexRejection `json:"rejections,omitempty"`
+	// Errors describes archive entries or outputs that could not be processed
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Reindex rebuilds storage from an archive of Atomic BEEF. Each entry is checked with the same
+// validation the topic manager applies (IdentifyAdmissibleOutputs with the given admittance
+// configuration) and each admissible output is parsed with ParsePushDropOutput and stored.
+//
+// Reindexing is idempotent: outputs that already have a record are left untouched, so an
+// interrupted pass can be rerun. Malformed entries and rejected outputs are collected in the
+// report; reading the archive or storage failing aborts the pass. The admittance policy is
+// applied, except that a RateLimitPolicy, alone or chained, neither limits nor counts the
+// archived outputs, which were admitted when first submitted.
+func (b *BaseLookupService) Reindex(ctx context.Context, source ReindexSource, admittance AdmittanceConfig) (ReindexReport, error) {
+	var report ReindexReport
+	ctx = withoutRateLimit(ctx)
+
+	for {
+		entry, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if errors.Is(err, ErrInvalidReindexEntry) {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to read reindex archive: %w", err)
+		}
+
+		report.Transactions++
+		if err := b.reindexEntry(ctx, entry, admittance, &report); err != nil {
+			return report, err
+		}
+	}
+}
+
+// reindexEntry validates and stores the requested outputs of a single archive entry.
+func (b *BaseLookupService) reindexEntry(ctx context.Context, entry *ReindexEntry, admittance AdmittanceConfig, report *ReindexReport) error {
+	beef, txid, err := transaction.NewBeefFromAtomicBytes(entry.AtomicBEEF)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: invalid atomic BEEF: %v", entry.Source, err))
+		return nil
+	}
+
+	result, err := IdentifyAdmissibleOutputsWithReport(ctx, beef, txid, nil, admittance)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: failed to identify admissible outputs: %v", entry.Source, err))
+		return nil
+	}
+	if !result.TransactionFound {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: transaction %s not found in BEEF", entry.Source, txid))
+		return nil
+	}
+
+	admitted := make(map[uint32]bool, len(result.Instructions.OutputsToAdmit))
+	for _, index := range result.Instructions.OutputsToAdmit {
+		admitted[index] = true
+	}
+	rejected := make(map[uint32]OutputRejection, len(result.Rejections))
+	for _, rejection := range result.Rejections {
+		rejected[rejection.OutputIndex] = rejection
+	}
+
+	indexes := entry.OutputIndexes
+	if len(indexes) == 0 {
+		indexes = make([]uint32, 0, len(admitted)+len(rejected))
+		indexes = append(indexes, result.Instructions.OutputsToAdmit...)
+		for _, rejection := range result.Rejections {
+			indexes = append(indexes, rejection.OutputIndex)
+		}
+	}
+
+	for _, index := range indexes {
+		if !admitted[index] {
+			if rejection, ok := rejected[index]; ok {
+				report.Rejections = append(report.Rejections, ReindexRejection{Txid: result.Txid, OutputRejection: rejection})
+			} else {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: output %d not found in transaction %s", entry.Source, index, txid))
+			}
+			continue
+		}
+
+		if err := b.reindexOutput(ctx, entry, index, report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reindexOutput parses an admissible output and stores it unless a record already exists.
+func (b *BaseLookupService) reindexOutput(ctx context.Context, entry *ReindexEntry, index uint32, report *ReindexReport) error {
+	fields, err := ParsePushDropOutputContext(ctx, &engine.OutputAdmittedByTopic{
+		Topic:       b.Cfg.Topic,
+		OutputIndex: index,
+		AtomicBEEF:  entry.AtomicBEEF,
+	}, b.Cfg.Topic, b.Cfg.Identifier)
+	if err != nil || fields == nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: failed to parse output %d: %v", entry.Source, index, err))
+		return nil
+	}
+
+	if b.Cfg.FindEvent != nil {
+		existing, err := b.Cfg.FindEvent(ctx, fields.Txid, fields.OutputIndex)
+		if err != nil {
+			return fmt.Errorf("failed to look up %s.%d: %w", fields.Txid, fields.OutputIndex, err)
+		}
+		if existing != nil {
+			report.AlreadyStored++
+			return nil
+		}
+	}
+
+	if err := b.storeRecord(ctx, fields); err != nil {
+		return fmt.Errorf("failed to store %s.%d: %w", fields.Txid, fields.OutputIndex, err)
+	}
+	report.Stored++
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package shared
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Common error variables for reindex archives.
+var (
+	ErrInvalidReindexEntry = errors.New("invalid reindex entry")
+)
+
+// ReindexEntry is one transaction read from a reindex archive.
+type ReindexEntry struct {
+	// Source identifies where the entry was read from, for reporting
+	Source string
+	// AtomicBEEF is the Atomic BEEF of the transaction
+	AtomicBEEF []byte
+	// OutputIndexes are the outputs to reindex; empty means every output
+	OutputIndexes []uint32
+}
+
+// ReindexSource yields the entries of a reindex archive in order.
+type ReindexSource interface {
+	// Next returns the next entry, or io.EOF when the archive is exhausted.
+	Next(ctx context.Context) (*ReindexEntry, error)
+}
+
+// ReindexRecord is the JSON form of a reindex entry used by stream archives.
+type ReindexRecord struct {
+	// BEEF is the hex-encoded Atomic BEEF of the transaction
+	BEEF string `json:"beef"`
+	// OutputIndexes are the outputs to reindex; omitted means every output
+	OutputIndexes []uint32 `json:"outputIndexes,omitempty"`
+}
+
+// MaxReindexLineSize is the longest line a StreamReindexSource accepts. Longer lines abort the
+// read, as the rest of the line cannot be skipped reliably.
+const MaxReindexLineSize = 64 << 20
+
+// StreamReindexSource reads a stream of ReindexRecord JSON values, one per line. Blank lines are
+// ignored, and a line that is not a valid record is reported as ErrInvalidReindexEntry and skipped.
+type StreamReindexSource struct {
+	name    string
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewStreamReindexSource creates a source reading ReindexRecord JSON lines from r.
+// The name is used to label entries in reports.
+func NewStreamReindexSource(name string, r io.Reader) *StreamReindexSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, MaxReindexLineSize)
+	return &StreamReindexSource{name: name, scanner: scanner}
+}
+
+// Next implements ReindexSource.
+func (s *StreamReindexSource) Next(ctx context.Context) (*ReindexEntry, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !s.scanner.Scan() {
+			if err := s.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read %s after line %d: %w", s.name, s.line, err)
+			}
+			return nil, io.EOF
+		}
+		s.line++
+
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record ReindexRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("%w: %s line %d: %w", ErrInvalidReindexEntry, s.name, s.line, err)
+		}
+
+		beef, err := hex.DecodeString(record.BEEF)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s line %d: beef is not hex: %w", ErrInvalidReindexEntry, s.name, s.line, err)
+		}
+
+		return &ReindexEntry{
+			Source:        fmt.Sprintf("%s line %d", s.name, s.line),
+			AtomicBEEF:    beef,
+			OutputIndexes: record.OutputIndexes,
+		}, nil
+	}
+}
+
+// DirReindexSource walks a directory of reindex archives in lexical path order.
+// Files ending in ".beef" hold raw Atomic BEEF and are reindexed for every output;
+// files ending in ".jsonl" or ".json" are read as ReindexRecord JSON lines. Other files are ignored.
+type DirReindexSource struct {
+	paths   []string
+	current *StreamReindexSource
+	file    *os.File
+}
+
+// NewDirReindexSource creates a source over the archives found under dir.
+func NewDirReindexSource(dir string) (*DirReindexSource, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".beef", ".jsonl", ".json":
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk reindex directory %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	return &DirReindexSource{paths: paths}, nil
+}
+
+// Next implements ReindexSource.
+func (s *DirReindexSource) Next(ctx context.Context) (*ReindexEntry, error) {
+	for {
+		if s.current != nil {
+			entry, err := s.current.Next(ctx)
+			if !errors.Is(err, io.EOF) {
+				return entry, err
+			}
+			if err := s.closeFile(); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(s.paths) == 0 {
+			return nil, io.EOF
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		path := s.paths[0]
+		s.paths = s.paths[1:]
+
+		if strings.EqualFold(filepath.Ext(path), ".beef") {
+			beef, err := os.ReadFile(path) //nolint:gosec // path comes from walking the operator-supplied directory
+			if err != nil {
+				return nil, fmt.Errorf("failed to read reindex file %s: %w", path, err)
+			}
+			return &ReindexEntry{Source: path, AtomicBEEF: beef}, nil
+		}
+
+		file, err := os.Open(path) //nolint:gosec // path comes from walking the operator-supplied directory
+		if err != nil {
+			return nil, fmt.Errorf("failed to open reindex file %s: %w", path, err)
+		}
+		s.file = file
+		s.current = NewStreamReindexSource(path, file)
+	}
+}
+
+// Close releases the file currently being read, if any.
+func (s *DirReindexSource) Close() error {
+	return s.closeFile()
+}
+
+// closeFile closes the stream archive currently being read.
+func (s *DirReindexSource) closeFile() error {
+	s.current = nil
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,106 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestRead = errors.New("read failed")
+
+func TestStreamReindexSource_SkipsMalformedLines(t *testing.T) {
+	archive := strings.Join([]string{
+		`{"beef":"0102","outputIndexes":[1]}`,
+		`{"beef": "truncated`,
+		``,
+		`{"beef":"zz"}`,
+		`  {"beef":"0304"}  `,
+	}, "\n")
+	source := NewStreamReindexSource("archive", strings.NewReader(archive))
+	ctx := context.Background()
+
+	entry, err := source.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "archive line 1", entry.Source)
+	assert.Equal(t, []byte{0x01, 0x02}, entry.AtomicBEEF)
+	assert.Equal(t, []uint32{1}, entry.OutputIndexes)
+
+	_, err = source.Next(ctx)
+	require.ErrorIs(t, err, ErrInvalidReindexEntry)
+	assert.Contains(t, err.Error(), "archive line 2")
+
+	_, err = source.Next(ctx)
+	require.ErrorIs(t, err, ErrInvalidReindexEntry)
+	assert.Contains(t, err.Error(), "archive line 4: beef is not hex")
+
+	entry, err = source.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "archive line 5", entry.Source)
+	assert.Equal(t, []byte{0x03, 0x04}, entry.AtomicBEEF)
+
+	_, err = source.Next(ctx)
+	require.ErrorIs(t, err, io.EOF)
+	_, err = source.Next(ctx)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestStreamReindexSource_ReadError(t *testing.T) {
+	source := NewStreamReindexSource("archive", iotest.ErrReader(errTestRead))
+
+	_, err := source.Next(context.Background())
+	require.ErrorIs(t, err, errTestRead)
+	assert.NotErrorIs(t, err, ErrInvalidReindexEntry)
+}
+
+func TestStreamReindexSource_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewStreamReindexSource("archive", strings.NewReader(`{"beef":"01"}`)).Next(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDirReindexSource_WalksArchivesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "b"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.beef"), []byte{0x0a}, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b", "c.jsonl"), []byte("{\"beef\":\"0b\"}\nnot json\n{\"beef\":\"0c\"}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+
+	source, err := NewDirReindexSource(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = source.Close() })
+	ctx := context.Background()
+
+	entry, err := source.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0a}, entry.AtomicBEEF)
+
+	entry, err = source.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0b}, entry.AtomicBEEF)
+
+	_, err = source.Next(ctx)
+	require.ErrorIs(t, err, ErrInvalidReindexEntry)
+
+	entry, err = source.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0c}, entry.AtomicBEEF)
+	assert.Contains(t, entry.Source, "c.jsonl line 3")
+
+	_, err = source.Next(ctx)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestNewDirReindexSource_MissingDirectory(t *testing.T) {
+	_, err := NewDirReindexSource(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
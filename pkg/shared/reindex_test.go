@@ -0,0 +1,142 @@
+package shared
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/internal/tokentest"
+)
+
+// erroringSource fails with err on the first read.
+type erroringSource struct{ err error }
+
+func (s erroringSource) Next(context.Context) (*ReindexEntry, error) {
+	return nil, s.err
+}
+
+func TestReindex_StreamArchive(t *testing.T) {
+	store := newFakeRecords()
+	service := store.lookupService()
+
+	beef, txid, identityKey := tokentest.SignedBEEF(t, overlay.ProtocolSHIP,
+		tokentest.Advertisement{Domain: "https://example.com", Name: "tm_bridge"},
+		tokentest.Advertisement{Domain: "https://example.com", Name: "not a topic"},
+	)
+	atomicBEEF, err := beef.AtomicBytes(txid)
+	require.NoError(t, err)
+	txidHex := hex.EncodeToString(txid.CloneBytes())
+
+	archive := strings.Join([]string{
+		fmt.Sprintf(`{"beef":%q}`, hex.EncodeToString(atomicBEEF)),
+		`{"beef": "truncated`,
+		`{"beef":"00ff"}`,
+		fmt.Sprintf(`{"beef":%q,"outputIndexes":[0,7]}`, hex.EncodeToString(atomicBEEF)),
+	}, "\n")
+
+	report, err := service.Reindex(context.Background(), NewStreamReindexSource("archive", strings.NewReader(archive)), shipAdmittance())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Transactions)
+	assert.Equal(t, 1, report.Stored)
+	assert.Equal(t, 1, report.AlreadyStored)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, uint32(1), report.Rejections[0].OutputIndex)
+	assert.Equal(t, RejectionInvalidTopic, report.Rejections[0].Reason)
+	require.Len(t, report.Errors, 3)
+	assert.Contains(t, report.Errors[0], "archive line 2")
+	assert.Contains(t, report.Errors[1], "archive line 3: invalid atomic BEEF")
+	assert.Contains(t, report.Errors[2], "output 7 not found")
+
+	require.Len(t, store.records, 1)
+	record := store.records[store.key(txidHex, 0)]
+	assert.Equal(t, identityKey, record.IdentityKey)
+	assert.Equal(t, "tm_bridge", record.Topic)
+}
+
+func TestReindex_DirArchive(t *testing.T) {
+	store := newFakeRecords()
+	service := store.lookupService()
+
+	dir := t.TempDir()
+	atomicBEEF := tokentest.SignedAtomicBEEF(t, overlay.ProtocolSHIP, tokentest.Advertisement{Domain: "https://example.com", Name: "tm_bridge"})
+	writeFile(t, dir, "1.beef", atomicBEEF)
+	writeFile(t, dir, "2.jsonl", []byte("not json\n"))
+
+	source, err := NewDirReindexSource(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = source.Close() })
+
+	report, err := service.Reindex(context.Background(), source, shipAdmittance())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Transactions)
+	assert.Equal(t, 1, report.Stored)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "2.jsonl line 1")
+	assert.Len(t, store.records, 1)
+}
+
+func TestReindex_BypassesRateLimit(t *testing.T) {
+	store := newFakeRecords()
+	service := store.lookupService()
+
+	beef, txid, identityKey := tokentest.SignedBEEF(t, overlay.ProtocolSHIP,
+		tokentest.Advertisement{Domain: "https://example.com", Name: "tm_bridge"},
+		tokentest.Advertisement{Domain: "https://example.com", Name: "tm_meter"},
+	)
+	atomicBEEF, err := beef.AtomicBytes(txid)
+	require.NoError(t, err)
+	archive := fmt.Sprintf(`{"beef":%q}`, hex.EncodeToString(atomicBEEF))
+
+	rateLimit, err := NewRateLimitPolicy(1, time.Hour, nil)
+	require.NoError(t, err)
+	admittance := shipAdmittance()
+	admittance.Policy = ChainAdmittancePolicies(NewTopicPolicy(nil, []string{"tm_denied"}), rateLimit)
+
+	report, err := service.Reindex(context.Background(), NewStreamReindexSource("archive", strings.NewReader(archive)), admittance)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Stored)
+	assert.Empty(t, report.Rejections)
+
+	// Reindexed outputs do not count towards the limit of new submissions
+	require.NoError(t, rateLimit.Admit(context.Background(), AdmittanceCandidate{IdentityKey: identityKey, Txid: "new"}))
+}
+
+func TestReindex_ReadErrorAborts(t *testing.T) {
+	service := newFakeRecords().lookupService()
+
+	_, err := service.Reindex(context.Background(), erroringSource{err: errTestRead}, shipAdmittance())
+	require.ErrorIs(t, err, errTestRead)
+
+	_, err = service.Reindex(context.Background(), erroringSource{err: io.EOF}, shipAdmittance())
+	require.NoError(t, err)
+}
+
+func TestReindex_StoreErrorAborts(t *testing.T) {
+	store := newFakeRecords()
+	store.storeErr = errTestStorage
+	service := store.lookupService()
+
+	atomicBEEF := tokentest.SignedAtomicBEEF(t, overlay.ProtocolSHIP, tokentest.Advertisement{Domain: "https://example.com", Name: "tm_bridge"})
+	archive := fmt.Sprintf(`{"beef":%q}`, hex.EncodeToString(atomicBEEF))
+
+	report, err := service.Reindex(context.Background(), NewStreamReindexSource("archive", strings.NewReader(archive)), shipAdmittance())
+	require.ErrorIs(t, err, errTestStorage)
+	assert.Equal(t, 0, report.Stored)
+}
+
+// writeFile writes an archive file into dir.
+func writeFile(t *testing.T, dir, name string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
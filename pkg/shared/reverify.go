@@ -0,0 +1,183 @@
+package shared
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
+)
+
+// Common error variables for token re-verification.
+var (
+	ErrTokenFieldCount     = errors.New("stored token does not have 5 fields")
+	ErrTokenFieldMismatch  = errors.New("stored token field does not match record")
+	ErrTokenSignatureCheck = errors.New("stored token signature could not be checked")
+	ErrTokenSignatureLink  = errors.New("stored token signature is not linked to the identity key")
+)
+
+// reverifyPageSize is the number of records loaded per storage call during re-verification.
+const reverifyPageSize = 500
+
+// StoredToken is a stored record together with the token material it was parsed from.
+type StoredToken struct {
+	// Txid is the transaction ID of the record's output
+	Txid string
+	// OutputIndex is the index of the record's output
+	OutputIndex int
+	// IdentityKey is the identity key stored on the record
+	IdentityKey string
+	// Domain is the domain stored on the record
+	Domain string
+	// FourthField is the topic (SHIP) or service (SLAP) stored on the record
+	FourthField string
+	// Token is the raw token material, or nil if it was not kept
+	Token *types.TokenMaterial
+}
+
+// ListStoredTokensFunc returns a page of stored records in a stable order.
+type ListStoredTokensFunc func(ctx context.Context, limit, skip *int) ([]StoredToken, error)
+
+// ReverifyMismatch identifies a stored record whose token failed re-verification.
+type ReverifyMismatch struct {
+	// Txid is the transaction ID of the record's output
+	Txid string `json:"txid"`
+	// OutputIndex is the index of the record's output
+	OutputIndex int `json:"outputIndex"`
+	// Reason describes why re-verification failed
+	Reason string `json:"reason"`
+}
+
+// ReverifyReport summarizes a re-verification pass over stored records.
+type ReverifyReport struct {
+	// Checked is the number of records whose token material was verified
+	Checked int `json:"checked"`
+	// Skipped is the number of records stored without token material
+	Skipped int `json:"skipped"`
+	// Mismatches lists every record whose token failed verification
+	Mismatches []ReverifyMismatch `json:"mismatches,omitempty"`
+}
+
+// ReverifyConfig describes how stored token material is re-verified.
+type ReverifyConfig struct {
+	// Identifier is the protocol identifier the tokens must carry (e.g. "SHIP")
+	Identifier string
+	// Verifier checks token signature linkage (optional). When nil, Reverify builds the
+	// verifier used for admittance once per pass.
+	Verifier *utils.TokenVerifier
+	// SignatureCache caches signature verification results (optional)
+	SignatureCache *SignatureCache
+}
+
+// VerifyStoredToken checks that a record's token material carries the expected protocol
+// identifier, matches the record's identity key, domain and topic or service, and has a
+// signature correctly linked to the identity key. Signature results are read from and added
+// to cfg.SignatureCache when set.
+func VerifyStoredToken(ctx context.Context, cfg ReverifyConfig, stored StoredToken) error {
+	fields := stored.Token.Fields
+	if len(fields) != 5 {
+		return fmt.Errorf("%w: got %d", ErrTokenFieldCount, len(fields))
+	}
+
+	expected := []struct {
+		name   string
+		stored string
+		actual string
+	}{
+		{"protocol", cfg.Identifier, string(fields[0])},
+		{"identity key", stored.IdentityKey, hex.EncodeToString(fields[1])},
+		// Domains are stored in canonical form
+		{"domain", utils.CanonicalDomain(stored.Domain), utils.CanonicalDomain(string(fields[2]))},
+		{"topic or service", stored.FourthField, string(fields[3])},
+	}
+	for _, field := range expected {
+		if field.stored != field.actual {
+			return fmt.Errorf("%w: %s is %q, token has %q", ErrTokenFieldMismatch, field.name, field.stored, field.actual)
+		}
+	}
+
+	result := verifyStoredSignature(ctx, cfg, stored.Token)
+	if result.err != nil {
+		return fmt.Errorf("%w: %w", ErrTokenSignatureCheck, result.err)
+	}
+	if !result.valid {
+		return ErrTokenSignatureLink
+	}
+
+	return nil
+}
+
+// verifyStoredSignature checks the signature linkage of stored token material, answering from
+// the signature cache where possible.
+func verifyStoredSignature(ctx context.Context, cfg ReverifyConfig, token *types.TokenMaterial) signatureResult {
+	key := tokenHash(token.LockingPublicKey, token.Fields)
+	if cfg.SignatureCache != nil {
+		if result, ok := cfg.SignatureCache.get(key); ok {
+			return result
+		}
+	}
+
+	verifier := cfg.Verifier
+	if verifier == nil {
+		verifier = newAdvertisementVerifier()
+	}
+	tokenFields := make(utils.TokenFields, len(token.Fields))
+	copy(tokenFields, token.Fields)
+	valid, err := verifier.Verify(ctx, token.LockingPublicKey, tokenFields)
+	result := signatureResult{valid: valid, err: err}
+
+	// Cancellation is not a property of the token, so it must not be cached
+	if cfg.SignatureCache != nil && ctx.Err() == nil {
+		cfg.SignatureCache.add(key, result)
+	}
+	return result
+}
+
+// Reverify re-runs signature verification over every stored record that kept its token
+// material, paging through storage with list. Records that fail are reported, and logged to
+// the logger carried by ctx (see ContextWithLogger), but left in storage. Records added or
+// removed while the pass runs may be missed or seen twice.
+func Reverify(ctx context.Context, cfg ReverifyConfig, list ListStoredTokensFunc) (ReverifyReport, error) {
+	var report ReverifyReport
+	if cfg.Verifier == nil {
+		// Build the verifier once rather than for every record
+		cfg.Verifier = newAdvertisementVerifier()
+	}
+	identifier := cfg.Identifier
+
+	limit := reverifyPageSize
+	for skip := 0; ; skip += limit {
+		page, err := list(ctx, &limit, &skip)
+		if err != nil {
+			return report, fmt.Errorf("failed to list %s records: %w", identifier, err)
+		}
+
+		for _, stored := range page {
+			if stored.Token == nil {
+				report.Skipped++
+				continue
+			}
+
+			report.Checked++
+			if err := VerifyStoredToken(ctx, cfg, stored); err != nil {
+				Logger(ctx, nil).Warn("Stored token failed re-verification",
+					LogKeyProtocol, identifier,
+					LogKeyTxid, stored.Txid,
+					LogKeyOutputIndex, stored.OutputIndex,
+					LogKeyReason, err,
+				)
+				report.Mismatches = append(report.Mismatches, ReverifyMismatch{
+					Txid:        stored.Txid,
+					OutputIndex: stored.OutputIndex,
+					Reason:      err.Error(),
+				})
+			}
+		}
+
+		if len(page) < limit {
+			return report, nil
+		}
+	}
+}
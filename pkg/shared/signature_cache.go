@@ -0,0 +1,123 @@
+package shared
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+)
+
+// DefaultSignatureCacheSize is a reasonable number of verification results to retain.
+const DefaultSignatureCacheSize = 10000
+
+// signatureResult is the outcome of verifying one token's signature linkage.
+type signatureResult struct {
+	valid bool
+	err   error
+}
+
+// signatureCacheEntry is a cached result together with its key, for eviction.
+type signatureCacheEntry struct {
+	key    [sha256.Size]byte
+	result signatureResult
+}
+
+// SignatureCacheStats is a snapshot of signature cache activity.
+type SignatureCacheStats struct {
+	// Hits is the number of lookups answered from the cache
+	Hits uint64 `json:"hits"`
+	// Misses is the number of lookups that required verification
+	Misses uint64 `json:"misses"`
+	// Size is the number of results currently cached
+	Size int `json:"size"`
+}
+
+// SignatureCache is a bounded, least-recently-used cache of token signature verification
+// results keyed by the SHA-256 hash of the output's locking script. The locking script
+// contains every token field and the locking key, so the result is fully determined by it.
+// Stored records, which keep the token material rather than the script, are keyed by a hash
+// of the locking key and fields instead. It is safe for concurrent use.
+type SignatureCache struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List
+	hits    uint64
+	misses  uint64
+}
+
+// NewSignatureCache creates a cache retaining at most size results.
+// A size of zero or less uses DefaultSignatureCacheSize.
+func NewSignatureCache(size int) *SignatureCache {
+	if size <= 0 {
+		size = DefaultSignatureCacheSize
+	}
+	return &SignatureCache{
+		size:    size,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// ScriptHash returns the cache key for a locking script.
+func ScriptHash(lockingScript []byte) [sha256.Size]byte {
+	return sha256.Sum256(lockingScript)
+}
+
+// tokenHash returns the cache key for token material held without its locking script, as
+// stored records keep it. Every value is length-prefixed so distinct tokens never share a key.
+func tokenHash(lockingPublicKey string, fields [][]byte) [sha256.Size]byte {
+	hash := sha256.New()
+	// Separates these keys from those returned by ScriptHash
+	hash.Write([]byte("token"))
+	for _, value := range append([][]byte{[]byte(lockingPublicKey)}, fields...) {
+		hash.Write(binary.AppendUvarint(nil, uint64(len(value))))
+		hash.Write(value)
+	}
+
+	var key [sha256.Size]byte
+	hash.Sum(key[:0])
+	return key
+}
+
+// get returns the cached result for a script hash, marking it as recently used.
+func (c *SignatureCache) get(key [sha256.Size]byte) (signatureResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return signatureResult{}, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*signatureCacheEntry).result, true
+}
+
+// add stores the result for a script hash, evicting the least recently used entry when full.
+func (c *SignatureCache) add(key [sha256.Size]byte, result signatureResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*signatureCacheEntry).result = result
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&signatureCacheEntry{key: key, result: result})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*signatureCacheEntry).key)
+	}
+}
+
+// Stats returns a snapshot of the cache's hit and miss counts and current size.
+func (c *SignatureCache) Stats() SignatureCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return SignatureCacheStats{Hits: c.hits, Misses: c.misses, Size: c.order.Len()}
+}
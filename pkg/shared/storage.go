@@ -3,6 +3,7 @@ package shared
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,8 +11,20 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
+// Common error variables for storage capabilities.
+var (
+	// ErrStorageUnsupported reports an operation that the underlying storage does not implement
+	ErrStorageUnsupported = errors.New("operation is not supported by the storage")
+)
+
+// UnsupportedStorageError returns an error wrapping ErrStorageUnsupported for the named operation.
+func UnsupportedStorageError(operation string) error {
+	return fmt.Errorf("%w: %s", ErrStorageUnsupported, operation)
+}
+
 // UTXOProjection returns the standard projection for returning UTXO references.
 func UTXOProjection() bson.M {
 	return bson.M{
@@ -40,6 +53,14 @@ func ApplyPaginationOpts(findOpts *options.FindOptions, sortOrder *types.SortOrd
 	}
 }
 
+// CloseCursor closes a mongo cursor, logging a failure to the logger carried by ctx (see
+// ContextWithLogger) since the results have already been read or the query has failed.
+func CloseCursor(ctx context.Context, cursor *mongo.Cursor, recordType string) {
+	if err := cursor.Close(ctx); err != nil {
+		Logger(ctx, nil).Debug("Failed to close cursor", "recordType", recordType, LogKeyError, err)
+	}
+}
+
 // CollectUTXORefs iterates a mongo cursor and collects UTXO references.
 func CollectUTXORefs(ctx context.Context, cursor *mongo.Cursor, recordType string) ([]types.UTXOReference, error) {
 	var results []types.UTXOReference
@@ -66,6 +87,26 @@ func CollectUTXORefs(ctx context.Context, cursor *mongo.Cursor, recordType strin
 	return results, nil
 }
 
+// QueryRecords returns the full records matching filter, ordered and paginated as by
+// ApplyPaginationOpts, in a single query.
+func QueryRecords[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, sortOrder *types.SortOrder, skip, limit *int, recordType string) ([]T, error) {
+	findOpts := options.Find()
+	ApplyPaginationOpts(findOpts, sortOrder, skip, limit)
+
+	cursor, err := collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s records: %w", recordType, err)
+	}
+	defer CloseCursor(ctx, cursor, recordType)
+
+	records := []T{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode %s records: %w", recordType, err)
+	}
+
+	return records, nil
+}
+
 // FindAllRecords executes a find-all query with pagination on the given collection.
 func FindAllRecords(ctx context.Context, collection *mongo.Collection, limit, skip *int, sortOrder *types.SortOrder, recordType string) ([]types.UTXOReference, error) {
 	findOpts := options.Find()
@@ -76,9 +117,170 @@ func FindAllRecords(ctx context.Context, collection *mongo.Collection, limit, sk
 	if err != nil {
 		return nil, fmt.Errorf("failed to find all %s records: %w", recordType, err)
 	}
-	defer func() {
-		_ = cursor.Close(ctx)
-	}()
+	defer CloseCursor(ctx, cursor, recordType)
 
 	return CollectUTXORefs(ctx, cursor, recordType)
 }
+
+// ListRecords returns full records from the collection, oldest first, with pagination.
+// Records are ordered by creation time and then insertion order so pages are stable.
+func ListRecords[T any](ctx context.Context, collection *mongo.Collection, limit, skip *int, recordType string) ([]T, error) {
+	findOpts := options.Find()
+	findOpts.SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	if skip != nil && *skip > 0 {
+		findOpts.SetSkip(int64(*skip))
+	}
+	if limit != nil && *limit > 0 {
+		findOpts.SetLimit(int64(*limit))
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{}, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s records: %w", recordType, err)
+	}
+	defer CloseCursor(ctx, cursor, recordType)
+
+	var records []T
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode %s records: %w", recordType, err)
+	}
+
+	return records, nil
+}
+
+// ForEachRecord streams every record in the collection to fn, oldest first, decoding one
+// record at a time so large collections are not loaded into memory. Iteration stops at the
+// first error returned by fn.
+func ForEachRecord[T any](ctx context.Context, collection *mongo.Collection, recordType string, fn func(T) error) error {
+	findOpts := options.Find()
+	findOpts.SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := collection.Find(ctx, bson.M{}, findOpts)
+	if err != nil {
+		return fmt.Errorf("failed to iterate %s records: %w", recordType, err)
+	}
+	defer CloseCursor(ctx, cursor, recordType)
+
+	for cursor.Next(ctx) {
+		var record T
+		if err := cursor.Decode(&record); err != nil {
+			return fmt.Errorf("failed to decode %s record: %w", recordType, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("cursor error while iterating %s records: %w", recordType, err)
+	}
+
+	return nil
+}
+
+// CountRecordsBy returns the number of records in the collection for each value of field,
+// counted by the server in a single aggregation.
+func CountRecordsBy(ctx context.Context, collection *mongo.Collection, field, recordType string) (map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + field},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s records: %w", recordType, err)
+	}
+	defer CloseCursor(ctx, cursor, recordType)
+
+	var groups []struct {
+		Value string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode %s record counts: %w", recordType, err)
+	}
+
+	counts := make(map[string]int, len(groups))
+	for _, group := range groups {
+		counts[group.Value] = group.Count
+	}
+	return counts, nil
+}
+
+// UpsertRecords writes complete records to the collection in one unordered bulk operation,
+// replacing any existing record with the same outpoint. key returns the outpoint filter for a record.
+func UpsertRecords[T any](ctx context.Context, collection *mongo.Collection, records []T, key func(T) bson.M, recordType string) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, len(records))
+	for i, record := range records {
+		models[i] = mongo.NewReplaceOneModel().SetFilter(key(record)).SetReplacement(record).SetUpsert(true)
+	}
+
+	if _, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to import %s records: %w", recordType, err)
+	}
+
+	return nil
+}
+
+// domainMigrationBatchSize is the number of record updates sent per bulk write.
+const domainMigrationBatchSize = 500
+
+// CanonicalizeDomains rewrites the domain of every record in the collection whose stored
+// domain is not in the form returned by utils.CanonicalDomain, migrating records stored before
+// domains were canonicalized. It returns the number of records updated and is safe to rerun.
+func CanonicalizeDomains(ctx context.Context, collection *mongo.Collection, recordType string) (int, error) {
+	findOpts := options.Find().SetProjection(bson.M{"domain": 1})
+	cursor, err := collection.Find(ctx, bson.M{}, findOpts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to iterate %s records: %w", recordType, err)
+	}
+	defer CloseCursor(ctx, cursor, recordType)
+
+	updated := 0
+	models := make([]mongo.WriteModel, 0, domainMigrationBatchSize)
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		if _, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to canonicalize %s domains: %w", recordType, err)
+		}
+		updated += len(models)
+		models = models[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var record struct {
+			ID     any    `bson:"_id"`
+			Domain string `bson:"domain"`
+		}
+		if err := cursor.Decode(&record); err != nil {
+			return updated, fmt.Errorf("failed to decode %s record: %w", recordType, err)
+		}
+
+		canonical := utils.CanonicalDomain(record.Domain)
+		if canonical == record.Domain {
+			continue
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": record.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"domain": canonical}}))
+		if len(models) == domainMigrationBatchSize {
+			if err := flush(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, fmt.Errorf("cursor error while iterating %s records: %w", recordType, err)
+	}
+
+	return updated, flush()
+}
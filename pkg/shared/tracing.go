@@ -0,0 +1,56 @@
+package shared
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans started by the discovery services.
+const TracerName = "github.com/bsv-blockchain/go-overlay-discovery-services"
+
+// Span attribute keys set by the discovery services.
+const (
+	// AttrProtocol is the protocol identifier, "SHIP" or "SLAP"
+	AttrProtocol = attribute.Key("discovery.protocol")
+	// AttrTopic is the overlay topic of an admitted output
+	AttrTopic = attribute.Key("discovery.topic")
+	// AttrService is the lookup service answering a question
+	AttrService = attribute.Key("discovery.service")
+	// AttrTxid is the transaction being processed
+	AttrTxid = attribute.Key("discovery.txid")
+	// AttrOutputIndex is the output being processed
+	AttrOutputIndex = attribute.Key("discovery.output_index")
+	// AttrQueryType is the QueryType of a lookup question
+	AttrQueryType = attribute.Key("discovery.query_type")
+	// AttrResultCount is the number of records or outputs returned
+	AttrResultCount = attribute.Key("discovery.result_count")
+	// AttrRejectedCount is the number of outputs rejected by admittance checks
+	AttrRejectedCount = attribute.Key("discovery.rejected_count")
+)
+
+// Tracer returns the tracer for spans started under ctx. A non-nil provider is used as is;
+// otherwise spans join the provider of the span already in ctx, falling back to the global
+// provider, which does nothing unless otel.SetTracerProvider has been called.
+func Tracer(ctx context.Context, provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+			provider = span.TracerProvider()
+		} else {
+			provider = otel.GetTracerProvider()
+		}
+	}
+	return provider.Tracer(TracerName)
+}
+
+// EndSpan records err, if any, on the span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
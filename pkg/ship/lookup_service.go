@@ -43,23 +43,102 @@ type LookupService struct {
 // Compile-time verification that LookupService implements engine.LookupService
 var _ engine.LookupService = (*LookupService)(nil)
 
-// NewLookupService creates a new SHIP lookup service instance.
+// NewLookupService creates a new SHIP lookup service instance with the default settings.
 func NewLookupService(storage StorageInterface) *LookupService {
-	doc := LookupDocumentation
-	return &LookupService{
+	return NewLookupServiceWithOptions(storage)
+}
+
+// NewLookupServiceWithOptions creates a new SHIP lookup service instance, customized by opts.
+// Without options it behaves like NewLookupService: it stores outputs admitted under Topic
+// and answers questions for Service.
+func NewLookupServiceWithOptions(storage StorageInterface, opts ...shared.Option) *LookupService {
+	o := shared.ApplyOptions(shared.Options{
+		Topic:               Topic,
+		Service:             Service,
+		MetaDataName:        "SHIP Lookup Service",
+		MetaDataDescription: "Provides lookup capabilities for SHIP tokens.",
+		Documentation:       LookupDocumentation,
+	}, opts)
+
+	s := &LookupService{
 		BaseLookupService: shared.NewBaseLookupService(shared.BaseLookupConfig{
-			Topic:               Topic,
-			ServiceID:           Service,
+			Topic:               o.Topic,
+			ServiceID:           o.Service,
 			Identifier:          Identifier,
-			MetaDataName:        "SHIP Lookup Service",
-			MetaDataDescription: "Provides lookup capabilities for SHIP tokens.",
-			Documentation:       &doc,
+			MetaDataName:        o.MetaDataName,
+			MetaDataDescription: o.MetaDataDescription,
+			Documentation:       &o.Documentation,
 			StoreRecord:         storage.StoreSHIPRecord,
 			DeleteRecord:        storage.DeleteSHIPRecord,
 			FindAll:             storage.FindAll,
+			RecordEvent:         newSHIPRecordEvent,
 		}),
 		storage: storage,
 	}
+	if tokens, ok := storage.(TokenRecordStore); ok {
+		s.Cfg.StoreRecordWithToken = tokens.StoreSHIPRecordWithToken
+	}
+	if reader, ok := storage.(RecordReader); ok {
+		s.Cfg.FindEvent = findSHIPEvent(reader)
+	}
+	s.SetLogger(o.Logger)
+	s.SetClock(o.Clock)
+	s.SetMaxResults(o.MaxResults)
+	return s
+}
+
+// newSHIPRecordEvent builds the event describing a newly stored SHIP record.
+func newSHIPRecordEvent(txid string, outputIndex int, identityKey, domain, topic string) types.RecordEvent {
+	record := types.SHIPRecord{
+		Txid:        txid,
+		OutputIndex: outputIndex,
+		IdentityKey: identityKey,
+		Domain:      domain,
+		Topic:       topic,
+	}
+	return record.Event(types.RecordEventAdmitted)
+}
+
+// findSHIPEvent returns a function describing the SHIP record stored for an outpoint.
+func findSHIPEvent(storage RecordReader) shared.FindEventFunc {
+	return func(ctx context.Context, txid string, outputIndex int) (*types.RecordEvent, error) {
+		record, err := storage.FindByOutpoint(ctx, txid, outputIndex)
+		if err != nil || record == nil {
+			return nil, err
+		}
+		event := record.Event(types.RecordEventRemoved)
+		return &event, nil
+	}
+}
+
+// Reverify re-runs signature verification over every stored SHIP record that kept its token
+// material (see SetKeepTokenMaterial) and reports the records whose tokens no longer verify
+// or no longer match the stored fields. Records are not modified. It fails with
+// shared.ErrStorageUnsupported if the storage does not implement RecordLister.
+func (s *LookupService) Reverify(ctx context.Context) (shared.ReverifyReport, error) {
+	lister, ok := s.storage.(RecordLister)
+	if !ok {
+		return shared.ReverifyReport{}, shared.UnsupportedStorageError("ListRecords")
+	}
+	return shared.Reverify(s.ContextWithLogger(ctx), s.ReverifyConfig(), func(ctx context.Context, limit, skip *int) ([]shared.StoredToken, error) {
+		records, err := lister.ListRecords(ctx, limit, skip)
+		if err != nil {
+			return nil, err
+		}
+
+		stored := make([]shared.StoredToken, len(records))
+		for i, record := range records {
+			stored[i] = shared.StoredToken{
+				Txid:        record.Txid,
+				OutputIndex: record.OutputIndex,
+				IdentityKey: record.IdentityKey,
+				Domain:      record.Domain,
+				FourthField: record.Topic,
+				Token:       record.Token,
+			}
+		}
+		return stored, nil
+	})
 }
 
 // Lookup performs a lookup query and returns matching results.
@@ -81,6 +160,7 @@ func (s *LookupService) ParseAndExecuteQuery(ctx context.Context, queryInterface
 		return nil, err
 	}
 
+	queryObj.Limit = s.LimitResults(queryObj.Limit)
 	if queryObj.FindAll != nil && *queryObj.FindAll {
 		return s.storage.FindAll(ctx, queryObj.Limit, queryObj.Skip, queryObj.SortOrder)
 	}
@@ -6,17 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
 	"github.com/bsv-blockchain/go-sdk/chainhash"
 	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
 	"github.com/bsv-blockchain/go-sdk/script"
 	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/pushdrop"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
 const TxID = "bdf1e48e845a65ba8c139c9b94844de30716f38d53787ba0a435e8705c4216d5"
@@ -38,6 +42,16 @@ func (m *MockStorage) StoreSHIPRecord(ctx context.Context, txid string, outputIn
 	return args.Error(0)
 }
 
+func (m *MockStorage) StoreSHIPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial) error {
+	args := m.Called(ctx, txid, outputIndex, identityKey, domain, topic, token)
+	return args.Error(0)
+}
+
+func (m *MockStorage) StoreSHIPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial, createdAt time.Time) error {
+	args := m.Called(ctx, txid, outputIndex, identityKey, domain, topic, token, createdAt)
+	return args.Error(0)
+}
+
 func (m *MockStorage) DeleteSHIPRecord(ctx context.Context, txid string, outputIndex int) error {
 	args := m.Called(ctx, txid, outputIndex)
 	return args.Error(0)
@@ -48,11 +62,46 @@ func (m *MockStorage) FindRecord(ctx context.Context, query types.SHIPQuery) ([]
 	return args.Get(0).([]types.UTXOReference), args.Error(1)
 }
 
+func (m *MockStorage) QueryRecords(ctx context.Context, query types.SHIPQuery) ([]types.SHIPRecord, error) {
+	args := m.Called(ctx, query)
+	records, _ := args.Get(0).([]types.SHIPRecord)
+	return records, args.Error(1)
+}
+
 func (m *MockStorage) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
 	args := m.Called(ctx, limit, skip, sortOrder)
 	return args.Get(0).([]types.UTXOReference), args.Error(1)
 }
 
+func (m *MockStorage) FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SHIPRecord, error) {
+	args := m.Called(ctx, txid, outputIndex)
+	record, _ := args.Get(0).(*types.SHIPRecord)
+	return record, args.Error(1)
+}
+
+func (m *MockStorage) ListRecords(ctx context.Context, limit, skip *int) ([]types.SHIPRecord, error) {
+	args := m.Called(ctx, limit, skip)
+	records, _ := args.Get(0).([]types.SHIPRecord)
+	return records, args.Error(1)
+}
+
+func (m *MockStorage) ForEachRecord(ctx context.Context, fn func(types.SHIPRecord) error) error {
+	args := m.Called(ctx)
+	records, _ := args.Get(0).([]types.SHIPRecord)
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockStorage) ImportRecords(ctx context.Context, records []types.SHIPRecord) error {
+	// Copy the batch, which the caller reuses after the call returns
+	args := m.Called(ctx, append([]types.SHIPRecord(nil), records...))
+	return args.Error(0)
+}
+
 func (m *MockStorage) EnsureIndexes(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -139,6 +188,80 @@ func TestNewSHIPLookupService(t *testing.T) {
 	assert.Equal(t, mockStorage, service.storage)
 }
 
+func TestNewSHIPLookupServiceWithOptions(t *testing.T) {
+	mockStorage := new(MockStorage)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	service := NewLookupServiceWithOptions(mockStorage,
+		shared.WithTopic("tm_ship_test"),
+		shared.WithService("ls_ship_test"),
+		shared.WithMetaData("Test SHIP", "SHIP lookups for tests"),
+		shared.WithDocumentation("# Test SHIP"),
+		shared.WithClock(shared.ClockFunc(func() time.Time { return now })),
+		shared.WithMaxResults(10),
+	)
+
+	assert.Equal(t, "ls_ship_test", service.ServiceName())
+	assert.Equal(t, "Test SHIP", service.GetMetaData().Name)
+	assert.Equal(t, "SHIP lookups for tests", service.GetMetaData().Description)
+	assert.Equal(t, "# Test SHIP", service.GetDocumentation())
+
+	limit := 10
+	mockStorage.On("FindAll", mock.Anything, &limit, (*int)(nil), (*types.SortOrder)(nil)).Return([]types.UTXOReference{}, nil)
+	_, err := service.Lookup(context.Background(), &lookup.LookupQuestion{
+		Service: "ls_ship_test",
+		Query:   json.RawMessage(`"findAll"`),
+	})
+	require.NoError(t, err)
+
+	_, err = service.Lookup(context.Background(), &lookup.LookupQuestion{
+		Service: Service,
+		Query:   json.RawMessage(`"findAll"`),
+	})
+	require.ErrorIs(t, err, shared.ErrLookupServiceNotSupported)
+
+	var events []types.RecordEvent
+	service.SetRecordEventListener(func(_ context.Context, event types.RecordEvent) {
+		events = append(events, event)
+	})
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 0).Return(nil, nil)
+	mockStorage.On("DeleteSHIPRecord", mock.Anything, TxID, 0).Return(nil)
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+	require.NoError(t, service.OutputSpent(context.Background(), &engine.OutputSpent{
+		Topic:    "tm_ship_test",
+		Outpoint: &transaction.Outpoint{Txid: txidArray, Index: 0},
+	}))
+	require.Len(t, events, 1)
+	assert.Equal(t, now, events[0].Timestamp)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestLookup_MaxResults(t *testing.T) {
+	mockStorage := new(MockStorage)
+	service := NewLookupServiceWithOptions(mockStorage, shared.WithMaxResults(5))
+
+	capped := 5
+	mockStorage.On("FindRecord", mock.Anything, mock.MatchedBy(func(query types.SHIPQuery) bool {
+		return query.Limit != nil && *query.Limit == capped
+	})).Return([]types.UTXOReference{}, nil).Twice()
+
+	for _, query := range []string{`{"topics":["tm_bridge"]}`, `{"topics":["tm_bridge"],"limit":50}`} {
+		_, err := service.Lookup(context.Background(), &lookup.LookupQuestion{Service: Service, Query: json.RawMessage(query)})
+		require.NoError(t, err)
+	}
+
+	smaller := 2
+	mockStorage.On("FindRecord", mock.Anything, mock.MatchedBy(func(query types.SHIPQuery) bool {
+		return query.Limit != nil && *query.Limit == smaller
+	})).Return([]types.UTXOReference{}, nil).Once()
+	_, err := service.Lookup(context.Background(), &lookup.LookupQuestion{Service: Service, Query: json.RawMessage(`{"limit":2}`)})
+	require.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+}
+
 // Test OutputAdmittedByTopic
 
 func TestOutputAdmittedByTopic_Success(t *testing.T) {
@@ -175,6 +298,32 @@ func TestOutputAdmittedByTopic_Success(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
+func TestOutputAdmittedByTopic_EmitsCanonicalDomain(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+	var events []types.RecordEvent
+	service.SetRecordEventListener(func(_ context.Context, event types.RecordEvent) {
+		events = append(events, event)
+	})
+
+	scriptObj, err := script.NewFromHex(createValidPushDropScript([][]byte{
+		[]byte("SHIP"),
+		{0x01, 0x02, 0x03, 0x04},
+		[]byte("HTTPS://Example.com:443/"),
+		[]byte("tm_bridge"),
+	}))
+	require.NoError(t, err)
+	beefBytes, txidHex, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+
+	mockStorage.On("StoreSHIPRecord", mock.Anything, txidHex, 0, "01020304", "HTTPS://Example.com:443/", "tm_bridge").Return(nil)
+
+	err = service.OutputAdmittedByTopic(context.Background(), &engine.OutputAdmittedByTopic{Topic: Topic, OutputIndex: 0, AtomicBEEF: beefBytes})
+	require.NoError(t, err)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "https://example.com", events[0].Domain)
+}
+
 func TestOutputAdmittedByTopic_IgnoreNonSHIPTopic(t *testing.T) {
 	service, _ := createTestSHIPLookupService()
 
@@ -261,6 +410,150 @@ func TestOutputAdmittedByTopic_IgnoreNonSHIPProtocol(t *testing.T) {
 	require.NoError(t, err) // Should silently ignore non-SHIP protocols
 }
 
+func TestOutputAdmittedByTopic_KeepsTokenMaterial(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+	service.SetKeepTokenMaterial(true)
+
+	fields := [][]byte{
+		[]byte("SHIP"),
+		{0x01, 0x02, 0x03, 0x04},
+		[]byte("https://example.com"),
+		[]byte("tm_bridge"),
+	}
+	scriptObj, err := script.NewFromHex(createValidPushDropScript(fields))
+	require.NoError(t, err)
+	beefBytes, txidHex, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+
+	expectedToken := &types.TokenMaterial{
+		Fields:           fields,
+		LockingPublicKey: "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+	}
+	mockStorage.On("StoreSHIPRecordWithToken", mock.Anything, txidHex, 0, "01020304", "https://example.com", "tm_bridge", expectedToken).Return(nil)
+
+	err = service.OutputAdmittedByTopic(context.Background(), &engine.OutputAdmittedByTopic{
+		Topic:       Topic,
+		OutputIndex: 0,
+		AtomicBEEF:  beefBytes,
+	})
+
+	require.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+	mockStorage.AssertNotCalled(t, "StoreSHIPRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutputAdmittedByTopic_URISchemes(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+	registry := utils.NewURISchemeRegistry()
+	require.NoError(t, registry.Disable("https+rtt://"))
+	service.SetURISchemes(registry)
+
+	admit := func(domain string) {
+		fields := [][]byte{[]byte("SHIP"), {0x01, 0x02, 0x03, 0x04}, []byte(domain), []byte("tm_bridge")}
+		scriptObj, err := script.NewFromHex(createValidPushDropScript(fields))
+		require.NoError(t, err)
+		beefBytes, _, err := createTestBEEFWithScript(scriptObj)
+		require.NoError(t, err)
+		require.NoError(t, service.OutputAdmittedByTopic(context.Background(), &engine.OutputAdmittedByTopic{
+			Topic:       Topic,
+			OutputIndex: 0,
+			AtomicBEEF:  beefBytes,
+		}))
+	}
+
+	mockStorage.On("StoreSHIPRecord", mock.Anything, mock.Anything, 0, "01020304", "https://example.com", "tm_bridge").Return(nil)
+
+	admit("https://example.com")
+	admit("https+rtt://example.com")
+
+	mockStorage.AssertNumberOfCalls(t, "StoreSHIPRecord", 1)
+}
+
+// Test Reverify
+
+func TestReverify_CanonicalDomain(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+
+	beef, txid, identityKey := createSignedSHIPBEEF(t, "https://Example.com/", "tm_foo")
+	result := pushdrop.Decode(beef.FindTransactionByHash(txid).Outputs[0].LockingScript)
+	require.NotNil(t, result)
+	token := &types.TokenMaterial{Fields: result.Fields, LockingPublicKey: result.LockingPublicKey.ToDERHex()}
+
+	// Records store the canonical domain, while the token keeps the domain as signed
+	limit, skip := 500, 0
+	mockStorage.On("ListRecords", mock.Anything, &limit, &skip).Return([]types.SHIPRecord{
+		{Txid: "aa", OutputIndex: 0, IdentityKey: identityKey, Domain: "https://example.com", Topic: "tm_foo", Token: token},
+	}, nil)
+
+	report, err := service.Reverify(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Checked)
+	assert.Empty(t, report.Mismatches)
+}
+
+func TestReverify_FlagsMismatches(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+
+	beef, txid, identityKey := createSignedSHIPBEEF(t, "https://example.com", "tm_foo")
+	result := pushdrop.Decode(beef.FindTransactionByHash(txid).Outputs[0].LockingScript)
+	require.NotNil(t, result)
+	token := &types.TokenMaterial{Fields: result.Fields, LockingPublicKey: result.LockingPublicKey.ToDERHex()}
+
+	// A token whose domain was altered after signing no longer verifies
+	tamperedFields := make([][]byte, len(result.Fields))
+	copy(tamperedFields, result.Fields)
+	tamperedFields[2] = []byte("https://evil.example.com")
+	tampered := &types.TokenMaterial{Fields: tamperedFields, LockingPublicKey: token.LockingPublicKey}
+
+	limit, skip := 500, 0
+	mockStorage.On("ListRecords", mock.Anything, &limit, &skip).Return([]types.SHIPRecord{
+		{Txid: "aa", OutputIndex: 0, IdentityKey: identityKey, Domain: "https://example.com", Topic: "tm_foo", Token: token},
+		{Txid: "bb", OutputIndex: 0, IdentityKey: identityKey, Domain: "https://example.com", Topic: "tm_bar", Token: token},
+		{Txid: "cc", OutputIndex: 0, IdentityKey: identityKey, Domain: "https://evil.example.com", Topic: "tm_foo", Token: tampered},
+		{Txid: "dd", OutputIndex: 1, IdentityKey: identityKey, Domain: "https://example.com", Topic: "tm_foo"},
+	}, nil)
+
+	report, err := service.Reverify(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Mismatches, 2)
+	assert.Equal(t, "bb", report.Mismatches[0].Txid)
+	assert.Contains(t, report.Mismatches[0].Reason, "topic or service is \"tm_bar\", token has \"tm_foo\"")
+	assert.Equal(t, "cc", report.Mismatches[1].Txid)
+	assert.Contains(t, report.Mismatches[1].Reason, shared.ErrTokenSignatureLink.Error())
+	mockStorage.AssertExpectations(t)
+}
+
+func TestReverify_UsesSignatureCache(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+	cache := shared.NewSignatureCache(0)
+	service.SetSignatureCache(cache)
+
+	beef, txid, identityKey := createSignedSHIPBEEF(t, "https://example.com", "tm_foo")
+	result := pushdrop.Decode(beef.FindTransactionByHash(txid).Outputs[0].LockingScript)
+	require.NotNil(t, result)
+	token := &types.TokenMaterial{Fields: result.Fields, LockingPublicKey: result.LockingPublicKey.ToDERHex()}
+
+	limit, skip := 500, 0
+	mockStorage.On("ListRecords", mock.Anything, &limit, &skip).Return([]types.SHIPRecord{
+		{Txid: "aa", OutputIndex: 0, IdentityKey: identityKey, Domain: "https://example.com", Topic: "tm_foo", Token: token},
+	}, nil)
+
+	for range 2 {
+		report, err := service.Reverify(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Checked)
+		assert.Empty(t, report.Mismatches)
+	}
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Hits, "the second pass must reuse the first result")
+}
+
 // Test OutputSpent
 
 func TestOutputSpent_Success(t *testing.T) {
@@ -312,6 +605,93 @@ func TestOutputSpent_IgnoreNonSHIPTopic(t *testing.T) {
 	require.NoError(t, err) // Should silently ignore non-SHIP topics
 }
 
+func TestOutputSpent_EmitsRemovedEvent(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+
+	var events []types.RecordEvent
+	service.SetRecordEventListener(func(_ context.Context, event types.RecordEvent) {
+		events = append(events, event)
+	})
+
+	stored := &types.SHIPRecord{
+		Txid:        TxID,
+		OutputIndex: 0,
+		IdentityKey: "01020304",
+		Domain:      "https://example.com",
+		Topic:       "tm_bridge",
+	}
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 0).Return(stored, nil)
+	mockStorage.On("DeleteSHIPRecord", mock.Anything, TxID, 0).Return(nil)
+
+	err = service.OutputSpent(context.Background(), &engine.OutputSpent{
+		Topic:    Topic,
+		Outpoint: &transaction.Outpoint{Txid: txidArray, Index: 0},
+	})
+	require.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, types.RecordEventRemoved, events[0].Type)
+	assert.Equal(t, "SHIP", events[0].Protocol)
+	assert.Equal(t, "tm_bridge", events[0].Topic)
+	assert.Equal(t, "https://example.com", events[0].Domain)
+}
+
+func TestOutputEvicted_EmitsRemovedEventForUnknownRecord(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+
+	var events []types.RecordEvent
+	service.SetRecordEventListener(func(_ context.Context, event types.RecordEvent) {
+		events = append(events, event)
+	})
+
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 2).Return(nil, nil)
+	mockStorage.On("DeleteSHIPRecord", mock.Anything, TxID, 2).Return(nil)
+
+	err = service.OutputEvicted(context.Background(), &transaction.Outpoint{Txid: txidArray, Index: 2})
+	require.NoError(t, err)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, types.RecordEventRemoved, events[0].Type)
+	assert.Equal(t, TxID, events[0].Txid)
+	assert.Equal(t, 2, events[0].OutputIndex)
+	assert.Empty(t, events[0].Domain)
+}
+
+func TestOutputSpent_NoEventOnStorageError(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+
+	listenerCalled := false
+	service.SetRecordEventListener(func(_ context.Context, _ types.RecordEvent) {
+		listenerCalled = true
+	})
+
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 0).Return(nil, nil)
+	mockStorage.On("DeleteSHIPRecord", mock.Anything, TxID, 0).Return(errTestStorage)
+
+	err = service.OutputSpent(context.Background(), &engine.OutputSpent{
+		Topic:    Topic,
+		Outpoint: &transaction.Outpoint{Txid: txidArray, Index: 0},
+	})
+	require.ErrorIs(t, err, errTestStorage)
+	assert.False(t, listenerCalled)
+}
+
 // Test OutputEvicted
 
 func TestOutputEvicted_Success(t *testing.T) {
@@ -586,6 +966,118 @@ func TestGetMetaData(t *testing.T) {
 	assert.Empty(t, metadata.InfoUrl)
 }
 
+// Test output-list answers
+
+func TestLookup_OutputListWithBEEFProvider(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+
+	var requested []string
+	service.SetBEEFProvider(shared.BEEFProviderFunc(func(_ context.Context, txid string, outputIndex int) ([]byte, error) {
+		requested = append(requested, txid)
+		if txid == "missing" {
+			return nil, shared.ErrOutputBEEFNotFound
+		}
+		return []byte("beef-" + txid), nil
+	}))
+
+	mockStorage.On("FindAll", mock.Anything, (*int)(nil), (*int)(nil), (*types.SortOrder)(nil)).Return([]types.UTXOReference{
+		{Txid: "abc123", OutputIndex: 0},
+		{Txid: "missing", OutputIndex: 3},
+		{Txid: "def456", OutputIndex: 2},
+	}, nil)
+
+	answer, err := service.Lookup(context.Background(), &lookup.LookupQuestion{
+		Service: Service,
+		Query:   json.RawMessage(`"findAll"`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, lookup.AnswerTypeOutputList, answer.Type)
+	assert.Nil(t, answer.Result)
+	assert.Equal(t, []*lookup.OutputListItem{
+		{Beef: []byte("beef-abc123"), OutputIndex: 0},
+		{Beef: []byte("beef-def456"), OutputIndex: 2},
+	}, answer.Outputs)
+	assert.Equal(t, []string{"abc123", "missing", "def456"}, requested)
+}
+
+func TestLookup_OutputListProviderError(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+	service.SetBEEFProvider(shared.BEEFProviderFunc(func(_ context.Context, _ string, _ int) ([]byte, error) {
+		return nil, errTestStorage
+	}))
+
+	mockStorage.On("FindAll", mock.Anything, (*int)(nil), (*int)(nil), (*types.SortOrder)(nil)).Return([]types.UTXOReference{
+		{Txid: "abc123", OutputIndex: 0},
+	}, nil)
+
+	_, err := service.Lookup(context.Background(), &lookup.LookupQuestion{
+		Service: Service,
+		Query:   json.RawMessage(`"findAll"`),
+	})
+
+	require.ErrorIs(t, err, errTestStorage)
+	assert.Contains(t, err.Error(), "failed to resolve BEEF for abc123.0")
+}
+
+// Test reconciliation
+
+// utxoSourceFunc adapts a function to shared.UTXOSource for tests.
+type utxoSourceFunc func(ctx context.Context, topic string) ([]*engine.Output, error)
+
+func (f utxoSourceFunc) FindUTXOsForTopic(ctx context.Context, topic string, _ float64, _ uint32, _ bool) ([]*engine.Output, error) {
+	return f(ctx, topic)
+}
+
+// FindOutput reports that the engine holds no output beyond those listed by the function.
+func (f utxoSourceFunc) FindOutput(context.Context, *transaction.Outpoint, *string, *bool, bool) (*engine.Output, error) {
+	return nil, nil
+}
+
+func TestReconcile_CollectsRepairErrors(t *testing.T) {
+	service, mockStorage := createTestSHIPLookupService()
+
+	hash, err := chainhash.NewHashFromHex(TxID)
+	require.NoError(t, err)
+	txid := hex.EncodeToString(hash[:])
+
+	var requestedTopic string
+	source := utxoSourceFunc(func(_ context.Context, topic string) ([]*engine.Output, error) {
+		requestedTopic = topic
+		// The engine holds output 1 but returns no BEEF for it
+		return []*engine.Output{{Outpoint: transaction.Outpoint{Txid: *hash, Index: 1}, Topic: topic}}, nil
+	})
+
+	mockStorage.On("FindAll", mock.Anything, (*int)(nil), (*int)(nil), (*types.SortOrder)(nil)).Return([]types.UTXOReference{
+		{Txid: txid, OutputIndex: 0},
+	}, nil)
+	mockStorage.On("DeleteSHIPRecord", mock.Anything, txid, 0).Return(errTestStorage)
+	mockStorage.On("FindByOutpoint", mock.Anything, txid, 1).Return(nil, nil)
+
+	report, err := service.Reconcile(context.Background(), source, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, Topic, requestedTopic)
+	assert.Equal(t, []types.UTXOReference{{Txid: txid, OutputIndex: 0}}, report.Orphans)
+	assert.Equal(t, []types.UTXOReference{{Txid: txid, OutputIndex: 1}}, report.Missing)
+	assert.Zero(t, report.RemovedOrphans)
+	assert.Zero(t, report.RestoredMissing)
+	require.Len(t, report.RepairErrors, 2)
+	assert.Contains(t, report.RepairErrors[0], "storage error")
+	assert.Contains(t, report.RepairErrors[1], "engine returned no BEEF")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestReconcile_SourceError(t *testing.T) {
+	service, _ := createTestSHIPLookupService()
+
+	_, err := service.Reconcile(context.Background(), utxoSourceFunc(func(_ context.Context, _ string) ([]*engine.Output, error) {
+		return nil, errTestStorage
+	}), false)
+
+	require.ErrorIs(t, err, errTestStorage)
+}
+
 // Test edge cases and error scenarios
 
 func TestLookup_StorageError(t *testing.T) {
@@ -4,7 +4,10 @@ package ship
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,6 +16,16 @@ import (
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
+)
+
+// Static error variables for err113 compliance
+var (
+	errImportTxidEmpty        = errors.New("txid cannot be empty")
+	errImportOutputIndex      = errors.New("outputIndex cannot be negative")
+	errImportIdentityKeyEmpty = errors.New("identityKey cannot be empty")
+	errImportDomainEmpty      = errors.New("domain cannot be empty")
+	errImportTopicEmpty       = errors.New("topic cannot be empty")
 )
 
 // StorageInterface defines the interface for SHIP storage operations.
@@ -24,12 +37,75 @@ type StorageInterface interface {
 	EnsureIndexes(ctx context.Context) error
 }
 
+// RecordReader is implemented by SHIP storages that return a single stored record. Lookup
+// services use it to describe removed records in their events, and rest.Handler to serve them.
+type RecordReader interface {
+	FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SHIPRecord, error)
+}
+
+// RecordQuerier is implemented by SHIP storages that return the full records matching a query.
+// rest.Handler uses it to serve record lists.
+type RecordQuerier interface {
+	QueryRecords(ctx context.Context, query types.SHIPQuery) ([]types.SHIPRecord, error)
+}
+
+// TokenRecordStore is implemented by SHIP storages that keep the token material a record was
+// parsed from. Lookup services use it when SetKeepTokenMaterial is on.
+type TokenRecordStore interface {
+	StoreSHIPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial) error
+}
+
+// BackdatedRecordStore is implemented by SHIP storages that store a record with an explicit
+// creation time, for backfilling records first seen elsewhere.
+type BackdatedRecordStore interface {
+	StoreSHIPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial, createdAt time.Time) error
+}
+
+// RecordLister is implemented by SHIP storages that return full records page by page.
+// LookupService.Reverify uses it to load the stored token material.
+type RecordLister interface {
+	ListRecords(ctx context.Context, limit, skip *int) ([]types.SHIPRecord, error)
+}
+
+// RecordIterator is implemented by SHIP storages that stream every stored record.
+// ExportJSONL uses it.
+type RecordIterator interface {
+	ForEachRecord(ctx context.Context, fn func(types.SHIPRecord) error) error
+}
+
+// RecordImporter is implemented by SHIP storages that write complete records, as ImportJSONL does.
+type RecordImporter interface {
+	ImportRecords(ctx context.Context, records []types.SHIPRecord) error
+}
+
+// RecordCounter is implemented by SHIP storages that count their records by topic without
+// reading them. metrics.Metrics uses it to export the record counts.
+type RecordCounter interface {
+	CountRecordsByTopic(ctx context.Context) (map[string]int, error)
+}
+
+// Compile-time verification of the optional interfaces implemented by Storage
+var (
+	_ RecordReader         = (*Storage)(nil)
+	_ RecordQuerier        = (*Storage)(nil)
+	_ TokenRecordStore     = (*Storage)(nil)
+	_ BackdatedRecordStore = (*Storage)(nil)
+	_ RecordLister         = (*Storage)(nil)
+	_ RecordIterator       = (*Storage)(nil)
+	_ RecordImporter       = (*Storage)(nil)
+	_ RecordCounter        = (*Storage)(nil)
+)
+
 // Storage implements a storage engine for SHIP protocol records.
 // It provides MongoDB-based storage with methods for storing, deleting,
 // and querying SHIP records with support for pagination and filtering.
 type Storage struct {
 	db          *mongo.Database
 	shipRecords *mongo.Collection
+	// logger receives storage events (optional)
+	logger *slog.Logger
+	// clock stamps stored records (optional)
+	clock shared.Clock
 }
 
 // Compile-time verification that Storage implements SHIPStorageInterface
@@ -44,6 +120,18 @@ func NewStorage(db *mongo.Database) *Storage {
 	}
 }
 
+// SetLogger sets the logger receiving storage events, such as cursors that failed to close.
+// A nil logger (the default) discards them.
+func (s *Storage) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetClock sets the clock stamping the creation time of stored records. A nil clock (the
+// default) uses the system time.
+func (s *Storage) SetClock(clock shared.Clock) {
+	s.clock = clock
+}
+
 // EnsureIndexes creates the necessary indexes for the SHIP records collection.
 // This method should be called once during application initialization to optimize
 // query performance. It creates a compound index on domain and topic fields.
@@ -65,15 +153,29 @@ func (s *Storage) EnsureIndexes(ctx context.Context) error {
 
 // StoreSHIPRecord stores a new SHIP record in the database.
 // The record includes transaction information, identity key, domain, topic,
-// and an automatically generated creation timestamp.
+// and a creation timestamp read from the storage clock (see SetClock).
 func (s *Storage) StoreSHIPRecord(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string) error {
+	return s.StoreSHIPRecordWithToken(ctx, txid, outputIndex, identityKey, domain, topic, nil)
+}
+
+// StoreSHIPRecordWithToken stores a new SHIP record together with the raw token material it was
+// parsed from, so its signature can be re-verified later. A nil token stores the record alone.
+// The domain is stored in the canonical form returned by utils.CanonicalDomain.
+func (s *Storage) StoreSHIPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial) error {
+	return s.StoreSHIPRecordAt(ctx, txid, outputIndex, identityKey, domain, topic, token, shared.Now(s.clock))
+}
+
+// StoreSHIPRecordAt stores a new SHIP record created at createdAt rather than the time of the
+// storage clock, for backfilling records first seen elsewhere. A nil token stores the record alone.
+func (s *Storage) StoreSHIPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial, createdAt time.Time) error {
 	record := types.SHIPRecord{
 		Txid:        txid,
 		OutputIndex: outputIndex,
 		IdentityKey: identityKey,
-		Domain:      domain,
+		Domain:      utils.CanonicalDomain(domain),
 		Topic:       topic,
-		CreatedAt:   time.Now(),
+		CreatedAt:   createdAt,
+		Token:       token,
 	}
 
 	_, err := s.shipRecords.InsertOne(ctx, record)
@@ -102,13 +204,41 @@ func (s *Storage) DeleteSHIPRecord(ctx context.Context, txid string, outputIndex
 
 // FindRecord finds SHIP records based on the provided query parameters.
 // It supports filtering by domain, topics, and identity key, with pagination and sorting options.
+// The domain filter is canonicalized, so it matches every spelling of the stored domain.
 // Returns only UTXO references (txid and outputIndex) as projection for efficient querying.
 func (s *Storage) FindRecord(ctx context.Context, query types.SHIPQuery) ([]types.UTXOReference, error) {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	mongoQuery := recordFilter(query)
+
+	// Set up the find options
+	findOpts := options.Find()
+	findOpts.SetProjection(shared.UTXOProjection())
+	shared.ApplyPaginationOpts(findOpts, query.SortOrder, query.Skip, query.Limit)
+
+	// Execute the query
+	cursor, err := s.shipRecords.Find(ctx, mongoQuery, findOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find SHIP records: %w", err)
+	}
+	defer shared.CloseCursor(ctx, cursor, "SHIP")
+
+	return shared.CollectUTXORefs(ctx, cursor, "SHIP")
+}
+
+// QueryRecords returns the full SHIP records matching the query, including any stored token
+// material, in the order and page FindRecord returns their references, using a single query.
+func (s *Storage) QueryRecords(ctx context.Context, query types.SHIPQuery) ([]types.SHIPRecord, error) {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	return shared.QueryRecords[types.SHIPRecord](ctx, s.shipRecords, recordFilter(query), query.SortOrder, query.Skip, query.Limit, "SHIP")
+}
+
+// recordFilter returns the MongoDB filter selecting the SHIP records matching the query.
+func recordFilter(query types.SHIPQuery) bson.M {
 	mongoQuery := bson.M{}
 
 	// Add domain filter if provided
 	if query.Domain != nil {
-		mongoQuery["domain"] = *query.Domain
+		mongoQuery["domain"] = utils.CanonicalDomain(*query.Domain)
 	}
 
 	// Add topics filter using $in operator if provided
@@ -121,26 +251,107 @@ func (s *Storage) FindRecord(ctx context.Context, query types.SHIPQuery) ([]type
 		mongoQuery["identityKey"] = *query.IdentityKey
 	}
 
-	// Set up the find options
-	findOpts := options.Find()
-	findOpts.SetProjection(shared.UTXOProjection())
-	shared.ApplyPaginationOpts(findOpts, query.SortOrder, query.Skip, query.Limit)
+	return mongoQuery
+}
 
-	// Execute the query
-	cursor, err := s.shipRecords.Find(ctx, mongoQuery, findOpts)
+// FindByOutpoint returns the SHIP record stored for the given transaction ID and output index.
+// Returns nil (no error) if no such record exists.
+func (s *Storage) FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SHIPRecord, error) {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	filter := bson.M{
+		"txid":        txid,
+		"outputIndex": outputIndex,
+	}
+
+	var record types.SHIPRecord
+	err := s.shipRecords.FindOne(ctx, filter).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil //nolint:nilnil // nil,nil means no record stored
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to find SHIP records: %w", err)
+		return nil, fmt.Errorf("failed to find SHIP record by outpoint: %w", err)
 	}
-	defer func() {
-		_ = cursor.Close(ctx)
-	}()
 
-	return shared.CollectUTXORefs(ctx, cursor, "SHIP")
+	return &record, nil
 }
 
 // FindAll returns all SHIP records in the database with optional pagination and sorting.
 // This method ignores all filtering criteria and returns all available records.
 // Returns only UTXO references (txid and outputIndex) as projection for efficient querying.
 func (s *Storage) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
 	return shared.FindAllRecords(ctx, s.shipRecords, limit, skip, sortOrder, "SHIP")
 }
+
+// ListRecords returns full SHIP records, including any stored token material, oldest first
+// with optional pagination.
+func (s *Storage) ListRecords(ctx context.Context, limit, skip *int) ([]types.SHIPRecord, error) {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	return shared.ListRecords[types.SHIPRecord](ctx, s.shipRecords, limit, skip, "SHIP")
+}
+
+// CountRecordsByTopic returns the number of stored SHIP records for each topic, counted by
+// MongoDB in a single aggregation.
+func (s *Storage) CountRecordsByTopic(ctx context.Context) (map[string]int, error) {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	return shared.CountRecordsBy(ctx, s.shipRecords, "topic", "SHIP")
+}
+
+// ForEachRecord streams every SHIP record, including any stored token material, to fn,
+// oldest first. Iteration stops at the first error returned by fn.
+func (s *Storage) ForEachRecord(ctx context.Context, fn func(types.SHIPRecord) error) error {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	return shared.ForEachRecord(ctx, s.shipRecords, "SHIP", fn)
+}
+
+// ImportRecords writes complete SHIP records, preserving their creation time and token
+// material. A record replaces any existing record for the same outpoint, so imports can be rerun.
+// Domains are canonicalized as by StoreSHIPRecordWithToken.
+func (s *Storage) ImportRecords(ctx context.Context, records []types.SHIPRecord) error {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	canonical := make([]types.SHIPRecord, len(records))
+	for i, record := range records {
+		record.Domain = utils.CanonicalDomain(record.Domain)
+		canonical[i] = record
+	}
+	return shared.UpsertRecords(ctx, s.shipRecords, canonical, func(record types.SHIPRecord) bson.M {
+		return bson.M{"txid": record.Txid, "outputIndex": record.OutputIndex}
+	}, "SHIP")
+}
+
+// CanonicalizeDomains migrates SHIP records stored before domains were canonicalized, rewriting
+// each domain to the form returned by utils.CanonicalDomain. It returns the number of records
+// updated and is safe to rerun.
+func (s *Storage) CanonicalizeDomains(ctx context.Context) (int, error) {
+	ctx = shared.ContextWithLogger(ctx, s.logger)
+	return shared.CanonicalizeDomains(ctx, s.shipRecords, "SHIP")
+}
+
+// ExportJSONL writes every SHIP record in storage to w as line-delimited JSON and returns
+// the number of records written. Records are streamed, not loaded into memory.
+func ExportJSONL(ctx context.Context, storage RecordIterator, w io.Writer) (int, error) {
+	return shared.ExportRecordsJSONL(ctx, w, storage.ForEachRecord)
+}
+
+// ImportJSONL reads line-delimited JSON SHIP records, as written by ExportJSONL, from r and
+// writes them to storage in batches. It returns the number of records imported.
+func ImportJSONL(ctx context.Context, storage RecordImporter, r io.Reader) (int, error) {
+	return shared.ImportRecordsJSONL(ctx, r, storage.ImportRecords, validateImportRecord)
+}
+
+// validateImportRecord checks that an imported SHIP record has every required field.
+func validateImportRecord(record types.SHIPRecord) error {
+	switch {
+	case record.Txid == "":
+		return errImportTxidEmpty
+	case record.OutputIndex < 0:
+		return errImportOutputIndex
+	case record.IdentityKey == "":
+		return errImportIdentityKeyEmpty
+	case record.Domain == "":
+		return errImportDomainEmpty
+	case record.Topic == "":
+		return errImportTopicEmpty
+	}
+	return nil
+}
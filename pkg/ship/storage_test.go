@@ -1,12 +1,19 @@
 package ship
 
 import (
+	"bytes"
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
 )
 
@@ -472,3 +479,158 @@ func intPtr(i int) *int {
 func sortOrderPtr(s types.SortOrder) *types.SortOrder {
 	return &s
 }
+
+func TestExportImportJSONL_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 123000000, time.UTC)
+	records := []types.SHIPRecord{
+		{Txid: "tx1", OutputIndex: 0, IdentityKey: "alice", Domain: "https://a.example.com", Topic: "tm_a", CreatedAt: createdAt},
+		{
+			Txid: "tx2", OutputIndex: 3, IdentityKey: "bob", Domain: "https://b.example.com", Topic: "tm_b", CreatedAt: createdAt.Add(time.Hour),
+			Token: &types.TokenMaterial{Fields: [][]byte{[]byte("SHIP"), {0x02}}, LockingPublicKey: "02ab"},
+		},
+	}
+
+	source := new(MockStorage)
+	source.On("ForEachRecord", mock.Anything).Return(records, nil)
+
+	var buf bytes.Buffer
+	exported, err := ExportJSONL(context.Background(), source, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, exported)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"createdAt":"2024-03-01T12:30:00.123Z"`)
+
+	target := new(MockStorage)
+	target.On("ImportRecords", mock.Anything, records).Return(nil)
+
+	imported, err := ImportJSONL(context.Background(), target, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+	target.AssertExpectations(t)
+}
+
+func TestImportJSONL_InvalidRecord(t *testing.T) {
+	target := new(MockStorage)
+
+	input := `{"txid":"tx1","outputIndex":0,"identityKey":"alice","domain":"https://a.example.com","topic":"tm_a"}
+{"txid":"tx2","outputIndex":0,"identityKey":"bob","domain":"https://b.example.com"}
+`
+	imported, err := ImportJSONL(context.Background(), target, strings.NewReader(input))
+
+	require.ErrorIs(t, err, shared.ErrInvalidImportRecord)
+	require.ErrorIs(t, err, errImportTopicEmpty)
+	assert.Contains(t, err.Error(), "record 2")
+	assert.Zero(t, imported)
+	target.AssertNotCalled(t, "ImportRecords", mock.Anything, mock.Anything)
+}
+
+func TestStorage_CanonicalizesDomains(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("store", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		require.NoError(mt, storage.StoreSHIPRecord(context.Background(), "tx1", 0, "alice", "HTTPS://Example.com:443/", "tm_one"))
+
+		inserted := mt.GetStartedEvent().Command.Lookup("documents", "0").Document()
+		assert.Equal(mt, "https://example.com", inserted.Lookup("domain").StringValue())
+	})
+
+	mt.Run("find", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		ns := mt.DB.Name() + ".shipRecords"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "txid", Value: "tx1"}, {Key: "outputIndex", Value: 0}}))
+
+		results, err := storage.FindRecord(context.Background(), types.SHIPQuery{Domain: stringPtr("https://BÜCHER.example/")})
+		require.NoError(mt, err)
+		assert.Equal(mt, []types.UTXOReference{{Txid: "tx1", OutputIndex: 0}}, results)
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(mt, "https://xn--bcher-kva.example", filter.Lookup("domain").StringValue())
+	})
+
+	mt.Run("migrate", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		ns := mt.DB.Name() + ".shipRecords"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: 1}, {Key: "domain", Value: "https://Example.com/"}},
+				bson.D{{Key: "_id", Value: 2}, {Key: "domain", Value: "https://example.com"}},
+				bson.D{{Key: "_id", Value: 3}, {Key: "domain", Value: "https://example.com:443"}},
+			),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+		)
+
+		updated, err := storage.CanonicalizeDomains(context.Background())
+		require.NoError(mt, err)
+		assert.Equal(mt, 2, updated)
+
+		mt.GetStartedEvent() // the find command
+		updates, err := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, updates, 2)
+		for i, id := range []int32{1, 3} {
+			update := updates[i].Document()
+			assert.Equal(mt, id, update.Lookup("q", "_id").Int32())
+			assert.Equal(mt, "https://example.com", update.Lookup("u", "$set", "domain").StringValue())
+		}
+	})
+}
+
+func TestQueryRecords(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("single query", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		ns := mt.DB.Name() + ".shipRecords"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			bson.D{{Key: "txid", Value: "tx1"}, {Key: "outputIndex", Value: 1}, {Key: "token", Value: bson.D{{Key: "lockingPublicKey", Value: "02ab"}}}},
+			bson.D{{Key: "txid", Value: "tx2"}, {Key: "outputIndex", Value: 0}},
+		))
+
+		limit := 2
+		records, err := storage.QueryRecords(context.Background(), types.SHIPQuery{Topics: []string{"tm_one"}, Limit: &limit})
+		require.NoError(mt, err)
+		require.Len(mt, records, 2)
+		assert.Equal(mt, "tx1", records[0].Txid)
+		require.NotNil(mt, records[0].Token)
+		assert.Equal(mt, "02ab", records[0].Token.LockingPublicKey)
+		assert.Equal(mt, "tx2", records[1].Txid)
+
+		command := mt.GetStartedEvent().Command
+		assert.Equal(mt, int64(2), command.Lookup("limit").AsInt64())
+		_, err = command.LookupErr("projection")
+		assert.Error(mt, err, "full records must not be projected")
+	})
+}
+
+func TestCountRecordsByTopic(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("aggregate", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		ns := mt.DB.Name() + ".shipRecords"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "tm_one"}, {Key: "count", Value: 2}},
+			bson.D{{Key: "_id", Value: "tm_two"}, {Key: "count", Value: 1}},
+		))
+
+		counts, err := storage.CountRecordsByTopic(context.Background())
+		require.NoError(mt, err)
+		assert.Equal(mt, map[string]int{"tm_one": 2, "tm_two": 1}, counts)
+
+		command := mt.GetStartedEvent().Command
+		assert.Equal(mt, "shipRecords", command.Lookup("aggregate").StringValue())
+		assert.Equal(mt, "$topic", command.Lookup("pipeline", "0", "$group", "_id").StringValue())
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "boom"}))
+
+		counts, err := storage.CountRecordsByTopic(context.Background())
+		require.Error(mt, err)
+		assert.Nil(mt, counts)
+	})
+}
@@ -3,6 +3,7 @@
 package ship
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"github.com/bsv-blockchain/go-sdk/overlay"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
 )
 
 // Static error variables for err113 compliance
@@ -21,6 +23,8 @@ var (
 	errNotSubscribedToTopic   = errors.New("not subscribed to topic")
 	errMessageTopicEmpty      = errors.New("message topic cannot be empty")
 	errNoHandlerFoundForTopic = errors.New("no handler found for topic")
+	errHandlerNotFound        = errors.New("handler not found")
+	errNoLookupService        = errors.New("topic manager has no lookup service")
 )
 
 // TopicSubscription represents an active topic subscription
@@ -57,10 +61,18 @@ type TopicManager struct {
 	// BaseTopicManagerOps provides shared implementations for engine.TopicManager interface methods
 	shared.BaseTopicManagerOps
 
+	// topic is the topic record events are published on
+	topic string
+	// topicManagerMetaData is returned by GetTopicManagerMetaData
+	topicManagerMetaData overlay.MetaData
 	// subscriptions holds all active topic subscriptions
 	subscriptions map[string]*TopicSubscription
-	// handlers holds message handlers for each subscribed topic
-	handlers map[string]TopicMessageHandler
+	// handlers holds the message handlers registered on each subscribed topic
+	handlers map[string]*shared.HandlerSet[TopicMessageHandler]
+	// handlerTopics maps each registered handler ID to its topic
+	handlerTopics map[shared.SubscriptionID]string
+	// nextHandlerID is the sequence number of the next handler ID issued by AddTopicHandler
+	nextHandlerID uint64
 	// mutex protects concurrent access to subscriptions and handlers
 	mutex sync.RWMutex
 	// storage provides access to SHIP storage operations
@@ -72,31 +84,85 @@ type TopicManager struct {
 // NewTopicManager creates a new SHIP topic manager instance.
 // This constructor initializes the topic manager with the required dependencies
 // for managing overlay network topic subscriptions and message routing.
+// When a lookup service is provided, records it stores or deletes are published
+// as messages on the SHIP topic (tm_ship) with a types.RecordEvent payload.
 func NewTopicManager(storage StorageInterface, lookupService *LookupService) *TopicManager {
-	doc := TopicManagerDocumentation
-	return &TopicManager{
+	return newTopicManager(storage, lookupService, shared.Options{Topic: Topic, Documentation: TopicManagerDocumentation})
+}
+
+// NewTopicManagerWithOptions creates a new SHIP topic manager instance, customized by opts.
+// Without options it behaves like NewTopicManager. shared.WithTopic sets the topic the manager
+// is registered under in the engine, which record events are published on; the lookup service
+// should be created with the same topic. The manager answers no lookup service, so
+// shared.WithService is rejected with shared.ErrUnsupportedOption.
+func NewTopicManagerWithOptions(storage StorageInterface, lookupService *LookupService, opts ...shared.Option) (*TopicManager, error) {
+	o := shared.ApplyOptions(shared.Options{Topic: Topic, Documentation: TopicManagerDocumentation}, opts)
+	if o.Service != "" {
+		return nil, fmt.Errorf("%w: a SHIP topic manager has no service %q", shared.ErrUnsupportedOption, o.Service)
+	}
+	return newTopicManager(storage, lookupService, o), nil
+}
+
+// newTopicManager creates a SHIP topic manager from resolved options. Metadata left unset
+// keeps the defaults of each metadata method.
+func newTopicManager(storage StorageInterface, lookupService *LookupService, o shared.Options) *TopicManager {
+	tm := &TopicManager{
 		BaseTopicManagerOps: shared.NewBaseTopicManagerOps(shared.BaseTopicManagerConfig{
 			Admittance: shared.AdmittanceConfig{
-				Identifier:   "SHIP",
-				TopicPrefix:  "tm_",
-				EmojiAdmit:   "\U0001f6f3\ufe0f",
-				EmojiConsume: "\U0001f6a2",
-				EmojiNone:    "\u2693",
+				Identifier:    "SHIP",
+				TopicPrefix:   "tm_",
+				VerifyWorkers: o.VerifyWorkers,
+				Logger:        o.Logger,
 			},
-			MetaDataName:        "SHIP Topic Manager",
-			MetaDataDescription: "Manages SHIP protocol topics for service host interconnection and discovery",
-			Documentation:       &doc,
+			MetaDataName:        cmp.Or(o.MetaDataName, "SHIP Topic Manager"),
+			MetaDataDescription: cmp.Or(o.MetaDataDescription, "Manages SHIP protocol topics for service host interconnection and discovery"),
+			Documentation:       &o.Documentation,
+			Clock:               o.Clock,
 		}),
+		topic: o.Topic,
+		topicManagerMetaData: overlay.MetaData{
+			Name:        cmp.Or(o.MetaDataName, "SHIP Topic Manager"),
+			Description: cmp.Or(o.MetaDataDescription, "Manages overlay network topic subscriptions for SHIP protocol."),
+		},
 		subscriptions: make(map[string]*TopicSubscription),
-		handlers:      make(map[string]TopicMessageHandler),
+		handlers:      make(map[string]*shared.HandlerSet[TopicMessageHandler]),
+		handlerTopics: make(map[shared.SubscriptionID]string),
 		storage:       storage,
 		lookupService: lookupService,
 	}
+
+	if lookupService != nil {
+		lookupService.SetRecordEventListener(tm.publishRecordEvent)
+	}
+
+	return tm
+}
+
+// publishRecordEvent delivers a record event from the lookup service to the subscribers
+// of the topic manager's topic. Handler errors are logged rather than failing the storage operation.
+func (tm *TopicManager) publishRecordEvent(ctx context.Context, event types.RecordEvent) {
+	message := TopicMessage{
+		Topic:      tm.topic,
+		Payload:    event,
+		ReceivedAt: event.Timestamp,
+		MessageID:  fmt.Sprintf("%s:%s.%d", event.Type, event.Txid, event.OutputIndex),
+	}
+
+	if err := tm.HandleTopicMessage(ctx, message); err != nil {
+		shared.Logger(ctx, tm.Cfg.Admittance.Logger).Warn("Failed to deliver record event",
+			shared.LogKeyProtocol, Identifier,
+			shared.LogKeyTxid, event.Txid,
+			shared.LogKeyOutputIndex, event.OutputIndex,
+			shared.LogKeyError, err,
+		)
+	}
 }
 
 // SubscribeToTopic subscribes to a specific topic with a message handler.
 // Creates a new subscription if one doesn't exist, or updates an existing one.
 // The provided handler will be called for all messages received on this topic.
+// Calling SubscribeToTopic again for the same topic replaces the handler it registered
+// previously; handlers registered with AddTopicHandler are unaffected.
 func (tm *TopicManager) SubscribeToTopic(_ context.Context, topic string, handler TopicMessageHandler) error {
 	if topic == "" {
 		return errTopicNameEmpty
@@ -109,12 +175,65 @@ func (tm *TopicManager) SubscribeToTopic(_ context.Context, topic string, handle
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
+	tm.addHandler(topic, shared.SubscriberID(topic), handler)
+
+	return nil
+}
+
+// AddTopicHandler registers an additional message handler for a topic, creating or
+// reactivating the subscription as needed. Every handler registered on a topic receives
+// each message for it. The returned ID removes this handler alone via RemoveTopicHandler.
+func (tm *TopicManager) AddTopicHandler(_ context.Context, topic string, handler TopicMessageHandler) (shared.SubscriptionID, error) {
+	if topic == "" {
+		return "", errTopicNameEmpty
+	}
+
+	if handler == nil {
+		return "", errMessageHandlerNil
+	}
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.nextHandlerID++
+	id := shared.HandlerID(tm.nextHandlerID, topic)
+	tm.addHandler(topic, id, handler)
+
+	return id, nil
+}
+
+// RemoveTopicHandler removes a single handler registered with AddTopicHandler.
+// Other handlers on the topic keep receiving messages; the subscription is marked
+// inactive once its last handler is removed.
+func (tm *TopicManager) RemoveTopicHandler(_ context.Context, id shared.SubscriptionID) error {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	topic, exists := tm.handlerTopics[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", errHandlerNotFound, id)
+	}
+	delete(tm.handlerTopics, id)
+
+	handlers := tm.handlers[topic]
+	handlers.Remove(id)
+	if handlers.Len() == 0 {
+		delete(tm.handlers, topic)
+		tm.subscriptions[topic].IsActive = false
+	}
+
+	return nil
+}
+
+// addHandler registers the handler on the topic under the given ID and activates the
+// topic's subscription. The caller must hold the write lock.
+func (tm *TopicManager) addHandler(topic string, id shared.SubscriptionID, handler TopicMessageHandler) {
 	// Create or update subscription
 	subscription, exists := tm.subscriptions[topic]
 	if !exists {
 		subscription = &TopicSubscription{
 			Topic:        topic,
-			SubscribedAt: time.Now(),
+			SubscribedAt: shared.Now(tm.Cfg.Clock),
 			IsActive:     true,
 			MessageCount: 0,
 		}
@@ -124,14 +243,17 @@ func (tm *TopicManager) SubscribeToTopic(_ context.Context, topic string, handle
 		subscription.IsActive = true
 	}
 
-	// Set or update handler
-	tm.handlers[topic] = handler
-
-	return nil
+	handlers, exists := tm.handlers[topic]
+	if !exists {
+		handlers = &shared.HandlerSet[TopicMessageHandler]{}
+		tm.handlers[topic] = handlers
+	}
+	handlers.Set(id, handler)
+	tm.handlerTopics[id] = topic
 }
 
 // UnsubscribeFromTopic unsubscribes from a specific topic.
-// Marks the subscription as inactive and removes the message handler.
+// Marks the subscription as inactive and removes all of its message handlers.
 // The subscription record is kept for historical purposes.
 func (tm *TopicManager) UnsubscribeFromTopic(_ context.Context, topic string) error {
 	if topic == "" {
@@ -149,14 +271,20 @@ func (tm *TopicManager) UnsubscribeFromTopic(_ context.Context, topic string) er
 	// Mark subscription as inactive
 	subscription.IsActive = false
 
-	// Remove handler
-	delete(tm.handlers, topic)
+	// Remove handlers
+	if handlers, exists := tm.handlers[topic]; exists {
+		for _, id := range handlers.IDs() {
+			delete(tm.handlerTopics, id)
+		}
+		delete(tm.handlers, topic)
+	}
 
 	return nil
 }
 
 // HandleTopicMessage processes an incoming topic message.
-// Routes the message to the appropriate handler if one exists for the topic.
+// Delivers the message to every handler registered for the topic. A failing or panicking
+// handler does not prevent delivery to the others; their errors are combined in the result.
 // Updates message statistics for the topic.
 func (tm *TopicManager) HandleTopicMessage(ctx context.Context, message TopicMessage) error {
 	if message.Topic == "" {
@@ -165,7 +293,10 @@ func (tm *TopicManager) HandleTopicMessage(ctx context.Context, message TopicMes
 
 	tm.mutex.RLock()
 	subscription, subscriptionExists := tm.subscriptions[message.Topic]
-	handler, handlerExists := tm.handlers[message.Topic]
+	var handlers []TopicMessageHandler
+	if set, exists := tm.handlers[message.Topic]; exists {
+		handlers = set.Handlers()
+	}
 	isActive := subscriptionExists && subscription.IsActive
 	tm.mutex.RUnlock()
 
@@ -175,7 +306,7 @@ func (tm *TopicManager) HandleTopicMessage(ctx context.Context, message TopicMes
 		return nil
 	}
 
-	if !handlerExists {
+	if len(handlers) == 0 {
 		return fmt.Errorf("%w: %s", errNoHandlerFoundForTopic, message.Topic)
 	}
 
@@ -185,7 +316,10 @@ func (tm *TopicManager) HandleTopicMessage(ctx context.Context, message TopicMes
 	tm.mutex.Unlock()
 
 	// Handle the message
-	if err := handler(ctx, message); err != nil {
+	err := shared.DeliverToHandlers(handlers, func(handler TopicMessageHandler) error {
+		return handler(ctx, message)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to handle message for topic %s: %w", message.Topic, err)
 	}
 
@@ -226,7 +360,7 @@ func (tm *TopicManager) CreateTopicSubscription(_ context.Context, topic string)
 	// Create new subscription
 	subscription := &TopicSubscription{
 		Topic:        topic,
-		SubscribedAt: time.Now(),
+		SubscribedAt: shared.Now(tm.Cfg.Clock),
 		IsActive:     false, // Not active until a handler is set
 		MessageCount: 0,
 	}
@@ -269,18 +403,35 @@ func (tm *TopicManager) Close(_ context.Context) error {
 	}
 
 	// Clear all handlers
-	tm.handlers = make(map[string]TopicMessageHandler)
+	tm.handlers = make(map[string]*shared.HandlerSet[TopicMessageHandler])
+	tm.handlerTopics = make(map[shared.SubscriptionID]string)
 
 	return nil
 }
 
+// Reindex rebuilds SHIP storage from an archive of Atomic BEEF, applying the same admittance
+// checks and policy as IdentifyAdmissibleOutputs and storing records through the lookup service.
+// Outputs that already have a record are skipped, so a pass can be safely rerun. Rate limits
+// set with shared.RateLimitPolicy do not apply to the archived outputs (see
+// shared.BaseLookupService.Reindex).
+func (tm *TopicManager) Reindex(ctx context.Context, source shared.ReindexSource) (shared.ReindexReport, error) {
+	if tm.lookupService == nil {
+		return shared.ReindexReport{}, errNoLookupService
+	}
+	return tm.lookupService.Reindex(ctx, source, tm.Cfg.Admittance)
+}
+
 // GetTopicManagerMetaData returns metadata information for the SHIP topic manager.
-// This provides basic information about the topic manager service.
+// This provides basic information about the topic manager service, unless overridden
+// by shared.WithMetaData.
 func (tm *TopicManager) GetTopicManagerMetaData() overlay.MetaData {
-	return overlay.MetaData{
-		Name:        "SHIP Topic Manager",
-		Description: "Manages overlay network topic subscriptions for SHIP protocol.",
-	}
+	return tm.topicManagerMetaData
+}
+
+// Topic returns the topic record events are published on: Topic unless configured
+// with shared.WithTopic.
+func (tm *TopicManager) Topic() string {
+	return tm.topic
 }
 
 // GetActiveTopicCount returns the number of currently active topic subscriptions.
@@ -0,0 +1,50 @@
+package ship
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+)
+
+// benchmarkOutputs is the number of signed outputs in the benchmark transaction.
+const benchmarkOutputs = 8
+
+// benchmarkAdmittance runs IdentifyAdmissibleOutputs against an eight-output transaction
+// on a topic manager configured by setup.
+func benchmarkAdmittance(b *testing.B, setup func(*TopicManager)) {
+	topics := make([]string, benchmarkOutputs)
+	for i := range topics {
+		topics[i] = "tm_bench"
+	}
+	beef, txid, _ := createSignedSHIPBEEF(b, "https://example.com", topics...)
+
+	topicManager := createTestSHIPTopicManager()
+	setup(topicManager)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		instructions, err := topicManager.IdentifyAdmissibleOutputs(ctx, beef, txid, nil)
+		if err != nil || len(instructions.OutputsToAdmit) != benchmarkOutputs {
+			b.Fatalf("unexpected admittance result: %v, %v", instructions.OutputsToAdmit, err)
+		}
+	}
+}
+
+func BenchmarkIdentifyAdmissibleOutputs_Sequential(b *testing.B) {
+	benchmarkAdmittance(b, func(*TopicManager) {})
+}
+
+func BenchmarkIdentifyAdmissibleOutputs_Concurrent(b *testing.B) {
+	benchmarkAdmittance(b, func(tm *TopicManager) {
+		tm.SetVerifyWorkers(4)
+	})
+}
+
+func BenchmarkIdentifyAdmissibleOutputs_Cached(b *testing.B) {
+	benchmarkAdmittance(b, func(tm *TopicManager) {
+		tm.SetSignatureCache(shared.NewSignatureCache(shared.DefaultSignatureCacheSize))
+	})
+}
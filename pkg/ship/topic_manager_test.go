@@ -1,14 +1,30 @@
 package ship
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/internal/tokentest"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
 // Static error variables for testing
@@ -55,6 +71,18 @@ func createMockHandler(called *bool, shouldError bool) TopicMessageHandler {
 	}
 }
 
+// createSignedSHIPBEEF builds a transaction with one correctly signed SHIP token output per topic,
+// all advertised by the same identity on the given domain. Returns the BEEF, the txid and
+// the hex identity key.
+func createSignedSHIPBEEF(t testing.TB, domain string, topics ...string) (*transaction.Beef, *chainhash.Hash, string) {
+	t.Helper()
+	advertisements := make([]tokentest.Advertisement, len(topics))
+	for i, topic := range topics {
+		advertisements[i] = tokentest.Advertisement{Domain: domain, Name: topic}
+	}
+	return tokentest.SignedBEEF(t, overlay.ProtocolSHIP, advertisements...)
+}
+
 // Test NewTopicManager
 
 func TestNewSHIPTopicManager(t *testing.T) {
@@ -81,6 +109,57 @@ func TestNewSHIPTopicManagerWithLookupService(t *testing.T) {
 	assert.NotNil(t, topicManager.handlers)
 }
 
+func TestNewSHIPTopicManagerWithOptions(t *testing.T) {
+	mockStorage := new(MockStorage)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := shared.ClockFunc(func() time.Time { return now })
+	lookupService := NewLookupServiceWithOptions(mockStorage, shared.WithTopic("tm_ship_test"), shared.WithClock(clock))
+
+	topicManager, err := NewTopicManagerWithOptions(mockStorage, lookupService,
+		shared.WithTopic("tm_ship_test"),
+		shared.WithMetaData("Test SHIP", "SHIP admittance for tests"),
+		shared.WithDocumentation("# Test SHIP"),
+		shared.WithClock(clock),
+		shared.WithVerifyWorkers(4),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test SHIP", topicManager.GetMetaData().Name)
+	assert.Equal(t, "SHIP admittance for tests", topicManager.GetMetaData().Description)
+	assert.Equal(t, "Test SHIP", topicManager.GetTopicManagerMetaData().Name)
+	assert.Equal(t, "SHIP admittance for tests", topicManager.GetTopicManagerMetaData().Description)
+	assert.Equal(t, "# Test SHIP", topicManager.GetDocumentation())
+	assert.Equal(t, 4, topicManager.Cfg.Admittance.VerifyWorkers)
+	assert.Equal(t, "tm_ship_test", topicManager.Topic())
+
+	var received []TopicMessage
+	require.NoError(t, topicManager.SubscribeToTopic(context.Background(), "tm_ship_test", func(_ context.Context, message TopicMessage) error {
+		received = append(received, message)
+		return nil
+	}))
+	subscriptions := topicManager.GetSubscribedTopics()
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, now, subscriptions[0].SubscribedAt)
+
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 0).Return(nil, nil)
+	mockStorage.On("DeleteSHIPRecord", mock.Anything, TxID, 0).Return(nil)
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+	require.NoError(t, lookupService.OutputEvicted(context.Background(), &transaction.Outpoint{Txid: txidArray, Index: 0}))
+
+	require.Len(t, received, 1)
+	assert.Equal(t, "tm_ship_test", received[0].Topic)
+	assert.Equal(t, now, received[0].ReceivedAt)
+}
+
+func TestNewSHIPTopicManagerWithOptions_RejectsService(t *testing.T) {
+	topicManager, err := NewTopicManagerWithOptions(new(MockStorage), nil, shared.WithService("ls_custom"))
+	require.ErrorIs(t, err, shared.ErrUnsupportedOption)
+	assert.Nil(t, topicManager)
+}
+
 // Test SubscribeToTopic
 
 func TestSubscribeToTopic_Success(t *testing.T) {
@@ -285,6 +364,253 @@ func TestHandleTopicMessage_HandlerError(t *testing.T) {
 	assert.Equal(t, int64(1), topicManager.GetTopicMessageCount("tm_test"))
 }
 
+// Test AddTopicHandler and RemoveTopicHandler
+
+func TestAddTopicHandler_FanOut(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	ctx := context.Background()
+
+	firstCalled, secondCalled, legacyCalled := false, false, false
+	firstID, err := topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&firstCalled, false))
+	require.NoError(t, err)
+	secondID, err := topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&secondCalled, false))
+	require.NoError(t, err)
+	require.NoError(t, topicManager.SubscribeToTopic(ctx, "tm_test", createMockHandler(&legacyCalled, false)))
+	assert.NotEqual(t, firstID, secondID)
+
+	err = topicManager.HandleTopicMessage(ctx, createTestTopicMessage("tm_test", "msg-1", "test payload"))
+
+	require.NoError(t, err)
+	assert.True(t, firstCalled)
+	assert.True(t, secondCalled)
+	assert.True(t, legacyCalled)
+	assert.Equal(t, int64(1), topicManager.GetTopicMessageCount("tm_test"))
+}
+
+func TestAddTopicHandler_InvalidArguments(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	handlerCalled := false
+
+	_, err := topicManager.AddTopicHandler(context.Background(), "", createMockHandler(&handlerCalled, false))
+	require.ErrorIs(t, err, errTopicNameEmpty)
+
+	_, err = topicManager.AddTopicHandler(context.Background(), "tm_test", nil)
+	require.ErrorIs(t, err, errMessageHandlerNil)
+}
+
+func TestHandleTopicMessage_HandlerErrorIsolation(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	ctx := context.Background()
+
+	failingCalled, healthyCalled := false, false
+	_, err := topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&failingCalled, true))
+	require.NoError(t, err)
+	_, err = topicManager.AddTopicHandler(ctx, "tm_test", func(_ context.Context, _ TopicMessage) error {
+		panic("handler exploded")
+	})
+	require.NoError(t, err)
+	_, err = topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&healthyCalled, false))
+	require.NoError(t, err)
+
+	err = topicManager.HandleTopicMessage(ctx, createTestTopicMessage("tm_test", "msg-1", "test payload"))
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errTestHandler)
+	require.ErrorIs(t, err, shared.ErrHandlerPanicked)
+	assert.Contains(t, err.Error(), "handler exploded")
+	assert.True(t, failingCalled)
+	assert.True(t, healthyCalled)
+}
+
+func TestRemoveTopicHandler_Independent(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	ctx := context.Background()
+
+	firstCalled, secondCalled := false, false
+	firstID, err := topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&firstCalled, false))
+	require.NoError(t, err)
+	secondID, err := topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&secondCalled, false))
+	require.NoError(t, err)
+
+	require.NoError(t, topicManager.RemoveTopicHandler(ctx, firstID))
+	assert.True(t, topicManager.IsSubscribedToTopic("tm_test"))
+
+	require.NoError(t, topicManager.HandleTopicMessage(ctx, createTestTopicMessage("tm_test", "msg-1", "test payload")))
+	assert.False(t, firstCalled)
+	assert.True(t, secondCalled)
+
+	// Removing the last handler deactivates the subscription
+	require.NoError(t, topicManager.RemoveTopicHandler(ctx, secondID))
+	assert.False(t, topicManager.IsSubscribedToTopic("tm_test"))
+	assert.Empty(t, topicManager.handlers)
+
+	err = topicManager.RemoveTopicHandler(ctx, secondID)
+	require.ErrorIs(t, err, errHandlerNotFound)
+}
+
+func TestRemoveTopicHandler_DoesNotCollideWithSubscribedTopic(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	ctx := context.Background()
+
+	// A topic named like a generated handler ID must not share its subscription ID
+	subscribedCalled, addedCalled := false, false
+	id, err := topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&addedCalled, false))
+	require.NoError(t, err)
+	require.NoError(t, topicManager.SubscribeToTopic(ctx, "tm_test#1", createMockHandler(&subscribedCalled, false)))
+
+	require.NoError(t, topicManager.RemoveTopicHandler(ctx, id))
+	assert.False(t, topicManager.IsSubscribedToTopic("tm_test"))
+	assert.True(t, topicManager.IsSubscribedToTopic("tm_test#1"))
+
+	require.NoError(t, topicManager.HandleTopicMessage(ctx, createTestTopicMessage("tm_test#1", "msg-1", "test payload")))
+	assert.True(t, subscribedCalled)
+	assert.False(t, addedCalled)
+}
+
+func TestUnsubscribeFromTopic_RemovesAllHandlers(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	ctx := context.Background()
+
+	handlerCalled := false
+	id, err := topicManager.AddTopicHandler(ctx, "tm_test", createMockHandler(&handlerCalled, false))
+	require.NoError(t, err)
+	require.NoError(t, topicManager.SubscribeToTopic(ctx, "tm_test", createMockHandler(&handlerCalled, false)))
+
+	require.NoError(t, topicManager.UnsubscribeFromTopic(ctx, "tm_test"))
+
+	assert.Empty(t, topicManager.handlers)
+	assert.Empty(t, topicManager.handlerTopics)
+	require.ErrorIs(t, topicManager.RemoveTopicHandler(ctx, id), errHandlerNotFound)
+}
+
+// Test admittance policy
+
+func TestIdentifyAdmissibleOutputs_NoPolicy(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_foo", "tm_bar")
+
+	instructions, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, 1}, instructions.OutputsToAdmit)
+}
+
+func TestIdentifyAdmissibleOutputs_TopicPolicy(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	topicManager.SetAdmittancePolicy(shared.NewTopicPolicy([]string{"tm_bar"}, nil))
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_foo", "tm_bar")
+
+	instructions, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1}, instructions.OutputsToAdmit)
+}
+
+func TestIdentifyAdmissibleOutputs_IdentityKeyDenied(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	beef, txid, identityKey := createSignedSHIPBEEF(t, "https://example.com", "tm_foo")
+	topicManager.SetAdmittancePolicy(shared.ChainAdmittancePolicies(
+		shared.NewDomainPolicy(nil, []string{"https://blocked.example.com"}),
+		shared.NewIdentityKeyPolicy(nil, []string{identityKey}),
+	))
+
+	instructions, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, instructions.OutputsToAdmit)
+}
+
+func TestIdentifyAdmissibleOutputs_RateLimitPolicy(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	policy, err := shared.NewRateLimitPolicy(2, time.Hour, nil)
+	require.NoError(t, err)
+	topicManager.SetAdmittancePolicy(policy)
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_one", "tm_two", "tm_three")
+
+	instructions, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, 1}, instructions.OutputsToAdmit)
+
+	// Resubmitting the transaction admits the same outputs without exhausting the limit further
+	instructions, err = topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, 1}, instructions.OutputsToAdmit)
+}
+
+func TestIdentifyAdmissibleOutputsWithReport_RejectionReasons(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	topicManager.SetAdmittancePolicy(shared.NewTopicPolicy(nil, []string{"tm_denied"}))
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_foo", "ls_foo", "tm_denied")
+
+	report, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.True(t, report.TransactionFound)
+	assert.Equal(t, txid.String(), report.Txid)
+	assert.Equal(t, []uint32{0}, report.Instructions.OutputsToAdmit)
+	require.Len(t, report.Rejections, 2)
+	assert.Equal(t, uint32(1), report.Rejections[0].OutputIndex)
+	assert.Equal(t, shared.RejectionInvalidTopic, report.Rejections[0].Reason)
+	assert.Equal(t, uint32(2), report.Rejections[1].OutputIndex)
+	assert.Equal(t, shared.RejectionPolicy, report.Rejections[1].Reason)
+	assert.Contains(t, report.Rejections[1].Detail, "tm_denied is denied")
+
+	stats := topicManager.GetAdmittanceStats()
+	assert.Equal(t, uint64(1), stats.Admitted)
+	assert.Equal(t, uint64(1), stats.Rejected[shared.RejectionInvalidTopic])
+	assert.Equal(t, uint64(1), stats.Rejected[shared.RejectionPolicy])
+}
+
+func TestIdentifyAdmissibleOutputsWithReport_UnsignedToken(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+
+	fields := [][]byte{[]byte("SHIP"), {0x01, 0x02}, []byte("https://example.com"), []byte("tm_foo")}
+	scriptObj, err := script.NewFromHex(createValidPushDropScript(fields))
+	require.NoError(t, err)
+	beefBytes, _, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+	beef, txid, err := transaction.NewBeefFromAtomicBytes(beefBytes)
+	require.NoError(t, err)
+
+	report, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, report.Instructions.OutputsToAdmit)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, shared.RejectionFieldCount, report.Rejections[0].Reason)
+	assert.Equal(t, "expected 5 fields, got 4", report.Rejections[0].Detail)
+}
+
+func TestIdentifyAdmissibleOutputs_Logger(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	var logs bytes.Buffer
+	topicManager.SetLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_foo", "ls_foo")
+
+	_, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var event map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "Output not admitted", events[0]["msg"])
+	assert.Equal(t, "SHIP", events[0][shared.LogKeyProtocol])
+	assert.Equal(t, txid.String(), events[0][shared.LogKeyTxid])
+	assert.InDelta(t, 1, events[0][shared.LogKeyOutputIndex], 0)
+	assert.Equal(t, string(shared.RejectionInvalidTopic), events[0][shared.LogKeyReason])
+
+	assert.Equal(t, "Admittance checked", events[1]["msg"])
+	assert.InDelta(t, 1, events[1]["admitted"], 0)
+	assert.InDelta(t, 1, events[1]["rejected"], 0)
+}
+
 // Test CreateTopicSubscription
 
 func TestCreateTopicSubscription_Success(t *testing.T) {
@@ -640,3 +966,293 @@ func TestIntegrationWithLookupService(t *testing.T) {
 	// Storage should be the same instance
 	assert.Equal(t, mockStorage, topicManager.storage)
 }
+
+func TestAdmittedRecordPublishedToSHIPTopic(t *testing.T) {
+	topicManager, mockStorage, lookupService := createTestSHIPTopicManagerWithLookupService()
+
+	var received []TopicMessage
+	err := topicManager.SubscribeToTopic(context.Background(), Topic, func(_ context.Context, message TopicMessage) error {
+		received = append(received, message)
+		return nil
+	})
+	require.NoError(t, err)
+
+	fields := [][]byte{
+		[]byte("SHIP"),
+		{0x01, 0x02, 0x03, 0x04},
+		[]byte("https://example.com"),
+		[]byte("tm_bridge"),
+	}
+	scriptObj, err := script.NewFromHex(createValidPushDropScript(fields))
+	require.NoError(t, err)
+	beefBytes, txidHex, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+
+	mockStorage.On("StoreSHIPRecord", mock.Anything, txidHex, 0, "01020304", "https://example.com", "tm_bridge").Return(nil)
+
+	err = lookupService.OutputAdmittedByTopic(context.Background(), &engine.OutputAdmittedByTopic{
+		Topic:       Topic,
+		OutputIndex: 0,
+		AtomicBEEF:  beefBytes,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, received, 1)
+	assert.Equal(t, Topic, received[0].Topic)
+	event, ok := received[0].Payload.(types.RecordEvent)
+	require.True(t, ok)
+	assert.Equal(t, types.RecordEventAdmitted, event.Type)
+	assert.Equal(t, "SHIP", event.Protocol)
+	assert.Equal(t, txidHex, event.Txid)
+	assert.Equal(t, "tm_bridge", event.Topic)
+	assert.Equal(t, "01020304", event.IdentityKey)
+	assert.Equal(t, int64(1), topicManager.GetTopicMessageCount(Topic))
+}
+
+// Test Reindex
+
+func TestReindex_StoresAdmissibleOutputsIdempotently(t *testing.T) {
+	topicManager, mockStorage, _ := createTestSHIPTopicManagerWithLookupService()
+
+	beef, txid, identityKey := createSignedSHIPBEEF(t, "https://example.com", "tm_bridge", "not a topic")
+	atomicBEEF, err := beef.AtomicBytes(txid)
+	require.NoError(t, err)
+	txidHex := hex.EncodeToString(txid.CloneBytes())
+
+	entry := fmt.Sprintf(`{"beef":%q}`, hex.EncodeToString(atomicBEEF))
+	archive := entry + "\n" + entry + "\n" + `{"beef":"zz"}` + "\n"
+
+	stored := &types.SHIPRecord{Txid: txidHex, OutputIndex: 0}
+	mockStorage.On("FindByOutpoint", mock.Anything, txidHex, 0).Return(nil, nil).Once()
+	mockStorage.On("FindByOutpoint", mock.Anything, txidHex, 0).Return(stored, nil).Once()
+	mockStorage.On("StoreSHIPRecord", mock.Anything, txidHex, 0, identityKey, "https://example.com", "tm_bridge").Return(nil).Once()
+
+	report, err := topicManager.Reindex(context.Background(), shared.NewStreamReindexSource("archive", strings.NewReader(archive)))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Transactions)
+	assert.Equal(t, 1, report.Stored)
+	assert.Equal(t, 1, report.AlreadyStored)
+	require.Len(t, report.Rejections, 2)
+	assert.Equal(t, uint32(1), report.Rejections[0].OutputIndex)
+	assert.Equal(t, shared.RejectionInvalidTopic, report.Rejections[0].Reason)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "archive line 3")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestReindex_SkipsCorruptLine(t *testing.T) {
+	topicManager, mockStorage, _ := createTestSHIPTopicManagerWithLookupService()
+
+	beef, txid, identityKey := createSignedSHIPBEEF(t, "https://example.com", "tm_bridge")
+	atomicBEEF, err := beef.AtomicBytes(txid)
+	require.NoError(t, err)
+	txidHex := hex.EncodeToString(txid.CloneBytes())
+
+	entry := fmt.Sprintf(`{"beef":%q}`, hex.EncodeToString(atomicBEEF))
+	archive := entry + "\n" + `{"beef": "truncated` + "\n\n" + entry + "\n"
+
+	mockStorage.On("FindByOutpoint", mock.Anything, txidHex, 0).Return(nil, nil).Once()
+	mockStorage.On("FindByOutpoint", mock.Anything, txidHex, 0).Return(&types.SHIPRecord{Txid: txidHex}, nil).Once()
+	mockStorage.On("StoreSHIPRecord", mock.Anything, txidHex, 0, identityKey, "https://example.com", "tm_bridge").Return(nil).Once()
+
+	report, err := topicManager.Reindex(context.Background(), shared.NewStreamReindexSource("archive", strings.NewReader(archive)))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Transactions)
+	assert.Equal(t, 1, report.Stored)
+	assert.Equal(t, 1, report.AlreadyStored)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "archive line 2")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestReindex_RequestedOutputIndexes(t *testing.T) {
+	topicManager, mockStorage, _ := createTestSHIPTopicManagerWithLookupService()
+
+	beef, txid, identityKey := createSignedSHIPBEEF(t, "https://example.com", "tm_one", "tm_two")
+	atomicBEEF, err := beef.AtomicBytes(txid)
+	require.NoError(t, err)
+	txidHex := hex.EncodeToString(txid.CloneBytes())
+
+	archive := fmt.Sprintf(`{"beef":%q,"outputIndexes":[1,5]}`, hex.EncodeToString(atomicBEEF))
+
+	mockStorage.On("FindByOutpoint", mock.Anything, txidHex, 1).Return(nil, nil)
+	mockStorage.On("StoreSHIPRecord", mock.Anything, txidHex, 1, identityKey, "https://example.com", "tm_two").Return(nil)
+
+	report, err := topicManager.Reindex(context.Background(), shared.NewStreamReindexSource("archive", strings.NewReader(archive)))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Stored)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "output 5 not found")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestReindex_StorageErrorAborts(t *testing.T) {
+	topicManager, mockStorage, _ := createTestSHIPTopicManagerWithLookupService()
+
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_bridge")
+	atomicBEEF, err := beef.AtomicBytes(txid)
+	require.NoError(t, err)
+
+	archive := fmt.Sprintf(`{"beef":%q}`, hex.EncodeToString(atomicBEEF))
+	mockStorage.On("FindByOutpoint", mock.Anything, mock.Anything, 0).Return(nil, errTestHandler)
+
+	_, err = topicManager.Reindex(context.Background(), shared.NewStreamReindexSource("archive", strings.NewReader(archive)))
+	require.ErrorIs(t, err, errTestHandler)
+}
+
+func TestReindex_RequiresLookupService(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+
+	_, err := topicManager.Reindex(context.Background(), shared.NewStreamReindexSource("archive", strings.NewReader("")))
+	require.ErrorIs(t, err, errNoLookupService)
+}
+
+// Test signature verification cache and concurrent verification
+
+// createMixedSHIPBEEF returns a transaction with four correctly signed outputs, a forged
+// output whose signature is not linked to its identity key, and an output with an invalid topic.
+func createMixedSHIPBEEF(t testing.TB) (*transaction.Beef, *chainhash.Hash) {
+	t.Helper()
+
+	signedBEEF, signedTxid, identityKey := createSignedSHIPBEEF(t, "https://example.com", "tm_one", "tm_two", "tm_three", "tm_four", "bad topic")
+	signedTx := signedBEEF.FindTransactionByHash(signedTxid)
+	require.NotNil(t, signedTx)
+
+	identityBytes, err := hex.DecodeString(identityKey)
+	require.NoError(t, err)
+	forgedSignature := []byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01}
+	forged, err := script.NewFromHex(createValidPushDropScript([][]byte{
+		[]byte("SHIP"), identityBytes, []byte("https://example.com"), []byte("tm_forged"), forgedSignature,
+	}))
+	require.NoError(t, err)
+
+	tx := transaction.NewTransaction()
+	for _, output := range signedTx.Outputs[:4] {
+		tx.AddOutput(output)
+	}
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1, LockingScript: forged})
+	tx.AddOutput(signedTx.Outputs[4])
+
+	beef, err := transaction.NewBeefFromTransaction(tx)
+	require.NoError(t, err)
+	return beef, tx.TxID()
+}
+
+func TestIdentifyAdmissibleOutputs_SignatureCache(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	cache := shared.NewSignatureCache(100)
+	topicManager.SetSignatureCache(cache)
+	beef, txid := createMixedSHIPBEEF(t)
+
+	first, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, 1, 2, 3}, first.Instructions.OutputsToAdmit)
+	require.Len(t, first.Rejections, 2)
+	assert.Equal(t, shared.RejectionInvalidSignature, first.Rejections[0].Reason)
+	assert.Equal(t, uint32(4), first.Rejections[0].OutputIndex)
+	assert.Equal(t, shared.RejectionInvalidTopic, first.Rejections[1].Reason)
+	assert.Equal(t, shared.SignatureCacheStats{Hits: 0, Misses: 5, Size: 5}, cache.Stats())
+
+	// A resubmission is answered entirely from the cache with the same outcome
+	second, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, shared.SignatureCacheStats{Hits: 5, Misses: 5, Size: 5}, cache.Stats())
+}
+
+func TestIdentifyAdmissibleOutputs_URISchemes(t *testing.T) {
+	registry := utils.NewURISchemeRegistry()
+	require.NoError(t, registry.Register(utils.URIScheme{
+		Prefix:    "https+custom://",
+		Family:    utils.SchemeFamilyHTTPS,
+		Transport: utils.TransportHTTPS,
+		Auth:      utils.AuthNone,
+		Validate:  utils.NewHTTPSFamilyValidator("https+custom://"),
+	}))
+	require.NoError(t, registry.Disable("https://"))
+
+	topicManager := createTestSHIPTopicManager()
+
+	// The default registry knows nothing of the custom scheme
+	customBEEF, customTxid, _ := createSignedSHIPBEEF(t, "https+custom://example.com", "tm_foo")
+	report, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), customBEEF, customTxid, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, shared.RejectionInvalidURI, report.Rejections[0].Reason)
+
+	topicManager.SetURISchemes(registry)
+
+	report, err = topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), customBEEF, customTxid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0}, report.Instructions.OutputsToAdmit)
+
+	httpsBEEF, httpsTxid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_foo")
+	report, err = topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), httpsBEEF, httpsTxid, nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.Instructions.OutputsToAdmit)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, shared.RejectionInvalidURI, report.Rejections[0].Reason)
+	assert.Contains(t, report.Rejections[0].Detail, utils.ErrURIUnsupportedScheme.Error())
+}
+
+func TestIdentifyAdmissibleOutputs_NamingPolicy(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_brc100", "tm_ship")
+
+	// The default policy rejects digits
+	report, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1}, report.Instructions.OutputsToAdmit)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, shared.RejectionInvalidTopic, report.Rejections[0].Reason)
+	assert.Contains(t, report.Rejections[0].Detail, utils.ErrNameInvalidChars.Error())
+
+	policy, err := utils.NewNamingPolicy(utils.NamingPolicyConfig{
+		AllowedChars: utils.LowercaseLetters + utils.Digits,
+		Reserved:     []string{"tm_ship"},
+	})
+	require.NoError(t, err)
+	topicManager.SetNamingPolicy(policy)
+
+	report, err = topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0}, report.Instructions.OutputsToAdmit)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, uint32(1), report.Rejections[0].OutputIndex)
+	assert.Contains(t, report.Rejections[0].Detail, utils.ErrNameReserved.Error())
+}
+
+func TestIdentifyAdmissibleOutputs_SignatureCacheEviction(t *testing.T) {
+	topicManager := createTestSHIPTopicManager()
+	cache := shared.NewSignatureCache(2)
+	topicManager.SetSignatureCache(cache)
+	beef, txid, _ := createSignedSHIPBEEF(t, "https://example.com", "tm_one", "tm_two", "tm_three")
+
+	instructions, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, 1, 2}, instructions.OutputsToAdmit)
+	assert.Equal(t, 2, cache.Stats().Size)
+
+	// The first output was evicted, so only the last two are cache hits
+	_, err = topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), cache.Stats().Misses)
+}
+
+func TestIdentifyAdmissibleOutputs_ConcurrentVerification(t *testing.T) {
+	beef, txid := createMixedSHIPBEEF(t)
+
+	sequential := createTestSHIPTopicManager()
+	expected, err := sequential.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+
+	concurrent := createTestSHIPTopicManager()
+	concurrent.SetVerifyWorkers(3)
+	actual, err := concurrent.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, actual)
+}
@@ -6,17 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
 	"github.com/bsv-blockchain/go-sdk/chainhash"
 	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
 	"github.com/bsv-blockchain/go-sdk/script"
 	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/pushdrop"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
 const TxID = "bdf1e48e845a65ba8c139c9b94844de30716f38d53787ba0a435e8705c4216d5"
@@ -38,6 +42,16 @@ func (m *MockStorage) StoreSLAPRecord(ctx context.Context, txid string, outputIn
 	return args.Error(0)
 }
 
+func (m *MockStorage) StoreSLAPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial) error {
+	args := m.Called(ctx, txid, outputIndex, identityKey, domain, service, token)
+	return args.Error(0)
+}
+
+func (m *MockStorage) StoreSLAPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial, createdAt time.Time) error {
+	args := m.Called(ctx, txid, outputIndex, identityKey, domain, service, token, createdAt)
+	return args.Error(0)
+}
+
 func (m *MockStorage) DeleteSLAPRecord(ctx context.Context, txid string, outputIndex int) error {
 	args := m.Called(ctx, txid, outputIndex)
 	return args.Error(0)
@@ -48,11 +62,46 @@ func (m *MockStorage) FindRecord(ctx context.Context, query types.SLAPQuery) ([]
 	return args.Get(0).([]types.UTXOReference), args.Error(1)
 }
 
+func (m *MockStorage) QueryRecords(ctx context.Context, query types.SLAPQuery) ([]types.SLAPRecord, error) {
+	args := m.Called(ctx, query)
+	records, _ := args.Get(0).([]types.SLAPRecord)
+	return records, args.Error(1)
+}
+
 func (m *MockStorage) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
 	args := m.Called(ctx, limit, skip, sortOrder)
 	return args.Get(0).([]types.UTXOReference), args.Error(1)
 }
 
+func (m *MockStorage) FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SLAPRecord, error) {
+	args := m.Called(ctx, txid, outputIndex)
+	record, _ := args.Get(0).(*types.SLAPRecord)
+	return record, args.Error(1)
+}
+
+func (m *MockStorage) ListRecords(ctx context.Context, limit, skip *int) ([]types.SLAPRecord, error) {
+	args := m.Called(ctx, limit, skip)
+	records, _ := args.Get(0).([]types.SLAPRecord)
+	return records, args.Error(1)
+}
+
+func (m *MockStorage) ForEachRecord(ctx context.Context, fn func(types.SLAPRecord) error) error {
+	args := m.Called(ctx)
+	records, _ := args.Get(0).([]types.SLAPRecord)
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockStorage) ImportRecords(ctx context.Context, records []types.SLAPRecord) error {
+	// Copy the batch, which the caller reuses after the call returns
+	args := m.Called(ctx, append([]types.SLAPRecord(nil), records...))
+	return args.Error(0)
+}
+
 func (m *MockStorage) EnsureIndexes(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -129,6 +178,31 @@ func TestNewSLAPLookupService(t *testing.T) {
 	assert.Equal(t, mockStorage, service.storage)
 }
 
+func TestNewSLAPLookupServiceWithOptions(t *testing.T) {
+	mockStorage := new(MockStorage)
+
+	service := NewLookupServiceWithOptions(mockStorage,
+		shared.WithService("ls_slap_test"),
+		shared.WithMetaData("Test SLAP", "SLAP lookups for tests"),
+		shared.WithDocumentation("# Test SLAP"),
+		shared.WithMaxResults(10),
+	)
+
+	assert.Equal(t, "ls_slap_test", service.ServiceName())
+	assert.Equal(t, "Test SLAP", service.GetMetaData().Name)
+	assert.Equal(t, "SLAP lookups for tests", service.GetMetaData().Description)
+	assert.Equal(t, "# Test SLAP", service.GetDocumentation())
+
+	limit := 10
+	mockStorage.On("FindAll", mock.Anything, &limit, (*int)(nil), (*types.SortOrder)(nil)).Return([]types.UTXOReference{}, nil)
+	_, err := service.Lookup(context.Background(), &lookup.LookupQuestion{
+		Service: "ls_slap_test",
+		Query:   json.RawMessage(`{"findAll":true,"limit":100}`),
+	})
+	require.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+}
+
 // Test OutputAdmittedByTopic
 
 func TestOutputAdmittedByTopic_Success(t *testing.T) {
@@ -251,6 +325,82 @@ func TestOutputAdmittedByTopic_IgnoreNonSLAPProtocol(t *testing.T) {
 	require.NoError(t, err) // Should silently ignore non-SLAP protocols
 }
 
+// Test Reverify
+
+func TestOutputAdmittedByTopic_NamingPolicy(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+	policy, err := utils.NewNamingPolicy(utils.NamingPolicyConfig{Reserved: []string{"ls_slap"}})
+	require.NoError(t, err)
+	service.SetNamingPolicy(policy)
+
+	for _, name := range []string{"ls_treasury", "ls_slap"} {
+		fields := [][]byte{[]byte("SLAP"), {0x01, 0x02, 0x03, 0x04}, []byte("https://example.com"), []byte(name)}
+		scriptObj, err := script.NewFromHex(createValidPushDropScript(fields))
+		require.NoError(t, err)
+		beefBytes, _, err := createTestBEEFWithScript(scriptObj)
+		require.NoError(t, err)
+
+		mockStorage.On("StoreSLAPRecord", mock.Anything, mock.Anything, 0, "01020304", "https://example.com", name).Return(nil).Maybe()
+		require.NoError(t, service.OutputAdmittedByTopic(context.Background(), &engine.OutputAdmittedByTopic{
+			Topic:       Topic,
+			OutputIndex: 0,
+			AtomicBEEF:  beefBytes,
+		}))
+	}
+
+	mockStorage.AssertNumberOfCalls(t, "StoreSLAPRecord", 1)
+	mockStorage.AssertCalled(t, "StoreSLAPRecord", mock.Anything, mock.Anything, 0, "01020304", "https://example.com", "ls_treasury")
+}
+
+func TestReverify_PagesThroughStorage(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+
+	beef, txid, identityKey := createSignedSLAPBEEF(t, "ls_foo", "https://example.com")
+	result := pushdrop.Decode(beef.FindTransactionByHash(txid).Outputs[0].LockingScript)
+	require.NotNil(t, result)
+	record := types.SLAPRecord{
+		Txid:        "aa",
+		IdentityKey: identityKey,
+		Domain:      "https://example.com",
+		Service:     "ls_foo",
+		Token:       &types.TokenMaterial{Fields: result.Fields, LockingPublicKey: result.LockingPublicKey.ToDERHex()},
+	}
+
+	fullPage := make([]types.SLAPRecord, 500)
+	for i := range fullPage {
+		fullPage[i] = record
+	}
+	limit, firstSkip, secondSkip := 500, 0, 500
+	mockStorage.On("ListRecords", mock.Anything, &limit, &firstSkip).Return(fullPage, nil).Once()
+	mockStorage.On("ListRecords", mock.Anything, &limit, &secondSkip).Return([]types.SLAPRecord{record}, nil).Once()
+
+	report, err := service.Reverify(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 501, report.Checked)
+	assert.Empty(t, report.Mismatches)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestReverify_StorageError(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+	mockStorage.On("ListRecords", mock.Anything, mock.Anything, mock.Anything).Return(nil, errTestStorage)
+
+	_, err := service.Reverify(context.Background())
+
+	require.ErrorIs(t, err, errTestStorage)
+	assert.Contains(t, err.Error(), "failed to list SLAP records")
+}
+
+func TestReverify_StorageWithoutRecordLister(t *testing.T) {
+	// Embedding only StorageInterface hides the optional methods of the mock
+	service := NewLookupService(struct{ StorageInterface }{new(MockStorage)})
+
+	_, err := service.Reverify(context.Background())
+
+	require.ErrorIs(t, err, shared.ErrStorageUnsupported)
+}
+
 // Test OutputSpent
 
 func TestOutputSpent_Success(t *testing.T) {
@@ -302,6 +452,93 @@ func TestOutputSpent_IgnoreNonSLAPTopic(t *testing.T) {
 	require.NoError(t, err) // Should silently ignore non-SLAP topics
 }
 
+func TestOutputSpent_EmitsRemovedEvent(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+
+	var events []types.RecordEvent
+	service.SetRecordEventListener(func(_ context.Context, event types.RecordEvent) {
+		events = append(events, event)
+	})
+
+	stored := &types.SLAPRecord{
+		Txid:        TxID,
+		OutputIndex: 0,
+		IdentityKey: "01020304",
+		Domain:      "https://example.com",
+		Service:     "ls_treasury",
+	}
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 0).Return(stored, nil)
+	mockStorage.On("DeleteSLAPRecord", mock.Anything, TxID, 0).Return(nil)
+
+	err = service.OutputSpent(context.Background(), &engine.OutputSpent{
+		Topic:    Topic,
+		Outpoint: &transaction.Outpoint{Txid: txidArray, Index: 0},
+	})
+	require.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, types.RecordEventRemoved, events[0].Type)
+	assert.Equal(t, "SLAP", events[0].Protocol)
+	assert.Equal(t, "ls_treasury", events[0].Service)
+	assert.Equal(t, "https://example.com", events[0].Domain)
+}
+
+func TestOutputEvicted_EmitsRemovedEventForUnknownRecord(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+
+	var events []types.RecordEvent
+	service.SetRecordEventListener(func(_ context.Context, event types.RecordEvent) {
+		events = append(events, event)
+	})
+
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 2).Return(nil, nil)
+	mockStorage.On("DeleteSLAPRecord", mock.Anything, TxID, 2).Return(nil)
+
+	err = service.OutputEvicted(context.Background(), &transaction.Outpoint{Txid: txidArray, Index: 2})
+	require.NoError(t, err)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, types.RecordEventRemoved, events[0].Type)
+	assert.Equal(t, TxID, events[0].Txid)
+	assert.Equal(t, 2, events[0].OutputIndex)
+	assert.Empty(t, events[0].Domain)
+}
+
+func TestOutputSpent_NoEventOnStorageError(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+
+	txidBytes, err := hex.DecodeString(TxID)
+	require.NoError(t, err)
+	var txidArray [32]byte
+	copy(txidArray[:], txidBytes)
+
+	listenerCalled := false
+	service.SetRecordEventListener(func(_ context.Context, _ types.RecordEvent) {
+		listenerCalled = true
+	})
+
+	mockStorage.On("FindByOutpoint", mock.Anything, TxID, 0).Return(nil, nil)
+	mockStorage.On("DeleteSLAPRecord", mock.Anything, TxID, 0).Return(errTestStorage)
+
+	err = service.OutputSpent(context.Background(), &engine.OutputSpent{
+		Topic:    Topic,
+		Outpoint: &transaction.Outpoint{Txid: txidArray, Index: 0},
+	})
+	require.ErrorIs(t, err, errTestStorage)
+	assert.False(t, listenerCalled)
+}
+
 // Test OutputEvicted
 
 func TestOutputEvicted_Success(t *testing.T) {
@@ -573,6 +810,122 @@ func TestGetMetaData(t *testing.T) {
 	assert.Equal(t, "Provides lookup capabilities for SLAP tokens.", metadata.Description)
 }
 
+// Test output-list answers
+
+// stubEngineStorage serves FindOutput from a fixed set of outputs; other engine.Storage
+// methods are not implemented.
+type stubEngineStorage struct {
+	engine.Storage
+
+	outputs map[transaction.Outpoint]*engine.Output
+}
+
+func (s *stubEngineStorage) FindOutput(_ context.Context, outpoint *transaction.Outpoint, _ *string, _ *bool, _ bool) (*engine.Output, error) {
+	if output, ok := s.outputs[*outpoint]; ok {
+		return output, nil
+	}
+	return nil, engine.ErrNotFound
+}
+
+func (s *stubEngineStorage) FindUTXOsForTopic(_ context.Context, topic string, _ float64, _ uint32, _ bool) ([]*engine.Output, error) {
+	outputs := make([]*engine.Output, 0, len(s.outputs))
+	for _, output := range s.outputs {
+		if output.Topic == topic {
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs, nil
+}
+
+func TestLookup_OutputListFromEngineStorage(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+
+	scriptObj, err := script.NewFromHex(createValidPushDropScript([][]byte{
+		[]byte("SLAP"), {0x01, 0x02}, []byte("https://example.com"), []byte("ls_foo"),
+	}))
+	require.NoError(t, err)
+	beefBytes, txidHex, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+	beef, txid, err := transaction.NewBeefFromAtomicBytes(beefBytes)
+	require.NoError(t, err)
+
+	outpoint := transaction.Outpoint{Txid: *txid, Index: 0}
+	service.SetBEEFProvider(shared.NewEngineBEEFProvider(&stubEngineStorage{
+		outputs: map[transaction.Outpoint]*engine.Output{outpoint: {Outpoint: outpoint, Topic: Topic, Beef: beef}},
+	}, Topic))
+
+	mockStorage.On("FindAll", mock.Anything, (*int)(nil), (*int)(nil), (*types.SortOrder)(nil)).Return([]types.UTXOReference{
+		{Txid: txidHex, OutputIndex: 0},
+		{Txid: TxID, OutputIndex: 1},
+	}, nil)
+
+	answer, err := service.Lookup(context.Background(), &lookup.LookupQuestion{
+		Service: Service,
+		Query:   json.RawMessage(`"findAll"`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, lookup.AnswerTypeOutputList, answer.Type)
+	require.Len(t, answer.Outputs, 1)
+	assert.Equal(t, uint32(0), answer.Outputs[0].OutputIndex)
+
+	_, tx, _, err := transaction.ParseBeef(answer.Outputs[0].Beef)
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+	assert.Equal(t, *txid, *tx.TxID())
+}
+
+// Test reconciliation
+
+func TestReconcile_ReportsAndRepairsDrift(t *testing.T) {
+	service, mockStorage := createTestSLAPLookupService()
+
+	scriptObj, err := script.NewFromHex(createValidPushDropScript([][]byte{
+		[]byte("SLAP"), {0x01, 0x02}, []byte("https://example.com"), []byte("ls_foo"),
+	}))
+	require.NoError(t, err)
+	beefBytes, missingTxid, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+	beef, txid, err := transaction.NewBeefFromAtomicBytes(beefBytes)
+	require.NoError(t, err)
+
+	sharedHash, err := chainhash.NewHashFromHex(TxID)
+	require.NoError(t, err)
+	sharedTxid := hex.EncodeToString(sharedHash[:])
+
+	missing := transaction.Outpoint{Txid: *txid, Index: 0}
+	inBoth := transaction.Outpoint{Txid: *sharedHash, Index: 0}
+	source := &stubEngineStorage{outputs: map[transaction.Outpoint]*engine.Output{
+		missing: {Outpoint: missing, Topic: Topic, Beef: beef},
+		inBoth:  {Outpoint: inBoth, Topic: Topic},
+	}}
+
+	mockStorage.On("FindAll", mock.Anything, (*int)(nil), (*int)(nil), (*types.SortOrder)(nil)).Return([]types.UTXOReference{
+		{Txid: sharedTxid, OutputIndex: 0},
+		{Txid: sharedTxid, OutputIndex: 3},
+	}, nil)
+
+	report, err := service.Reconcile(context.Background(), source, false)
+	require.NoError(t, err)
+	assert.False(t, report.Consistent())
+	assert.Equal(t, 2, report.EngineOutputs)
+	assert.Equal(t, 2, report.StoredRecords)
+	assert.Equal(t, []types.UTXOReference{{Txid: sharedTxid, OutputIndex: 3}}, report.Orphans)
+	assert.Equal(t, []types.UTXOReference{{Txid: missingTxid, OutputIndex: 0}}, report.Missing)
+	mockStorage.AssertNotCalled(t, "DeleteSLAPRecord", mock.Anything, mock.Anything, mock.Anything)
+
+	mockStorage.On("DeleteSLAPRecord", mock.Anything, sharedTxid, 3).Return(nil)
+	mockStorage.On("FindByOutpoint", mock.Anything, missingTxid, 0).Return(nil, nil)
+	mockStorage.On("StoreSLAPRecord", mock.Anything, missingTxid, 0, "0102", "https://example.com", "ls_foo").Return(nil)
+
+	report, err = service.Reconcile(context.Background(), source, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.RemovedOrphans)
+	assert.Equal(t, 1, report.RestoredMissing)
+	assert.Empty(t, report.RepairErrors)
+	mockStorage.AssertExpectations(t)
+}
+
 // Test edge cases and error scenarios
 
 func TestLookup_StorageError(t *testing.T) {
@@ -1,11 +1,18 @@
 package slap
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
 )
@@ -523,3 +530,127 @@ func intPtr(i int) *int {
 func sortOrderPtr(s types.SortOrder) *types.SortOrder {
 	return &s
 }
+
+func TestImportJSONL_Batches(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 1201; i++ {
+		fmt.Fprintf(&input, `{"txid":"tx%d","outputIndex":0,"identityKey":"alice","domain":"https://example.com","service":"ls_a","createdAt":"2024-01-01T00:00:00Z"}`+"\n", i)
+	}
+
+	target := new(MockStorage)
+	var batchSizes []int
+	target.On("ImportRecords", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		records := args.Get(1).([]types.SLAPRecord)
+		batchSizes = append(batchSizes, len(records))
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), records[0].CreatedAt.UTC())
+	}).Return(nil)
+
+	imported, err := ImportJSONL(context.Background(), target, strings.NewReader(input.String()))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1201, imported)
+	assert.Equal(t, []int{500, 500, 201}, batchSizes)
+}
+
+func TestImportJSONL_StorageError(t *testing.T) {
+	target := new(MockStorage)
+	target.On("ImportRecords", mock.Anything, mock.Anything).Return(errTestStorage)
+
+	input := `{"txid":"tx1","outputIndex":0,"identityKey":"alice","domain":"https://example.com","service":"ls_a"}`
+	imported, err := ImportJSONL(context.Background(), target, strings.NewReader(input))
+
+	require.ErrorIs(t, err, errTestStorage)
+	assert.Zero(t, imported)
+}
+
+func TestExportJSONL_StorageError(t *testing.T) {
+	source := new(MockStorage)
+	source.On("ForEachRecord", mock.Anything).Return([]types.SLAPRecord{{Txid: "tx1"}}, errTestStorage)
+
+	var buf bytes.Buffer
+	exported, err := ExportJSONL(context.Background(), source, &buf)
+
+	require.ErrorIs(t, err, errTestStorage)
+	assert.Equal(t, 1, exported)
+}
+
+func TestStorage_CanonicalizesDomains(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("store", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		require.NoError(mt, storage.StoreSLAPRecord(context.Background(), "tx1", 0, "alice", "HTTPS://Example.com:443/", "ls_one"))
+
+		inserted := mt.GetStartedEvent().Command.Lookup("documents", "0").Document()
+		assert.Equal(mt, "https://example.com", inserted.Lookup("domain").StringValue())
+	})
+
+	mt.Run("find", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		ns := mt.DB.Name() + ".slapRecords"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "txid", Value: "tx1"}, {Key: "outputIndex", Value: 0}}))
+
+		results, err := storage.FindRecord(context.Background(), types.SLAPQuery{Domain: stringPtr("https://BÜCHER.example/")})
+		require.NoError(mt, err)
+		assert.Equal(mt, []types.UTXOReference{{Txid: "tx1", OutputIndex: 0}}, results)
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(mt, "https://xn--bcher-kva.example", filter.Lookup("domain").StringValue())
+	})
+
+	mt.Run("migrate", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		ns := mt.DB.Name() + ".slapRecords"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: 1}, {Key: "domain", Value: "https://Example.com/"}},
+				bson.D{{Key: "_id", Value: 2}, {Key: "domain", Value: "https://example.com"}},
+				bson.D{{Key: "_id", Value: 3}, {Key: "domain", Value: "https://example.com:443"}},
+			),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+		)
+
+		updated, err := storage.CanonicalizeDomains(context.Background())
+		require.NoError(mt, err)
+		assert.Equal(mt, 2, updated)
+
+		mt.GetStartedEvent() // the find command
+		updates, err := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		require.NoError(mt, err)
+		require.Len(mt, updates, 2)
+		for i, id := range []int32{1, 3} {
+			update := updates[i].Document()
+			assert.Equal(mt, id, update.Lookup("q", "_id").Int32())
+			assert.Equal(mt, "https://example.com", update.Lookup("u", "$set", "domain").StringValue())
+		}
+	})
+}
+
+func TestQueryRecords(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("single query", func(mt *mtest.T) {
+		storage := NewStorage(mt.DB)
+		ns := mt.DB.Name() + ".slapRecords"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			bson.D{{Key: "txid", Value: "tx1"}, {Key: "outputIndex", Value: 1}, {Key: "token", Value: bson.D{{Key: "lockingPublicKey", Value: "02ab"}}}},
+			bson.D{{Key: "txid", Value: "tx2"}, {Key: "outputIndex", Value: 0}},
+		))
+
+		limit := 2
+		records, err := storage.QueryRecords(context.Background(), types.SLAPQuery{Service: stringPtr("ls_one"), Limit: &limit})
+		require.NoError(mt, err)
+		require.Len(mt, records, 2)
+		assert.Equal(mt, "tx1", records[0].Txid)
+		require.NotNil(mt, records[0].Token)
+		assert.Equal(mt, "02ab", records[0].Token.LockingPublicKey)
+		assert.Equal(mt, "tx2", records[1].Txid)
+
+		command := mt.GetStartedEvent().Command
+		assert.Equal(mt, int64(2), command.Lookup("limit").AsInt64())
+		_, err = command.LookupErr("projection")
+		assert.Error(mt, err, "full records must not be projected")
+	})
+}
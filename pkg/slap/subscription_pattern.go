@@ -0,0 +1,77 @@
+package slap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
+)
+
+// Wildcards accepted in subscription service and domain patterns.
+const (
+	// WildcardService matches every service when used as a subscription service.
+	// A service ending in WildcardService (e.g. "ls_foo*") matches every service with that prefix.
+	WildcardService = "*"
+	// WildcardDomain matches every domain when used as a subscription domain
+	WildcardDomain = "*"
+)
+
+// ServiceMatches reports whether a service name matches a subscription service pattern.
+// A pattern ending in "*" matches any service with that prefix; any other pattern must match exactly.
+func ServiceMatches(pattern, service string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, WildcardService); ok {
+		return strings.HasPrefix(service, prefix)
+	}
+	return pattern == service
+}
+
+// DomainMatches reports whether a domain matches a subscription domain pattern.
+// The pattern "*" matches any domain; any other pattern must match exactly.
+func DomainMatches(pattern, domain string) bool {
+	return pattern == WildcardDomain || pattern == domain
+}
+
+// CanonicalDomainPattern returns a subscription domain pattern in the canonical form of
+// utils.CanonicalDomain, which record events carry. The wildcard and empty patterns are
+// returned unchanged.
+func CanonicalDomainPattern(pattern string) string {
+	if pattern == "" || pattern == WildcardDomain {
+		return pattern
+	}
+	return utils.CanonicalDomain(pattern)
+}
+
+// ValidateSubscriptionPattern checks that wildcards appear only where supported:
+// as the final character of the service, or as the entire domain.
+func ValidateSubscriptionPattern(service, domain string) error {
+	if idx := strings.Index(service, WildcardService); idx != -1 && idx != len(service)-1 {
+		return fmt.Errorf("%w: %s", errInvalidServicePattern, service)
+	}
+
+	if domain != WildcardDomain && strings.Contains(domain, WildcardDomain) {
+		return fmt.Errorf("%w: %s", errInvalidDomainPattern, domain)
+	}
+
+	return nil
+}
+
+// subscriptionPrecedence ranks how specifically a subscription pattern matches a message;
+// matching subscriptions are served in decreasing rank.
+//
+// Precedence is decided by service first, then domain:
+//  1. An exact service outranks any service prefix
+//  2. A longer service prefix outranks a shorter one ("*" is the empty prefix)
+//  3. For the same service pattern, an exact domain outranks the wildcard domain
+func subscriptionPrecedence(service, domain string) int {
+	serviceRank := len(service) + 1
+	if prefix, ok := strings.CutSuffix(service, WildcardService); ok {
+		serviceRank = len(prefix)
+	}
+
+	domainRank := 1
+	if domain == WildcardDomain {
+		domainRank = 0
+	}
+
+	return serviceRank*2 + domainRank
+}
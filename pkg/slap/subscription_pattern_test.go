@@ -0,0 +1,65 @@
+package slap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		service  string
+		expected bool
+	}{
+		{"ls_foo", "ls_foo", true},
+		{"ls_foo", "ls_foo_bar", false},
+		{"ls_foo*", "ls_foo", true},
+		{"ls_foo*", "ls_foo_bar", true},
+		{"ls_foo*", "ls_bar", false},
+		{"*", "ls_anything", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.service, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ServiceMatches(tt.pattern, tt.service))
+		})
+	}
+}
+
+func TestDomainMatches(t *testing.T) {
+	assert.True(t, DomainMatches("https://example.com", "https://example.com"))
+	assert.False(t, DomainMatches("https://example.com", "https://other.com"))
+	assert.True(t, DomainMatches(WildcardDomain, "https://other.com"))
+}
+
+func TestValidateSubscriptionPattern(t *testing.T) {
+	require.NoError(t, ValidateSubscriptionPattern("ls_foo", "example.com"))
+	require.NoError(t, ValidateSubscriptionPattern("ls_foo*", WildcardDomain))
+	require.NoError(t, ValidateSubscriptionPattern(WildcardService, WildcardDomain))
+
+	err := ValidateSubscriptionPattern("ls_*foo", "example.com")
+	require.ErrorIs(t, err, errInvalidServicePattern)
+
+	err = ValidateSubscriptionPattern("ls_foo", "*.example.com")
+	require.ErrorIs(t, err, errInvalidDomainPattern)
+}
+
+func TestSubscriptionPrecedence(t *testing.T) {
+	// Ordered from most to least specific for a message on ls_foo@example.com
+	ordered := [][2]string{
+		{"ls_foo", "example.com"},
+		{"ls_foo", WildcardDomain},
+		{"ls_f*", "example.com"},
+		{"ls_f*", WildcardDomain},
+		{WildcardService, "example.com"},
+		{WildcardService, WildcardDomain},
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		higher := subscriptionPrecedence(ordered[i-1][0], ordered[i-1][1])
+		lower := subscriptionPrecedence(ordered[i][0], ordered[i][1])
+		assert.Greater(t, higher, lower, "%v should outrank %v", ordered[i-1], ordered[i])
+	}
+}
@@ -3,15 +3,19 @@
 package slap
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/bsv-blockchain/go-sdk/overlay"
 
 	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
 )
 
 // Static error variables for err113 compliance
@@ -23,6 +27,10 @@ var (
 	errMessageServiceEmpty      = errors.New("message service cannot be empty")
 	errMessageDomainEmpty       = errors.New("message domain cannot be empty")
 	errNoHandlerFoundForService = errors.New("no handler found for service")
+	errInvalidServicePattern    = errors.New("service wildcard '*' is only allowed as the final character")
+	errInvalidDomainPattern     = errors.New("domain wildcard '*' must be the entire domain")
+	errHandlerNotFound          = errors.New("handler not found")
+	errNoLookupService          = errors.New("topic manager has no lookup service")
 )
 
 // ServiceSubscription represents an active service subscription for SLAP protocol
@@ -65,10 +73,16 @@ type TopicManager struct {
 	// BaseTopicManagerOps provides shared implementations for engine.TopicManager interface methods
 	shared.BaseTopicManagerOps
 
+	// topicManagerMetaData is returned by GetTopicManagerMetaData
+	topicManagerMetaData overlay.MetaData
 	// subscriptions holds all active service subscriptions keyed by service+domain
 	subscriptions map[string]*ServiceSubscription
-	// handlers holds message handlers for each subscribed service
-	handlers map[string]ServiceMessageHandler
+	// handlers holds the message handlers registered on each subscribed service+domain
+	handlers map[string]*shared.HandlerSet[ServiceMessageHandler]
+	// handlerSubscriptions maps each registered handler ID to its subscription key
+	handlerSubscriptions map[shared.SubscriptionID]string
+	// nextHandlerID is the sequence number of the next handler ID issued by AddServiceHandler
+	nextHandlerID uint64
 	// mutex protects concurrent access to subscriptions and handlers
 	mutex sync.RWMutex
 	// storage provides access to SLAP storage operations
@@ -80,25 +94,85 @@ type TopicManager struct {
 // NewTopicManager creates a new SLAP topic manager instance.
 // This constructor initializes the topic manager with the required dependencies
 // for managing overlay network service subscriptions and message routing.
+// When a lookup service is provided, records it stores or deletes are published
+// as messages on the advertised service and domain with a types.RecordEvent payload.
 func NewTopicManager(storage StorageInterface, lookupService *LookupService) *TopicManager {
-	doc := TopicManagerDocumentation
-	return &TopicManager{
+	return newTopicManager(storage, lookupService, shared.Options{Documentation: TopicManagerDocumentation})
+}
+
+// NewTopicManagerWithOptions creates a new SLAP topic manager instance, customized by opts.
+// Without options it behaves like NewTopicManager. Record events are routed by the service
+// they advertise rather than by a configured identifier, so shared.WithTopic and
+// shared.WithService are rejected with shared.ErrUnsupportedOption.
+func NewTopicManagerWithOptions(storage StorageInterface, lookupService *LookupService, opts ...shared.Option) (*TopicManager, error) {
+	o := shared.ApplyOptions(shared.Options{Documentation: TopicManagerDocumentation}, opts)
+	if o.Topic != "" {
+		return nil, fmt.Errorf("%w: a SLAP topic manager routes events by service, not topic %q", shared.ErrUnsupportedOption, o.Topic)
+	}
+	if o.Service != "" {
+		return nil, fmt.Errorf("%w: a SLAP topic manager routes events by advertised service, not %q", shared.ErrUnsupportedOption, o.Service)
+	}
+	return newTopicManager(storage, lookupService, o), nil
+}
+
+// newTopicManager creates a SLAP topic manager from resolved options. Metadata left unset
+// keeps the defaults of each metadata method.
+func newTopicManager(storage StorageInterface, lookupService *LookupService, o shared.Options) *TopicManager {
+	tm := &TopicManager{
 		BaseTopicManagerOps: shared.NewBaseTopicManagerOps(shared.BaseTopicManagerConfig{
 			Admittance: shared.AdmittanceConfig{
-				Identifier:   "SLAP",
-				TopicPrefix:  "ls_",
-				EmojiAdmit:   "\U0001f44f",
-				EmojiConsume: "\u270b",
-				EmojiNone:    "\U0001f615",
+				Identifier:    "SLAP",
+				TopicPrefix:   "ls_",
+				VerifyWorkers: o.VerifyWorkers,
+				Logger:        o.Logger,
 			},
-			MetaDataName:        "SLAP Topic Manager",
-			MetaDataDescription: "Manages SLAP protocol topics for service lookup and availability tracking",
-			Documentation:       &doc,
+			MetaDataName:        cmp.Or(o.MetaDataName, "SLAP Topic Manager"),
+			MetaDataDescription: cmp.Or(o.MetaDataDescription, "Manages SLAP protocol topics for service lookup and availability tracking"),
+			Documentation:       &o.Documentation,
+			Clock:               o.Clock,
 		}),
-		subscriptions: make(map[string]*ServiceSubscription),
-		handlers:      make(map[string]ServiceMessageHandler),
-		storage:       storage,
-		lookupService: lookupService,
+		topicManagerMetaData: overlay.MetaData{
+			Name:        cmp.Or(o.MetaDataName, "SLAP Topic Manager"),
+			Description: cmp.Or(o.MetaDataDescription, "Manages overlay network service subscriptions for SLAP protocol."),
+		},
+		subscriptions:        make(map[string]*ServiceSubscription),
+		handlers:             make(map[string]*shared.HandlerSet[ServiceMessageHandler]),
+		handlerSubscriptions: make(map[shared.SubscriptionID]string),
+		storage:              storage,
+		lookupService:        lookupService,
+	}
+
+	if lookupService != nil {
+		lookupService.SetRecordEventListener(tm.publishRecordEvent)
+	}
+
+	return tm
+}
+
+// publishRecordEvent delivers a record event from the lookup service to the subscribers
+// of the advertised service and domain. Events for records that were never stored carry
+// no service or domain and cannot be routed, so they are dropped.
+func (tm *TopicManager) publishRecordEvent(ctx context.Context, event types.RecordEvent) {
+	if event.Service == "" || event.Domain == "" {
+		return
+	}
+
+	message := ServiceMessage{
+		Service:     event.Service,
+		Domain:      event.Domain,
+		Payload:     event,
+		ReceivedAt:  event.Timestamp,
+		MessageID:   fmt.Sprintf("%s:%s.%d", event.Type, event.Txid, event.OutputIndex),
+		IdentityKey: event.IdentityKey,
+	}
+
+	if err := tm.HandleServiceMessage(ctx, message); err != nil {
+		shared.Logger(ctx, tm.Cfg.Admittance.Logger).Warn("Failed to deliver record event",
+			shared.LogKeyProtocol, Identifier,
+			shared.LogKeyTxid, event.Txid,
+			shared.LogKeyOutputIndex, event.OutputIndex,
+			shared.LogKeyError, err,
+		)
 	}
 }
 
@@ -110,7 +184,16 @@ func (tm *TopicManager) getSubscriptionKey(service, domain string) string {
 // SubscribeToService subscribes to a specific service with a message handler.
 // Creates a new subscription if one doesn't exist, or updates an existing one.
 // The provided handler will be called for all messages received for this service.
+// Calling SubscribeToService again for the same service and domain replaces the handler it
+// registered previously; handlers registered with AddServiceHandler are unaffected.
+//
+// The service may end in "*" to match every service with that prefix (or be "*" to match
+// all services), and the domain may be "*" to match every domain. See HandleServiceMessage
+// for how overlapping subscriptions are resolved. The domain is kept in the canonical form
+// returned by CanonicalDomainPattern, so differently spelled forms of a domain name the same
+// subscription.
 func (tm *TopicManager) SubscribeToService(_ context.Context, service, domain string, handler ServiceMessageHandler) error {
+	domain = CanonicalDomainPattern(domain)
 	if service == "" {
 		return errServiceNameEmpty
 	}
@@ -123,9 +206,77 @@ func (tm *TopicManager) SubscribeToService(_ context.Context, service, domain st
 		return errMessageHandlerNil
 	}
 
+	if err := ValidateSubscriptionPattern(service, domain); err != nil {
+		return err
+	}
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	subscriptionKey := tm.getSubscriptionKey(service, domain)
+	tm.addHandler(service, domain, shared.SubscriberID(subscriptionKey), handler)
+
+	return nil
+}
+
+// AddServiceHandler registers an additional message handler for a service and domain,
+// creating or reactivating the subscription as needed. The service and domain accept the
+// same patterns as SubscribeToService. Every handler registered on a subscription receives
+// each message routed to it. The returned ID removes this handler alone via RemoveServiceHandler.
+func (tm *TopicManager) AddServiceHandler(_ context.Context, service, domain string, handler ServiceMessageHandler) (shared.SubscriptionID, error) {
+	domain = CanonicalDomainPattern(domain)
+	if service == "" {
+		return "", errServiceNameEmpty
+	}
+
+	if domain == "" {
+		return "", errDomainEmpty
+	}
+
+	if handler == nil {
+		return "", errMessageHandlerNil
+	}
+
+	if err := ValidateSubscriptionPattern(service, domain); err != nil {
+		return "", err
+	}
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	tm.nextHandlerID++
+	id := shared.HandlerID(tm.nextHandlerID, tm.getSubscriptionKey(service, domain))
+	tm.addHandler(service, domain, id, handler)
+
+	return id, nil
+}
+
+// RemoveServiceHandler removes a single handler registered with AddServiceHandler.
+// Other handlers on the subscription keep receiving messages; the subscription is marked
+// inactive once its last handler is removed.
+func (tm *TopicManager) RemoveServiceHandler(_ context.Context, id shared.SubscriptionID) error {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
+	subscriptionKey, exists := tm.handlerSubscriptions[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", errHandlerNotFound, id)
+	}
+	delete(tm.handlerSubscriptions, id)
+
+	handlers := tm.handlers[subscriptionKey]
+	handlers.Remove(id)
+	if handlers.Len() == 0 {
+		delete(tm.handlers, subscriptionKey)
+		tm.subscriptions[subscriptionKey].IsActive = false
+	}
+
+	return nil
+}
+
+// addHandler registers the handler on the service+domain subscription under the given ID
+// and activates the subscription. The caller must hold the write lock.
+func (tm *TopicManager) addHandler(service, domain string, id shared.SubscriptionID, handler ServiceMessageHandler) {
 	subscriptionKey := tm.getSubscriptionKey(service, domain)
 
 	// Create or update subscription
@@ -134,7 +285,7 @@ func (tm *TopicManager) SubscribeToService(_ context.Context, service, domain st
 		subscription = &ServiceSubscription{
 			Service:      service,
 			Domain:       domain,
-			SubscribedAt: time.Now(),
+			SubscribedAt: shared.Now(tm.Cfg.Clock),
 			IsActive:     true,
 			MessageCount: 0,
 		}
@@ -144,16 +295,20 @@ func (tm *TopicManager) SubscribeToService(_ context.Context, service, domain st
 		subscription.IsActive = true
 	}
 
-	// Set or update handler
-	tm.handlers[subscriptionKey] = handler
-
-	return nil
+	handlers, exists := tm.handlers[subscriptionKey]
+	if !exists {
+		handlers = &shared.HandlerSet[ServiceMessageHandler]{}
+		tm.handlers[subscriptionKey] = handlers
+	}
+	handlers.Set(id, handler)
+	tm.handlerSubscriptions[id] = subscriptionKey
 }
 
 // UnsubscribeFromService unsubscribes from a specific service.
-// Marks the subscription as inactive and removes the message handler.
+// Marks the subscription as inactive and removes all of its message handlers.
 // The subscription record is kept for historical purposes.
 func (tm *TopicManager) UnsubscribeFromService(_ context.Context, service, domain string) error {
+	domain = CanonicalDomainPattern(domain)
 	if service == "" {
 		return errServiceNameEmpty
 	}
@@ -175,15 +330,28 @@ func (tm *TopicManager) UnsubscribeFromService(_ context.Context, service, domai
 	// Mark subscription as inactive
 	subscription.IsActive = false
 
-	// Remove handler
-	delete(tm.handlers, subscriptionKey)
+	// Remove handlers
+	if handlers, exists := tm.handlers[subscriptionKey]; exists {
+		for _, id := range handlers.IDs() {
+			delete(tm.handlerSubscriptions, id)
+		}
+		delete(tm.handlers, subscriptionKey)
+	}
 
 	return nil
 }
 
 // HandleServiceMessage processes an incoming service message.
-// Routes the message to the appropriate handler if one exists for the service.
-// Updates message statistics for the service.
+// Routes the message to the handlers of every active subscription matching the message's
+// service and domain, and updates message statistics for each of them. A failing or
+// panicking handler does not prevent delivery to the other handlers; their errors are
+// combined in the result.
+//
+// Subscriptions are served from the most specific to the least specific, ordered by service
+// first, then domain: an exact service precedes a service prefix, a longer prefix precedes a
+// shorter one, and for the same service pattern an exact domain precedes the "*" domain. For
+// example, a message for ls_foo@example.com is delivered to whichever of these are subscribed,
+// in this order: ls_foo@example.com, ls_foo@*, ls_f*@example.com, ls_f*@*, *@example.com, *@*.
 func (tm *TopicManager) HandleServiceMessage(ctx context.Context, message ServiceMessage) error {
 	if message.Service == "" {
 		return errMessageServiceEmpty
@@ -193,37 +361,59 @@ func (tm *TopicManager) HandleServiceMessage(ctx context.Context, message Servic
 		return errMessageDomainEmpty
 	}
 
-	subscriptionKey := tm.getSubscriptionKey(message.Service, message.Domain)
-
-	tm.mutex.RLock()
-	subscription, subscriptionExists := tm.subscriptions[subscriptionKey]
-	handler, handlerExists := tm.handlers[subscriptionKey]
-	isActive := subscriptionExists && subscription.IsActive
-	tm.mutex.RUnlock()
+	tm.mutex.Lock()
+	matches := tm.matchSubscriptions(message.Service, utils.CanonicalDomain(message.Domain))
+	var handlers []ServiceMessageHandler
+	for _, key := range matches {
+		// Update message count
+		tm.subscriptions[key].MessageCount++
+		if set, exists := tm.handlers[key]; exists {
+			handlers = append(handlers, set.Handlers()...)
+		}
+	}
+	tm.mutex.Unlock()
 
 	// Check if we have an active subscription for this service
-	if !subscriptionExists || !isActive {
+	if len(matches) == 0 {
 		// Silently ignore messages for services we're not subscribed to
 		return nil
 	}
 
-	if !handlerExists {
+	if len(handlers) == 0 {
 		return fmt.Errorf("%w: %s@%s", errNoHandlerFoundForService, message.Service, message.Domain)
 	}
 
-	// Update message count
-	tm.mutex.Lock()
-	subscription.MessageCount++
-	tm.mutex.Unlock()
-
 	// Handle the message
-	if err := handler(ctx, message); err != nil {
+	err := shared.DeliverToHandlers(handlers, func(handler ServiceMessageHandler) error {
+		return handler(ctx, message)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to handle message for service %s@%s: %w", message.Service, message.Domain, err)
 	}
 
 	return nil
 }
 
+// matchSubscriptions returns the keys of the active subscriptions matching the service and
+// domain, most specific first. The caller must hold the mutex.
+func (tm *TopicManager) matchSubscriptions(service, domain string) []string {
+	var keys []string
+	for key, subscription := range tm.subscriptions {
+		if subscription.IsActive &&
+			ServiceMatches(subscription.Service, service) &&
+			DomainMatches(subscription.Domain, domain) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := tm.subscriptions[keys[i]], tm.subscriptions[keys[j]]
+		return subscriptionPrecedence(a.Service, a.Domain) > subscriptionPrecedence(b.Service, b.Domain)
+	})
+
+	return keys
+}
+
 // GetSubscribedServices returns all current service subscriptions.
 // Returns a copy of subscription data to prevent external modification.
 func (tm *TopicManager) GetSubscribedServices() []ServiceSubscription {
@@ -242,6 +432,7 @@ func (tm *TopicManager) GetSubscribedServices() []ServiceSubscription {
 // CreateServiceSubscription creates a new service subscription without a handler.
 // This method is useful for creating subscription records before setting up handlers.
 func (tm *TopicManager) CreateServiceSubscription(_ context.Context, service, domain string) (*ServiceSubscription, error) {
+	domain = CanonicalDomainPattern(domain)
 	if service == "" {
 		return nil, errServiceNameEmpty
 	}
@@ -250,6 +441,10 @@ func (tm *TopicManager) CreateServiceSubscription(_ context.Context, service, do
 		return nil, errDomainEmpty
 	}
 
+	if err := ValidateSubscriptionPattern(service, domain); err != nil {
+		return nil, err
+	}
+
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
@@ -265,7 +460,7 @@ func (tm *TopicManager) CreateServiceSubscription(_ context.Context, service, do
 	subscription := &ServiceSubscription{
 		Service:      service,
 		Domain:       domain,
-		SubscribedAt: time.Now(),
+		SubscribedAt: shared.Now(tm.Cfg.Clock),
 		IsActive:     false, // Not active until a handler is set
 		MessageCount: 0,
 	}
@@ -280,7 +475,7 @@ func (tm *TopicManager) IsSubscribedToService(service, domain string) bool {
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
-	subscriptionKey := tm.getSubscriptionKey(service, domain)
+	subscriptionKey := tm.getSubscriptionKey(service, CanonicalDomainPattern(domain))
 	subscription, exists := tm.subscriptions[subscriptionKey]
 	return exists && subscription.IsActive
 }
@@ -291,7 +486,7 @@ func (tm *TopicManager) GetServiceMessageCount(service, domain string) int64 {
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
-	subscriptionKey := tm.getSubscriptionKey(service, domain)
+	subscriptionKey := tm.getSubscriptionKey(service, CanonicalDomainPattern(domain))
 	if subscription, exists := tm.subscriptions[subscriptionKey]; exists {
 		return subscription.MessageCount
 	}
@@ -310,18 +505,29 @@ func (tm *TopicManager) Close(_ context.Context) error {
 	}
 
 	// Clear all handlers
-	tm.handlers = make(map[string]ServiceMessageHandler)
+	tm.handlers = make(map[string]*shared.HandlerSet[ServiceMessageHandler])
+	tm.handlerSubscriptions = make(map[shared.SubscriptionID]string)
 
 	return nil
 }
 
+// Reindex rebuilds SLAP storage from an archive of Atomic BEEF, applying the same admittance
+// checks and policy as IdentifyAdmissibleOutputs and storing records through the lookup service.
+// Outputs that already have a record are skipped, so a pass can be safely rerun. Rate limits
+// set with shared.RateLimitPolicy do not apply to the archived outputs (see
+// shared.BaseLookupService.Reindex).
+func (tm *TopicManager) Reindex(ctx context.Context, source shared.ReindexSource) (shared.ReindexReport, error) {
+	if tm.lookupService == nil {
+		return shared.ReindexReport{}, errNoLookupService
+	}
+	return tm.lookupService.Reindex(ctx, source, tm.Cfg.Admittance)
+}
+
 // GetTopicManagerMetaData returns metadata information for the SLAP topic manager.
-// This provides basic information about the topic manager service.
+// This provides basic information about the topic manager service, unless overridden
+// by shared.WithMetaData.
 func (tm *TopicManager) GetTopicManagerMetaData() overlay.MetaData {
-	return overlay.MetaData{
-		Name:        "SLAP Topic Manager",
-		Description: "Manages overlay network service subscriptions for SLAP protocol.",
-	}
+	return tm.topicManagerMetaData
 }
 
 // GetActiveServiceCount returns the number of currently active service subscriptions.
@@ -355,6 +561,7 @@ func (tm *TopicManager) GetServicesByDomain(domain string) []ServiceSubscription
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
+	domain = CanonicalDomainPattern(domain)
 	var domainServices []ServiceSubscription
 	for _, subscription := range tm.subscriptions {
 		if subscription.Domain == domain && subscription.IsActive {
@@ -2,13 +2,26 @@ package slap
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/internal/tokentest"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
 )
 
 // Static error variables for testing
@@ -57,6 +70,18 @@ func createMockServiceHandler(called *bool, shouldError bool) ServiceMessageHand
 	}
 }
 
+// createSignedSLAPBEEF builds a transaction with one correctly signed SLAP token output per domain,
+// all advertising the same service from the same identity. Returns the BEEF, the txid and
+// the hex identity key.
+func createSignedSLAPBEEF(t *testing.T, service string, domains ...string) (*transaction.Beef, *chainhash.Hash, string) {
+	t.Helper()
+	advertisements := make([]tokentest.Advertisement, len(domains))
+	for i, domain := range domains {
+		advertisements[i] = tokentest.Advertisement{Domain: domain, Name: service}
+	}
+	return tokentest.SignedBEEF(t, overlay.ProtocolSLAP, advertisements...)
+}
+
 // Test NewTopicManager
 
 func TestNewSLAPTopicManager(t *testing.T) {
@@ -83,6 +108,37 @@ func TestNewSLAPTopicManagerWithLookupService(t *testing.T) {
 	assert.NotNil(t, topicManager.handlers)
 }
 
+func TestNewSLAPTopicManagerWithOptions(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	topicManager, err := NewTopicManagerWithOptions(new(MockStorage), nil,
+		shared.WithMetaData("Test SLAP", "SLAP admittance for tests"),
+		shared.WithDocumentation("# Test SLAP"),
+		shared.WithClock(shared.ClockFunc(func() time.Time { return now })),
+		shared.WithVerifyWorkers(4),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test SLAP", topicManager.GetMetaData().Name)
+	assert.Equal(t, "SLAP admittance for tests", topicManager.GetMetaData().Description)
+	assert.Equal(t, "Test SLAP", topicManager.GetTopicManagerMetaData().Name)
+	assert.Equal(t, "SLAP admittance for tests", topicManager.GetTopicManagerMetaData().Description)
+	assert.Equal(t, "# Test SLAP", topicManager.GetDocumentation())
+	assert.Equal(t, 4, topicManager.Cfg.Admittance.VerifyWorkers)
+
+	subscription, err := topicManager.CreateServiceSubscription(context.Background(), "ls_test", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, now, subscription.SubscribedAt)
+}
+
+func TestNewSLAPTopicManagerWithOptions_RejectsIdentifiers(t *testing.T) {
+	for _, opt := range []shared.Option{shared.WithTopic("tm_custom"), shared.WithService("ls_custom")} {
+		topicManager, err := NewTopicManagerWithOptions(new(MockStorage), nil, opt)
+		require.ErrorIs(t, err, shared.ErrUnsupportedOption)
+		assert.Nil(t, topicManager)
+	}
+}
+
 // Test SubscribeToService
 
 func TestSubscribeToService_Success(t *testing.T) {
@@ -320,6 +376,205 @@ func TestHandleServiceMessage_HandlerError(t *testing.T) {
 	assert.Equal(t, int64(1), topicManager.GetServiceMessageCount("ls_treasury", "example.com"))
 }
 
+// Test AddServiceHandler and RemoveServiceHandler
+
+func TestAddServiceHandler_FanOut(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	ctx := context.Background()
+
+	firstCalled, secondCalled, legacyCalled := false, false, false
+	firstID, err := topicManager.AddServiceHandler(ctx, "ls_test", "example.com", createMockServiceHandler(&firstCalled, false))
+	require.NoError(t, err)
+	secondID, err := topicManager.AddServiceHandler(ctx, "ls_test", "example.com", createMockServiceHandler(&secondCalled, false))
+	require.NoError(t, err)
+	require.NoError(t, topicManager.SubscribeToService(ctx, "ls_test", "example.com", createMockServiceHandler(&legacyCalled, false)))
+	assert.NotEqual(t, firstID, secondID)
+
+	err = topicManager.HandleServiceMessage(ctx, createTestServiceMessage("ls_test", "example.com", "msg-1", "test payload"))
+
+	require.NoError(t, err)
+	assert.True(t, firstCalled)
+	assert.True(t, secondCalled)
+	assert.True(t, legacyCalled)
+	assert.Equal(t, int64(1), topicManager.GetServiceMessageCount("ls_test", "example.com"))
+}
+
+func TestSubscriptions_CanonicalDomain(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	ctx := context.Background()
+
+	subscribedCalled, addedCalled := false, false
+	require.NoError(t, topicManager.SubscribeToService(ctx, "ls_test", "HTTPS://Example.com:443/", createMockServiceHandler(&subscribedCalled, false)))
+	_, err := topicManager.AddServiceHandler(ctx, "ls_test", "https://EXAMPLE.com.", createMockServiceHandler(&addedCalled, false))
+	require.NoError(t, err)
+
+	require.Len(t, topicManager.subscriptions, 1)
+	assert.Equal(t, "https://example.com", topicManager.GetSubscribedServices()[0].Domain)
+	assert.True(t, topicManager.IsSubscribedToService("ls_test", "https://example.com"))
+
+	require.NoError(t, topicManager.HandleServiceMessage(ctx, createTestServiceMessage("ls_test", "https://example.com", "msg-1", "test payload")))
+	assert.True(t, subscribedCalled)
+	assert.True(t, addedCalled)
+}
+
+func TestAddServiceHandler_InvalidArguments(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	ctx := context.Background()
+	handlerCalled := false
+	handler := createMockServiceHandler(&handlerCalled, false)
+
+	_, err := topicManager.AddServiceHandler(ctx, "", "example.com", handler)
+	require.ErrorIs(t, err, errServiceNameEmpty)
+
+	_, err = topicManager.AddServiceHandler(ctx, "ls_test", "", handler)
+	require.ErrorIs(t, err, errDomainEmpty)
+
+	_, err = topicManager.AddServiceHandler(ctx, "ls_test", "example.com", nil)
+	require.ErrorIs(t, err, errMessageHandlerNil)
+
+	_, err = topicManager.AddServiceHandler(ctx, "ls_*test", "example.com", handler)
+	require.ErrorIs(t, err, errInvalidServicePattern)
+}
+
+func TestHandleServiceMessage_HandlerErrorIsolation(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	ctx := context.Background()
+
+	failingCalled, healthyCalled := false, false
+	_, err := topicManager.AddServiceHandler(ctx, "ls_test", "example.com", createMockServiceHandler(&failingCalled, true))
+	require.NoError(t, err)
+	_, err = topicManager.AddServiceHandler(ctx, "ls_test", "example.com", func(_ context.Context, _ ServiceMessage) error {
+		panic("handler exploded")
+	})
+	require.NoError(t, err)
+	_, err = topicManager.AddServiceHandler(ctx, "ls_test", "example.com", createMockServiceHandler(&healthyCalled, false))
+	require.NoError(t, err)
+
+	err = topicManager.HandleServiceMessage(ctx, createTestServiceMessage("ls_test", "example.com", "msg-1", "test payload"))
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errTestHandler)
+	require.ErrorIs(t, err, shared.ErrHandlerPanicked)
+	assert.True(t, failingCalled)
+	assert.True(t, healthyCalled)
+}
+
+func TestRemoveServiceHandler_Independent(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	ctx := context.Background()
+
+	firstCalled, secondCalled := false, false
+	firstID, err := topicManager.AddServiceHandler(ctx, "ls_test", "*", createMockServiceHandler(&firstCalled, false))
+	require.NoError(t, err)
+	secondID, err := topicManager.AddServiceHandler(ctx, "ls_test", "*", createMockServiceHandler(&secondCalled, false))
+	require.NoError(t, err)
+
+	require.NoError(t, topicManager.RemoveServiceHandler(ctx, firstID))
+	assert.True(t, topicManager.IsSubscribedToService("ls_test", "*"))
+
+	require.NoError(t, topicManager.HandleServiceMessage(ctx, createTestServiceMessage("ls_test", "example.com", "msg-1", "test payload")))
+	assert.False(t, firstCalled)
+	assert.True(t, secondCalled)
+
+	// Removing the last handler deactivates the subscription
+	require.NoError(t, topicManager.RemoveServiceHandler(ctx, secondID))
+	assert.False(t, topicManager.IsSubscribedToService("ls_test", "*"))
+	assert.Empty(t, topicManager.handlers)
+
+	err = topicManager.RemoveServiceHandler(ctx, secondID)
+	require.ErrorIs(t, err, errHandlerNotFound)
+}
+
+func TestUnsubscribeFromService_RemovesAllHandlers(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	ctx := context.Background()
+
+	handlerCalled := false
+	id, err := topicManager.AddServiceHandler(ctx, "ls_test", "example.com", createMockServiceHandler(&handlerCalled, false))
+	require.NoError(t, err)
+	require.NoError(t, topicManager.SubscribeToService(ctx, "ls_test", "example.com", createMockServiceHandler(&handlerCalled, false)))
+
+	require.NoError(t, topicManager.UnsubscribeFromService(ctx, "ls_test", "example.com"))
+
+	assert.Empty(t, topicManager.handlers)
+	assert.Empty(t, topicManager.handlerSubscriptions)
+	require.ErrorIs(t, topicManager.RemoveServiceHandler(ctx, id), errHandlerNotFound)
+}
+
+// Test admittance policy
+
+func TestIdentifyAdmissibleOutputs_DomainPolicy(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	topicManager.SetAdmittancePolicy(shared.NewDomainPolicy(nil, []string{"https://blocked.example.com"}))
+	beef, txid, _ := createSignedSLAPBEEF(t, "ls_foo", "https://blocked.example.com", "https://example.com")
+
+	instructions, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1}, instructions.OutputsToAdmit)
+}
+
+func TestIdentifyAdmissibleOutputs_IdentityKeyAllowList(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	beef, txid, identityKey := createSignedSLAPBEEF(t, "ls_foo", "https://example.com")
+
+	topicManager.SetAdmittancePolicy(shared.NewIdentityKeyPolicy([]string{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"}, nil))
+	instructions, err := topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Empty(t, instructions.OutputsToAdmit)
+
+	topicManager.SetAdmittancePolicy(shared.NewIdentityKeyPolicy([]string{identityKey}, nil))
+	instructions, err = topicManager.IdentifyAdmissibleOutputs(context.Background(), beef, txid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0}, instructions.OutputsToAdmit)
+}
+
+func TestIdentifyAdmissibleOutputsWithReport_RejectionReasons(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	beef, txid, _ := createSignedSLAPBEEF(t, "ls_foo", "https://example.com", "not a uri")
+
+	report, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0}, report.Instructions.OutputsToAdmit)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, uint32(1), report.Rejections[0].OutputIndex)
+	assert.Equal(t, shared.RejectionInvalidURI, report.Rejections[0].Reason)
+
+	// A SHIP token is rejected by the SLAP topic manager
+	scriptObj, err := script.NewFromHex(createValidPushDropScript([][]byte{
+		[]byte("SHIP"), {0x01}, []byte("https://example.com"), []byte("ls_foo"), {0x02},
+	}))
+	require.NoError(t, err)
+	beefBytes, _, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+	beef, txid, err = transaction.NewBeefFromAtomicBytes(beefBytes)
+	require.NoError(t, err)
+
+	report, err = topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, txid, nil)
+
+	require.NoError(t, err)
+	require.Len(t, report.Rejections, 1)
+	assert.Equal(t, shared.RejectionWrongIdentifier, report.Rejections[0].Reason)
+
+	stats := topicManager.GetAdmittanceStats()
+	assert.Equal(t, uint64(1), stats.Admitted)
+	assert.Equal(t, uint64(1), stats.Rejected[shared.RejectionInvalidURI])
+	assert.Equal(t, uint64(1), stats.Rejected[shared.RejectionWrongIdentifier])
+}
+
+func TestIdentifyAdmissibleOutputsWithReport_TransactionNotFound(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	beef, _, _ := createSignedSLAPBEEF(t, "ls_foo", "https://example.com")
+
+	report, err := topicManager.IdentifyAdmissibleOutputsWithReport(context.Background(), beef, &chainhash.Hash{}, nil)
+
+	require.NoError(t, err)
+	assert.False(t, report.TransactionFound)
+	assert.Empty(t, report.Instructions.OutputsToAdmit)
+	assert.Empty(t, report.Rejections)
+}
+
 // Test CreateServiceSubscription
 
 func TestCreateServiceSubscription_Success(t *testing.T) {
@@ -775,3 +1030,235 @@ func TestIntegrationWithLookupService(t *testing.T) {
 	// Storage should be the same instance
 	assert.Equal(t, mockStorage, topicManager.storage)
 }
+
+func TestAdmittedRecordPublishedToAdvertisedService(t *testing.T) {
+	topicManager, mockStorage, lookupService := createTestSLAPTopicManagerWithLookupService()
+
+	var received []ServiceMessage
+	err := topicManager.SubscribeToService(context.Background(), "ls_treasury", "https://example.com", func(_ context.Context, message ServiceMessage) error {
+		received = append(received, message)
+		return nil
+	})
+	require.NoError(t, err)
+
+	fields := [][]byte{
+		[]byte("SLAP"),
+		{0x01, 0x02, 0x03, 0x04},
+		[]byte("https://example.com"),
+		[]byte("ls_treasury"),
+	}
+	scriptObj, err := script.NewFromHex(createValidPushDropScript(fields))
+	require.NoError(t, err)
+	beefBytes, txidHex, err := createTestBEEFWithScript(scriptObj)
+	require.NoError(t, err)
+
+	mockStorage.On("StoreSLAPRecord", mock.Anything, txidHex, 0, "01020304", "https://example.com", "ls_treasury").Return(nil)
+
+	err = lookupService.OutputAdmittedByTopic(context.Background(), &engine.OutputAdmittedByTopic{
+		Topic:       Topic,
+		OutputIndex: 0,
+		AtomicBEEF:  beefBytes,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, received, 1)
+	assert.Equal(t, "ls_treasury", received[0].Service)
+	assert.Equal(t, "https://example.com", received[0].Domain)
+	assert.Equal(t, "01020304", received[0].IdentityKey)
+	event, ok := received[0].Payload.(types.RecordEvent)
+	require.True(t, ok)
+	assert.Equal(t, types.RecordEventAdmitted, event.Type)
+	assert.Equal(t, "SLAP", event.Protocol)
+	assert.Equal(t, txidHex, event.Txid)
+}
+
+func TestUnknownRemovedRecordNotPublished(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+
+	handlerCalled := false
+	err := topicManager.SubscribeToService(context.Background(), "ls_treasury", "https://example.com", createMockServiceHandler(&handlerCalled, false))
+	require.NoError(t, err)
+
+	topicManager.publishRecordEvent(context.Background(), types.RecordEvent{
+		Type:     types.RecordEventRemoved,
+		Protocol: "SLAP",
+		Txid:     TxID,
+	})
+
+	assert.False(t, handlerCalled)
+}
+
+// Test wildcard subscriptions
+
+func TestSubscribeToService_InvalidPattern(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+
+	handlerCalled := false
+	handler := createMockServiceHandler(&handlerCalled, false)
+
+	err := topicManager.SubscribeToService(context.Background(), "ls_*treasury", "example.com", handler)
+	require.ErrorIs(t, err, errInvalidServicePattern)
+
+	err = topicManager.SubscribeToService(context.Background(), "ls_treasury", "*.example.com", handler)
+	require.ErrorIs(t, err, errInvalidDomainPattern)
+
+	_, err = topicManager.CreateServiceSubscription(context.Background(), "ls_*treasury", "example.com")
+	require.ErrorIs(t, err, errInvalidServicePattern)
+
+	assert.Equal(t, 0, topicManager.GetActiveServiceCount())
+}
+
+func TestHandleServiceMessage_WildcardDomain(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+
+	handlerCalled := false
+	err := topicManager.SubscribeToService(context.Background(), "ls_treasury", WildcardDomain, createMockServiceHandler(&handlerCalled, false))
+	require.NoError(t, err)
+
+	err = topicManager.HandleServiceMessage(context.Background(), createTestServiceMessage("ls_treasury", "any.example.com", "msg-1", nil))
+	require.NoError(t, err)
+	assert.True(t, handlerCalled)
+	assert.Equal(t, int64(1), topicManager.GetServiceMessageCount("ls_treasury", WildcardDomain))
+
+	handlerCalled = false
+	err = topicManager.HandleServiceMessage(context.Background(), createTestServiceMessage("ls_other", "any.example.com", "msg-2", nil))
+	require.NoError(t, err)
+	assert.False(t, handlerCalled)
+}
+
+func TestHandleServiceMessage_ServicePrefix(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+
+	handlerCalled := false
+	err := topicManager.SubscribeToService(context.Background(), "ls_tre*", "example.com", createMockServiceHandler(&handlerCalled, false))
+	require.NoError(t, err)
+
+	err = topicManager.HandleServiceMessage(context.Background(), createTestServiceMessage("ls_treasury", "example.com", "msg-1", nil))
+	require.NoError(t, err)
+	assert.True(t, handlerCalled)
+
+	handlerCalled = false
+	err = topicManager.HandleServiceMessage(context.Background(), createTestServiceMessage("ls_treasury", "other.com", "msg-2", nil))
+	require.NoError(t, err)
+	assert.False(t, handlerCalled)
+}
+
+func TestHandleServiceMessage_DeliversToEveryMatch(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+
+	var received []string
+	subscribe := func(service, domain string) {
+		label := service + "@" + domain
+		err := topicManager.SubscribeToService(context.Background(), service, domain, func(_ context.Context, _ ServiceMessage) error {
+			received = append(received, label)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	subscribe(WildcardService, WildcardDomain)
+	subscribe(WildcardService, "example.com")
+	subscribe("ls_tre*", WildcardDomain)
+	subscribe("ls_treasury", WildcardDomain)
+	subscribe("ls_treasury", "example.com")
+
+	send := func(service, domain string) []string {
+		received = nil
+		err := topicManager.HandleServiceMessage(context.Background(), createTestServiceMessage(service, domain, "msg", nil))
+		require.NoError(t, err)
+		return received
+	}
+
+	// Every matching subscription receives the message, most specific first
+	assert.Equal(t, []string{"ls_treasury@example.com", "ls_treasury@*", "ls_tre*@*", "*@example.com", "*@*"}, send("ls_treasury", "example.com"))
+	assert.Equal(t, []string{"ls_treasury@*", "ls_tre*@*", "*@*"}, send("ls_treasury", "other.com"))
+	assert.Equal(t, []string{"ls_tre*@*", "*@example.com", "*@*"}, send("ls_trees", "example.com"))
+	assert.Equal(t, []string{"*@*"}, send("ls_identity", "other.com"))
+	assert.Equal(t, int64(4), topicManager.GetServiceMessageCount(WildcardService, WildcardDomain))
+	assert.Equal(t, int64(1), topicManager.GetServiceMessageCount("ls_treasury", "example.com"))
+
+	// Inactive subscriptions are skipped
+	require.NoError(t, topicManager.UnsubscribeFromService(context.Background(), "ls_treasury", "example.com"))
+	assert.Equal(t, []string{"ls_treasury@*", "ls_tre*@*", "*@example.com", "*@*"}, send("ls_treasury", "example.com"))
+}
+
+func TestHandleServiceMessage_OverlappingSubscribers(t *testing.T) {
+	topicManager := createTestSLAPTopicManager()
+	ctx := context.Background()
+
+	var exact, prefix int
+	_, err := topicManager.AddServiceHandler(ctx, "ls_treasury", "example.com", func(context.Context, ServiceMessage) error {
+		exact++
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = topicManager.AddServiceHandler(ctx, "ls_tre*", WildcardDomain, func(context.Context, ServiceMessage) error {
+		prefix++
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, topicManager.HandleServiceMessage(ctx, createTestServiceMessage("ls_treasury", "example.com", "msg-1", nil)))
+	require.NoError(t, topicManager.HandleServiceMessage(ctx, createTestServiceMessage("ls_trees", "example.com", "msg-2", nil)))
+
+	assert.Equal(t, 1, exact)
+	assert.Equal(t, 2, prefix)
+}
+
+// Test Reindex
+
+func TestReindex_FromDirectory(t *testing.T) {
+	topicManager, mockStorage, _ := createTestSLAPTopicManagerWithLookupService()
+
+	rawBEEF, rawTxid, rawIdentity := createSignedSLAPBEEF(t, "ls_treasury", "https://one.example.com")
+	rawAtomic, err := rawBEEF.AtomicBytes(rawTxid)
+	require.NoError(t, err)
+	streamBEEF, streamTxid, streamIdentity := createSignedSLAPBEEF(t, "ls_treasury", "https://two.example.com", "https://three.example.com")
+	streamAtomic, err := streamBEEF.AtomicBytes(streamTxid)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.beef"), rawAtomic, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "b.jsonl"),
+		[]byte(fmt.Sprintf("{\"beef\":%q,\"outputIndexes\":[1]}\n", hex.EncodeToString(streamAtomic))), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.txt"), []byte("ignored"), 0o600))
+
+	rawTxidHex := hex.EncodeToString(rawTxid.CloneBytes())
+	streamTxidHex := hex.EncodeToString(streamTxid.CloneBytes())
+	mockStorage.On("FindByOutpoint", mock.Anything, rawTxidHex, 0).Return(nil, nil)
+	mockStorage.On("FindByOutpoint", mock.Anything, streamTxidHex, 1).Return(nil, nil)
+	mockStorage.On("StoreSLAPRecord", mock.Anything, rawTxidHex, 0, rawIdentity, "https://one.example.com", "ls_treasury").Return(nil)
+	mockStorage.On("StoreSLAPRecord", mock.Anything, streamTxidHex, 1, streamIdentity, "https://three.example.com", "ls_treasury").Return(nil)
+
+	source, err := shared.NewDirReindexSource(dir)
+	require.NoError(t, err)
+	defer func() { _ = source.Close() }()
+
+	report, err := topicManager.Reindex(context.Background(), source)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Transactions)
+	assert.Equal(t, 2, report.Stored)
+	assert.Empty(t, report.Rejections)
+	assert.Empty(t, report.Errors)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestReindex_InvalidBEEFIsReported(t *testing.T) {
+	topicManager, _, _ := createTestSLAPTopicManagerWithLookupService()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.beef"), []byte{0x01, 0x02}, 0o600))
+
+	source, err := shared.NewDirReindexSource(dir)
+	require.NoError(t, err)
+
+	report, err := topicManager.Reindex(context.Background(), source)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Transactions)
+	assert.Zero(t, report.Stored)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "broken.beef: invalid atomic BEEF")
+}
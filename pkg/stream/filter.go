@@ -0,0 +1,100 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/utils"
+)
+
+// Protocol identifiers accepted in the "protocol" query parameter.
+const (
+	// ProtocolSHIP selects SHIP record events
+	ProtocolSHIP = "ship"
+	// ProtocolSLAP selects SLAP record events
+	ProtocolSLAP = "slap"
+)
+
+// Static error variables for err113 compliance
+var (
+	errUnknownProtocol        = errors.New("protocol must be 'ship' or 'slap'")
+	errTopicRequiresSHIP      = errors.New("topic filter is only supported for SHIP subscriptions")
+	errServiceRequiresSLAP    = errors.New("service filter is only supported for SLAP subscriptions")
+	errHandlerClosed          = errors.New("stream handler is closed")
+	errNoTopicManagerProtocol = errors.New("no topic manager configured for protocol")
+)
+
+// Filter selects which record events are delivered to a subscriber.
+// Empty fields match any value. Service and Domain accept the SLAP subscription
+// wildcards: a service ending in "*" matches by prefix and the domain "*" matches any domain.
+// Domains are compared in the canonical form of utils.CanonicalDomain.
+type Filter struct {
+	// Protocol is the protocol to subscribe to ("ship" or "slap")
+	Protocol string `json:"protocol"`
+	// Topic filters SHIP events by advertised topic
+	Topic string `json:"topic,omitempty"`
+	// Service filters SLAP events by advertised service
+	Service string `json:"service,omitempty"`
+	// Domain filters events by advertised domain
+	Domain string `json:"domain,omitempty"`
+	// IdentityKey filters events by the advertiser's identity key
+	IdentityKey string `json:"identityKey,omitempty"`
+}
+
+// ParseFilter builds a Filter from subscribe request query parameters.
+// The protocol defaults to "ship".
+func ParseFilter(values url.Values) (Filter, error) {
+	filter := Filter{
+		Protocol:    strings.ToLower(values.Get("protocol")),
+		Topic:       values.Get("topic"),
+		Service:     values.Get("service"),
+		Domain:      slap.CanonicalDomainPattern(values.Get("domain")),
+		IdentityKey: values.Get("identityKey"),
+	}
+
+	if filter.Protocol == "" {
+		filter.Protocol = ProtocolSHIP
+	}
+
+	switch filter.Protocol {
+	case ProtocolSHIP:
+		if filter.Service != "" {
+			return Filter{}, errServiceRequiresSLAP
+		}
+	case ProtocolSLAP:
+		if filter.Topic != "" {
+			return Filter{}, errTopicRequiresSHIP
+		}
+		if err := slap.ValidateSubscriptionPattern(filter.Service, filter.Domain); err != nil {
+			return Filter{}, err
+		}
+	default:
+		return Filter{}, fmt.Errorf("%w: got '%s'", errUnknownProtocol, filter.Protocol)
+	}
+
+	return filter, nil
+}
+
+// Matches reports whether the event satisfies every non-empty field of the filter.
+func (f Filter) Matches(event types.RecordEvent) bool {
+	if !strings.EqualFold(f.Protocol, event.Protocol) {
+		return false
+	}
+	if f.Topic != "" && f.Topic != event.Topic {
+		return false
+	}
+	if f.Service != "" && !slap.ServiceMatches(f.Service, event.Service) {
+		return false
+	}
+	if f.Domain != "" && !slap.DomainMatches(slap.CanonicalDomainPattern(f.Domain), utils.CanonicalDomain(event.Domain)) {
+		return false
+	}
+	if f.IdentityKey != "" && f.IdentityKey != event.IdentityKey {
+		return false
+	}
+	return true
+}
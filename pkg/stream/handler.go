@@ -0,0 +1,304 @@
+// Package stream implements a Server-Sent Events endpoint that streams SHIP and SLAP
+// record events published by the topic managers to remote subscribers.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// clientBufferSize is the number of events buffered per subscriber before events are dropped.
+const clientBufferSize = 64
+
+// client is a single connected subscriber.
+type client struct {
+	filter Filter
+	events chan types.RecordEvent
+	// slapKey is the SLAP subscription the client holds a reference to, if any
+	slapKey *slapKey
+}
+
+// slapKey identifies a SLAP topic manager subscription.
+type slapKey struct {
+	service string
+	domain  string
+}
+
+// slapSubscription is the handler registered for a SLAP pattern and the number of connected
+// subscribers relying on it.
+type slapSubscription struct {
+	id      shared.SubscriptionID
+	clients int
+}
+
+// Handler is an http.Handler that streams record events to subscribers as Server-Sent Events.
+// Subscribers select events with query parameters parsed by ParseFilter. Each event is written
+// with its type ("admitted" or "removed") as the SSE event name and the JSON-encoded
+// types.RecordEvent as data. Slow subscribers whose buffer is full miss events rather than
+// blocking delivery to others.
+type Handler struct {
+	// shipTopicManager publishes SHIP record events (optional)
+	shipTopicManager *ship.TopicManager
+	// slapTopicManager publishes SLAP record events (optional)
+	slapTopicManager *slap.TopicManager
+	// shipHandlerID identifies the handler registered on the SHIP topic
+	shipHandlerID shared.SubscriptionID
+	// clients holds all connected subscribers
+	clients map[*client]struct{}
+	// slapSubscriptions holds the handler registered for each service+domain pattern on the
+	// SLAP topic manager, removed when its last subscriber disconnects
+	slapSubscriptions map[slapKey]*slapSubscription
+	// closed is set once Close has been called
+	closed bool
+	// mutex protects concurrent access to clients, slapSubscriptions and closed
+	mutex sync.RWMutex
+}
+
+// NewHandler creates a new stream handler backed by the given topic managers.
+// Either topic manager may be nil, in which case subscriptions to that protocol are rejected.
+// The handler subscribes to the SHIP topic manager's topic immediately and to SLAP service and domain
+// patterns as subscribers request them, using "*" for filters a subscriber leaves empty.
+// Its handlers are added alongside any others registered on the topic managers.
+func NewHandler(ctx context.Context, shipTopicManager *ship.TopicManager, slapTopicManager *slap.TopicManager) (*Handler, error) {
+	h := &Handler{
+		shipTopicManager:  shipTopicManager,
+		slapTopicManager:  slapTopicManager,
+		clients:           make(map[*client]struct{}),
+		slapSubscriptions: make(map[slapKey]*slapSubscription),
+	}
+
+	if shipTopicManager != nil {
+		id, err := shipTopicManager.AddTopicHandler(ctx, shipTopicManager.Topic(), h.handleSHIPMessage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to subscribe to SHIP topic: %w", err)
+		}
+		h.shipHandlerID = id
+	}
+
+	return h, nil
+}
+
+// ServeHTTP implements http.Handler. It holds the connection open and writes matching
+// record events until the client disconnects or the handler is closed.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	c, err := h.register(r.Context(), filter)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, errNoTopicManagerProtocol) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	defer func() {
+		// The response is already under way, so a failure to unsubscribe cannot be reported
+		_ = h.unregister(context.WithoutCancel(r.Context()), c)
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, ": subscribed\n\n"); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-c.events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Close disconnects all subscribers and removes the handler's topic manager handlers,
+// leaving handlers registered by other components in place.
+func (h *Handler) Close(ctx context.Context) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+
+	for c := range h.clients {
+		close(c.events)
+	}
+	h.clients = make(map[*client]struct{})
+
+	var errs []error
+	if h.shipTopicManager != nil {
+		errs = append(errs, h.shipTopicManager.RemoveTopicHandler(ctx, h.shipHandlerID))
+	}
+	for _, subscription := range h.slapSubscriptions {
+		errs = append(errs, h.slapTopicManager.RemoveServiceHandler(ctx, subscription.id))
+	}
+	h.slapSubscriptions = make(map[slapKey]*slapSubscription)
+
+	return errors.Join(errs...)
+}
+
+// register adds a subscriber, subscribing to the SLAP service if this is the first
+// subscriber for it.
+func (h *Handler) register(ctx context.Context, filter Filter) (*client, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return nil, errHandlerClosed
+	}
+
+	c := &client{
+		filter: filter,
+		events: make(chan types.RecordEvent, clientBufferSize),
+	}
+
+	switch filter.Protocol {
+	case ProtocolSHIP:
+		if h.shipTopicManager == nil {
+			return nil, fmt.Errorf("%w: %s", errNoTopicManagerProtocol, filter.Protocol)
+		}
+	case ProtocolSLAP:
+		if h.slapTopicManager == nil {
+			return nil, fmt.Errorf("%w: %s", errNoTopicManagerProtocol, filter.Protocol)
+		}
+		key := slapKey{service: slap.WildcardService, domain: slap.WildcardDomain}
+		if filter.Service != "" {
+			key.service = filter.Service
+		}
+		if filter.Domain != "" {
+			key.domain = filter.Domain
+		}
+		subscription, exists := h.slapSubscriptions[key]
+		if !exists {
+			id, err := h.slapTopicManager.AddServiceHandler(ctx, key.service, key.domain, h.slapMessageHandler(key))
+			if err != nil {
+				return nil, fmt.Errorf("failed to subscribe to SLAP service: %w", err)
+			}
+			subscription = &slapSubscription{id: id}
+			h.slapSubscriptions[key] = subscription
+		}
+		subscription.clients++
+		c.slapKey = &key
+	}
+
+	h.clients[c] = struct{}{}
+
+	return c, nil
+}
+
+// unregister removes a subscriber, unsubscribing from its SLAP service once no other
+// subscriber needs it.
+func (h *Handler) unregister(ctx context.Context, c *client) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, exists := h.clients[c]; !exists {
+		// Already removed by Close, which also removed every SLAP handler
+		return nil
+	}
+	delete(h.clients, c)
+
+	if c.slapKey == nil {
+		return nil
+	}
+	subscription := h.slapSubscriptions[*c.slapKey]
+	subscription.clients--
+	if subscription.clients > 0 {
+		return nil
+	}
+	delete(h.slapSubscriptions, *c.slapKey)
+	if err := h.slapTopicManager.RemoveServiceHandler(ctx, subscription.id); err != nil {
+		return fmt.Errorf("failed to unsubscribe from SLAP service: %w", err)
+	}
+	return nil
+}
+
+// handleSHIPMessage receives SHIP topic manager messages and broadcasts their record events.
+func (h *Handler) handleSHIPMessage(_ context.Context, message ship.TopicMessage) error {
+	if event, ok := message.Payload.(types.RecordEvent); ok {
+		h.broadcast(event, nil)
+	}
+	return nil
+}
+
+// slapMessageHandler returns the handler receiving SLAP topic manager messages for the
+// subscription key. The topic manager delivers a message to every matching subscription, so
+// each handler only serves the subscribers holding its own subscription.
+func (h *Handler) slapMessageHandler(key slapKey) slap.ServiceMessageHandler {
+	return func(_ context.Context, message slap.ServiceMessage) error {
+		if event, ok := message.Payload.(types.RecordEvent); ok {
+			h.broadcast(event, &key)
+		}
+		return nil
+	}
+}
+
+// broadcast queues the event for every subscriber whose filter matches it, limited to the
+// subscribers of the SLAP subscription key when one is given.
+func (h *Handler) broadcast(event types.RecordEvent, key *slapKey) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for c := range h.clients {
+		if key != nil && (c.slapKey == nil || *c.slapKey != *key) {
+			continue
+		}
+		if !c.filter.Matches(event) {
+			continue
+		}
+		select {
+		case c.events <- event:
+		default:
+			// Subscriber is not keeping up; drop the event rather than block delivery
+		}
+	}
+}
+
+// writeEvent writes a record event in Server-Sent Events format.
+func writeEvent(w http.ResponseWriter, event types.RecordEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode record event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s:%s.%d\nevent: %s\ndata: %s\n\n", event.Type, event.Txid, event.OutputIndex, event.Type, data)
+	return err
+}
@@ -0,0 +1,449 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Test helper functions
+
+func createTestHandler(t *testing.T) (*Handler, *ship.TopicManager, *slap.TopicManager) {
+	t.Helper()
+
+	shipTopicManager := ship.NewTopicManager(nil, nil)
+	slapTopicManager := slap.NewTopicManager(nil, nil)
+
+	handler, err := NewHandler(context.Background(), shipTopicManager, slapTopicManager)
+	require.NoError(t, err)
+
+	return handler, shipTopicManager, slapTopicManager
+}
+
+// subscribe opens a stream and waits for the subscription acknowledgement.
+func subscribe(t *testing.T, server *httptest.Server, query string) *bufio.Reader {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?"+query, nil)
+	require.NoError(t, err)
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": subscribed\n", line)
+	_, err = reader.ReadString('\n')
+	require.NoError(t, err)
+
+	return reader
+}
+
+// readEvent reads the next SSE event and returns its name and decoded data.
+func readEvent(t *testing.T, reader *bufio.Reader) (string, types.RecordEvent) {
+	t.Helper()
+
+	var name string
+	var event types.RecordEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			return name, event
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		}
+	}
+}
+
+func createSHIPEvent(eventType types.RecordEventType, txid, topic string) types.RecordEvent {
+	return types.SHIPRecord{
+		Txid:        txid,
+		OutputIndex: 0,
+		IdentityKey: "02abc",
+		Domain:      "https://example.com",
+		Topic:       topic,
+	}.Event(eventType)
+}
+
+// Test ParseFilter
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected Filter
+		errMsg   string
+	}{
+		{
+			name:     "defaults to SHIP",
+			query:    "",
+			expected: Filter{Protocol: ProtocolSHIP},
+		},
+		{
+			name:     "SHIP with filters",
+			query:    "protocol=SHIP&topic=tm_foo&domain=https://example.com&identityKey=02abc",
+			expected: Filter{Protocol: ProtocolSHIP, Topic: "tm_foo", Domain: "https://example.com", IdentityKey: "02abc"},
+		},
+		{
+			name:     "SLAP with service and domain",
+			query:    "protocol=slap&service=ls_foo&domain=https://example.com",
+			expected: Filter{Protocol: ProtocolSLAP, Service: "ls_foo", Domain: "https://example.com"},
+		},
+		{
+			name:   "unknown protocol",
+			query:  "protocol=other",
+			errMsg: "protocol must be 'ship' or 'slap'",
+		},
+		{
+			name:   "service on SHIP",
+			query:  "service=ls_foo",
+			errMsg: "service filter is only supported for SLAP subscriptions",
+		},
+		{
+			name:   "topic on SLAP",
+			query:  "protocol=slap&topic=tm_foo&service=ls_foo&domain=https://example.com",
+			errMsg: "topic filter is only supported for SHIP subscriptions",
+		},
+		{
+			name:     "SLAP without filters",
+			query:    "protocol=slap",
+			expected: Filter{Protocol: ProtocolSLAP},
+		},
+		{
+			name:     "SLAP with service prefix and wildcard domain",
+			query:    "protocol=slap&service=ls_f*&domain=*",
+			expected: Filter{Protocol: ProtocolSLAP, Service: "ls_f*", Domain: "*"},
+		},
+		{
+			name:   "SLAP with invalid service pattern",
+			query:  "protocol=slap&service=ls_*foo",
+			errMsg: "service wildcard '*' is only allowed as the final character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			filter, err := ParseFilter(values)
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
+
+// Test Filter.Matches
+
+func TestFilterMatches(t *testing.T) {
+	event := createSHIPEvent(types.RecordEventAdmitted, "aa", "tm_foo")
+
+	assert.True(t, Filter{Protocol: ProtocolSHIP}.Matches(event))
+	assert.True(t, Filter{Protocol: ProtocolSHIP, Topic: "tm_foo", IdentityKey: "02abc"}.Matches(event))
+	assert.False(t, Filter{Protocol: ProtocolSLAP}.Matches(event))
+	assert.False(t, Filter{Protocol: ProtocolSHIP, Topic: "tm_bar"}.Matches(event))
+	assert.False(t, Filter{Protocol: ProtocolSHIP, Domain: "https://other.com"}.Matches(event))
+	assert.False(t, Filter{Protocol: ProtocolSHIP, IdentityKey: "03def"}.Matches(event))
+
+	// Domains match in canonical form
+	assert.True(t, Filter{Protocol: ProtocolSHIP, Domain: "HTTPS://Example.com:443/"}.Matches(event))
+}
+
+func TestParseFilter_CanonicalDomain(t *testing.T) {
+	filter, err := ParseFilter(url.Values{"protocol": {"slap"}, "domain": {"https://Example.COM/"}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", filter.Domain)
+
+	filter, err = ParseFilter(url.Values{"protocol": {"slap"}, "domain": {slap.WildcardDomain}})
+	require.NoError(t, err)
+	assert.Equal(t, slap.WildcardDomain, filter.Domain)
+}
+
+// Test ServeHTTP
+
+func TestServeHTTP_StreamsMatchingSHIPEvents(t *testing.T) {
+	handler, shipTopicManager, _ := createTestHandler(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	reader := subscribe(t, server, "topic=tm_foo")
+
+	ctx := context.Background()
+	for _, event := range []types.RecordEvent{
+		createSHIPEvent(types.RecordEventAdmitted, "aa", "tm_bar"),
+		createSHIPEvent(types.RecordEventAdmitted, "bb", "tm_foo"),
+		createSHIPEvent(types.RecordEventRemoved, "bb", "tm_foo"),
+	} {
+		require.NoError(t, shipTopicManager.HandleTopicMessage(ctx, ship.TopicMessage{
+			Topic:      ship.Topic,
+			Payload:    event,
+			ReceivedAt: time.Now(),
+		}))
+	}
+
+	name, event := readEvent(t, reader)
+	assert.Equal(t, "admitted", name)
+	assert.Equal(t, "bb", event.Txid)
+	assert.Equal(t, "tm_foo", event.Topic)
+
+	name, event = readEvent(t, reader)
+	assert.Equal(t, "removed", name)
+	assert.Equal(t, "bb", event.Txid)
+}
+
+func TestServeHTTP_StreamsEventsOnConfiguredTopic(t *testing.T) {
+	shipTopicManager, err := ship.NewTopicManagerWithOptions(nil, nil, shared.WithTopic("tm_ship_test"))
+	require.NoError(t, err)
+	handler, err := NewHandler(context.Background(), shipTopicManager, nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	reader := subscribe(t, server, "")
+	assert.True(t, shipTopicManager.IsSubscribedToTopic("tm_ship_test"))
+	assert.False(t, shipTopicManager.IsSubscribedToTopic(ship.Topic))
+
+	require.NoError(t, shipTopicManager.HandleTopicMessage(context.Background(), ship.TopicMessage{
+		Topic:   "tm_ship_test",
+		Payload: createSHIPEvent(types.RecordEventAdmitted, "aa", "tm_foo"),
+	}))
+
+	_, event := readEvent(t, reader)
+	assert.Equal(t, "aa", event.Txid)
+}
+
+func TestServeHTTP_StreamsSLAPEvents(t *testing.T) {
+	handler, _, slapTopicManager := createTestHandler(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	reader := subscribe(t, server, "protocol=slap&service=ls_foo&domain=https://example.com")
+	assert.True(t, slapTopicManager.IsSubscribedToService("ls_foo", "https://example.com"))
+
+	event := types.SLAPRecord{
+		Txid:        "cc",
+		OutputIndex: 1,
+		IdentityKey: "02abc",
+		Domain:      "https://example.com",
+		Service:     "ls_foo",
+	}.Event(types.RecordEventAdmitted)
+
+	require.NoError(t, slapTopicManager.HandleServiceMessage(context.Background(), slap.ServiceMessage{
+		Service: "ls_foo",
+		Domain:  "https://example.com",
+		Payload: event,
+	}))
+
+	name, received := readEvent(t, reader)
+	assert.Equal(t, "admitted", name)
+	assert.Equal(t, event.Txid, received.Txid)
+	assert.Equal(t, 1, received.OutputIndex)
+	assert.Equal(t, "ls_foo", received.Service)
+}
+
+func TestServeHTTP_InvalidFilter(t *testing.T) {
+	handler, _, _ := createTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?protocol=other", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServeHTTP_MethodNotAllowed(t *testing.T) {
+	handler, _, _ := createTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodGet, rec.Header().Get("Allow"))
+}
+
+func TestServeHTTP_ProtocolWithoutTopicManager(t *testing.T) {
+	handler, err := NewHandler(context.Background(), ship.NewTopicManager(nil, nil), nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?protocol=slap&service=ls_foo&domain=example.com", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// Test Close
+
+func TestClose_EndsStreamsAndUnsubscribes(t *testing.T) {
+	handler, shipTopicManager, slapTopicManager := createTestHandler(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	reader := subscribe(t, server, "protocol=slap&service=ls_foo&domain=https://example.com")
+
+	require.NoError(t, handler.Close(context.Background()))
+
+	_, err := reader.ReadString('\n')
+	require.Error(t, err)
+	assert.False(t, shipTopicManager.IsSubscribedToTopic(ship.Topic))
+	assert.False(t, slapTopicManager.IsSubscribedToService("ls_foo", "https://example.com"))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestClose_LeavesOtherHandlersSubscribed(t *testing.T) {
+	shipTopicManager := ship.NewTopicManager(nil, nil)
+	ctx := context.Background()
+
+	var received []ship.TopicMessage
+	require.NoError(t, shipTopicManager.SubscribeToTopic(ctx, ship.Topic, func(_ context.Context, message ship.TopicMessage) error {
+		received = append(received, message)
+		return nil
+	}))
+
+	handler, err := NewHandler(ctx, shipTopicManager, nil)
+	require.NoError(t, err)
+	require.NoError(t, handler.Close(ctx))
+
+	assert.True(t, shipTopicManager.IsSubscribedToTopic(ship.Topic))
+	require.NoError(t, shipTopicManager.HandleTopicMessage(ctx, ship.TopicMessage{
+		Topic:   ship.Topic,
+		Payload: createSHIPEvent(types.RecordEventAdmitted, "aa", "tm_foo"),
+	}))
+	assert.Len(t, received, 1)
+}
+
+func TestServeHTTP_StreamsSLAPEventsByServicePrefix(t *testing.T) {
+	handler, _, slapTopicManager := createTestHandler(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	reader := subscribe(t, server, "protocol=slap&service=ls_f*")
+	assert.True(t, slapTopicManager.IsSubscribedToService("ls_f*", slap.WildcardDomain))
+
+	for _, record := range []types.SLAPRecord{
+		{Txid: "aa", Domain: "https://one.example.com", Service: "ls_bar"},
+		{Txid: "bb", Domain: "https://two.example.com", Service: "ls_foo"},
+	} {
+		require.NoError(t, slapTopicManager.HandleServiceMessage(context.Background(), slap.ServiceMessage{
+			Service: record.Service,
+			Domain:  record.Domain,
+			Payload: record.Event(types.RecordEventAdmitted),
+		}))
+	}
+
+	_, received := readEvent(t, reader)
+	assert.Equal(t, "bb", received.Txid)
+	assert.Equal(t, "ls_foo", received.Service)
+}
+
+func TestUnregister_RemovesSLAPHandlerWithLastSubscriber(t *testing.T) {
+	handler, _, slapTopicManager := createTestHandler(t)
+	ctx := context.Background()
+	filter := Filter{Protocol: ProtocolSLAP, Service: "ls_foo", Domain: "https://example.com"}
+
+	first, err := handler.register(ctx, filter)
+	require.NoError(t, err)
+	second, err := handler.register(ctx, filter)
+	require.NoError(t, err)
+	other, err := handler.register(ctx, Filter{Protocol: ProtocolSLAP, Service: "ls_bar"})
+	require.NoError(t, err)
+
+	require.NoError(t, handler.unregister(ctx, first))
+	assert.True(t, slapTopicManager.IsSubscribedToService("ls_foo", "https://example.com"))
+
+	require.NoError(t, handler.unregister(ctx, second))
+	assert.False(t, slapTopicManager.IsSubscribedToService("ls_foo", "https://example.com"))
+	assert.True(t, slapTopicManager.IsSubscribedToService("ls_bar", slap.WildcardDomain))
+
+	require.NoError(t, handler.unregister(ctx, other))
+	assert.False(t, slapTopicManager.IsSubscribedToService("ls_bar", slap.WildcardDomain))
+	assert.Equal(t, 0, slapTopicManager.GetActiveServiceCount())
+
+	// A new subscriber registers a fresh handler
+	again, err := handler.register(ctx, filter)
+	require.NoError(t, err)
+	assert.True(t, slapTopicManager.IsSubscribedToService("ls_foo", "https://example.com"))
+	require.NoError(t, handler.Close(ctx))
+	require.NoError(t, handler.unregister(ctx, again))
+}
+
+func TestServeHTTP_DisconnectRemovesSLAPHandler(t *testing.T) {
+	handler, _, slapTopicManager := createTestHandler(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?protocol=slap&service=ls_foo", nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.True(t, slapTopicManager.IsSubscribedToService("ls_foo", slap.WildcardDomain))
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		return !slapTopicManager.IsSubscribedToService("ls_foo", slap.WildcardDomain)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServeHTTP_OverlappingSLAPSubscribersReceiveEventsOnce(t *testing.T) {
+	handler, _, slapTopicManager := createTestHandler(t)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	exact := subscribe(t, server, "protocol=slap&service=ls_foo")
+	prefix := subscribe(t, server, "protocol=slap&service=ls_f*")
+
+	for _, record := range []types.SLAPRecord{
+		{Txid: "aa", Domain: "https://example.com", Service: "ls_foo"},
+		{Txid: "bb", Domain: "https://example.com", Service: "ls_fab"},
+	} {
+		require.NoError(t, slapTopicManager.HandleServiceMessage(context.Background(), slap.ServiceMessage{
+			Service: record.Service,
+			Domain:  record.Domain,
+			Payload: record.Event(types.RecordEventAdmitted),
+		}))
+	}
+
+	// Each subscriber receives every matching event exactly once, in order
+	_, received := readEvent(t, exact)
+	assert.Equal(t, "aa", received.Txid)
+	_, received = readEvent(t, prefix)
+	assert.Equal(t, "aa", received.Txid)
+	_, received = readEvent(t, prefix)
+	assert.Equal(t, "bb", received.Txid)
+}
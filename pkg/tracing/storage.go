@@ -0,0 +1,372 @@
+// Package tracing records OpenTelemetry spans for SHIP and SLAP storage operations. The shared
+// helpers, topic managers and lookup services record their own spans; see shared.Tracer and
+// the SetTracerProvider methods. Wrapping their storage with InstrumentSHIPStorage or
+// InstrumentSLAPStorage nests the storage spans under them:
+//
+//	storage = tracing.InstrumentSHIPStorage(storage, provider)
+//	lookupService := ship.NewLookupService(storage)
+//	lookupService.SetTracerProvider(provider)
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/types"
+)
+
+// Compile-time verification that the instrumented storages implement the storage interfaces
+var (
+	_ ship.StorageInterface     = (*shipStorage)(nil)
+	_ ship.RecordReader         = (*shipStorage)(nil)
+	_ ship.RecordQuerier        = (*shipStorage)(nil)
+	_ ship.TokenRecordStore     = (*shipStorage)(nil)
+	_ ship.BackdatedRecordStore = (*shipStorage)(nil)
+	_ ship.RecordLister         = (*shipStorage)(nil)
+	_ ship.RecordIterator       = (*shipStorage)(nil)
+	_ ship.RecordImporter       = (*shipStorage)(nil)
+	_ ship.RecordCounter        = (*shipStorage)(nil)
+	_ slap.StorageInterface     = (*slapStorage)(nil)
+	_ slap.RecordReader         = (*slapStorage)(nil)
+	_ slap.RecordQuerier        = (*slapStorage)(nil)
+	_ slap.TokenRecordStore     = (*slapStorage)(nil)
+	_ slap.BackdatedRecordStore = (*slapStorage)(nil)
+	_ slap.RecordLister         = (*slapStorage)(nil)
+	_ slap.RecordIterator       = (*slapStorage)(nil)
+	_ slap.RecordImporter       = (*slapStorage)(nil)
+	_ slap.RecordCounter        = (*slapStorage)(nil)
+)
+
+// InstrumentSHIPStorage returns a storage recording a span for every operation on storage.
+// A nil provider joins the provider of the span in the context, as described by shared.Tracer.
+// It implements every optional ship storage interface, such as ship.RecordReader;
+// operations storage lacks fail with shared.ErrStorageUnsupported.
+func InstrumentSHIPStorage(storage ship.StorageInterface, provider trace.TracerProvider) ship.StorageInterface {
+	return &shipStorage{next: storage, provider: provider}
+}
+
+// InstrumentSLAPStorage returns a storage recording a span for every operation on storage.
+// A nil provider joins the provider of the span in the context, as described by shared.Tracer.
+// It implements every optional slap storage interface, such as slap.RecordReader;
+// operations storage lacks fail with shared.ErrStorageUnsupported.
+func InstrumentSLAPStorage(storage slap.StorageInterface, provider trace.TracerProvider) slap.StorageInterface {
+	return &slapStorage{next: storage, provider: provider}
+}
+
+// startSpan starts a client span for a storage operation.
+func startSpan(ctx context.Context, provider trace.TracerProvider, protocol, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, shared.AttrProtocol.String(protocol))
+	return shared.Tracer(ctx, provider).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// outpointAttrs returns the attributes identifying an outpoint.
+func outpointAttrs(txid string, outputIndex int) []attribute.KeyValue {
+	return []attribute.KeyValue{shared.AttrTxid.String(txid), shared.AttrOutputIndex.Int(outputIndex)}
+}
+
+// shipStorage instruments a ship.StorageInterface.
+type shipStorage struct {
+	next     ship.StorageInterface
+	provider trace.TracerProvider
+}
+
+// start starts a span for an operation on the SHIP storage.
+func (s *shipStorage) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return startSpan(ctx, s.provider, "SHIP", "SHIPStorage."+name, attrs...)
+}
+
+func (s *shipStorage) StoreSHIPRecord(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string) error {
+	ctx, span := s.start(ctx, "StoreSHIPRecord", append(outpointAttrs(txid, outputIndex), shared.AttrTopic.String(topic))...)
+	err := s.next.StoreSHIPRecord(ctx, txid, outputIndex, identityKey, domain, topic)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *shipStorage) StoreSHIPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial) error {
+	next, ok := s.next.(ship.TokenRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSHIPRecordWithToken")
+	}
+	ctx, span := s.start(ctx, "StoreSHIPRecordWithToken", append(outpointAttrs(txid, outputIndex), shared.AttrTopic.String(topic))...)
+	err := next.StoreSHIPRecordWithToken(ctx, txid, outputIndex, identityKey, domain, topic, token)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *shipStorage) StoreSHIPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, topic string, token *types.TokenMaterial, createdAt time.Time) error {
+	next, ok := s.next.(ship.BackdatedRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSHIPRecordAt")
+	}
+	ctx, span := s.start(ctx, "StoreSHIPRecordAt", append(outpointAttrs(txid, outputIndex), shared.AttrTopic.String(topic))...)
+	err := next.StoreSHIPRecordAt(ctx, txid, outputIndex, identityKey, domain, topic, token, createdAt)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *shipStorage) DeleteSHIPRecord(ctx context.Context, txid string, outputIndex int) error {
+	ctx, span := s.start(ctx, "DeleteSHIPRecord", outpointAttrs(txid, outputIndex)...)
+	err := s.next.DeleteSHIPRecord(ctx, txid, outputIndex)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *shipStorage) FindRecord(ctx context.Context, query types.SHIPQuery) ([]types.UTXOReference, error) {
+	ctx, span := s.start(ctx, "FindRecord", attribute.StringSlice("discovery.topics", query.Topics))
+	refs, err := s.next.FindRecord(ctx, query)
+	span.SetAttributes(shared.AttrResultCount.Int(len(refs)))
+	shared.EndSpan(span, err)
+	return refs, err
+}
+
+func (s *shipStorage) QueryRecords(ctx context.Context, query types.SHIPQuery) ([]types.SHIPRecord, error) {
+	next, ok := s.next.(ship.RecordQuerier)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("QueryRecords")
+	}
+	ctx, span := s.start(ctx, "QueryRecords", attribute.StringSlice("discovery.topics", query.Topics))
+	records, err := next.QueryRecords(ctx, query)
+	span.SetAttributes(shared.AttrResultCount.Int(len(records)))
+	shared.EndSpan(span, err)
+	return records, err
+}
+
+func (s *shipStorage) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
+	ctx, span := s.start(ctx, "FindAll")
+	refs, err := s.next.FindAll(ctx, limit, skip, sortOrder)
+	span.SetAttributes(shared.AttrResultCount.Int(len(refs)))
+	shared.EndSpan(span, err)
+	return refs, err
+}
+
+func (s *shipStorage) FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SHIPRecord, error) {
+	next, ok := s.next.(ship.RecordReader)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("FindByOutpoint")
+	}
+	ctx, span := s.start(ctx, "FindByOutpoint", outpointAttrs(txid, outputIndex)...)
+	record, err := next.FindByOutpoint(ctx, txid, outputIndex)
+	span.SetAttributes(attribute.Bool("discovery.found", record != nil))
+	shared.EndSpan(span, err)
+	return record, err
+}
+
+func (s *shipStorage) ListRecords(ctx context.Context, limit, skip *int) ([]types.SHIPRecord, error) {
+	next, ok := s.next.(ship.RecordLister)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("ListRecords")
+	}
+	ctx, span := s.start(ctx, "ListRecords")
+	records, err := next.ListRecords(ctx, limit, skip)
+	span.SetAttributes(shared.AttrResultCount.Int(len(records)))
+	shared.EndSpan(span, err)
+	return records, err
+}
+
+func (s *shipStorage) ForEachRecord(ctx context.Context, fn func(types.SHIPRecord) error) error {
+	next, ok := s.next.(ship.RecordIterator)
+	if !ok {
+		return shared.UnsupportedStorageError("ForEachRecord")
+	}
+	ctx, span := s.start(ctx, "ForEachRecord")
+	count := 0
+	err := next.ForEachRecord(ctx, func(record types.SHIPRecord) error {
+		count++
+		return fn(record)
+	})
+	span.SetAttributes(shared.AttrResultCount.Int(count))
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *shipStorage) ImportRecords(ctx context.Context, records []types.SHIPRecord) error {
+	next, ok := s.next.(ship.RecordImporter)
+	if !ok {
+		return shared.UnsupportedStorageError("ImportRecords")
+	}
+	ctx, span := s.start(ctx, "ImportRecords", shared.AttrResultCount.Int(len(records)))
+	err := next.ImportRecords(ctx, records)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *shipStorage) CountRecordsByTopic(ctx context.Context) (map[string]int, error) {
+	next, ok := s.next.(ship.RecordCounter)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("CountRecordsByTopic")
+	}
+	ctx, span := s.start(ctx, "CountRecordsByTopic")
+	counts, err := next.CountRecordsByTopic(ctx)
+	span.SetAttributes(shared.AttrResultCount.Int(len(counts)))
+	shared.EndSpan(span, err)
+	return counts, err
+}
+
+func (s *shipStorage) EnsureIndexes(ctx context.Context) error {
+	ctx, span := s.start(ctx, "EnsureIndexes")
+	err := s.next.EnsureIndexes(ctx)
+	shared.EndSpan(span, err)
+	return err
+}
+
+// slapStorage instruments a slap.StorageInterface.
+type slapStorage struct {
+	next     slap.StorageInterface
+	provider trace.TracerProvider
+}
+
+// start starts a span for an operation on the SLAP storage.
+func (s *slapStorage) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return startSpan(ctx, s.provider, "SLAP", "SLAPStorage."+name, attrs...)
+}
+
+func (s *slapStorage) StoreSLAPRecord(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string) error {
+	ctx, span := s.start(ctx, "StoreSLAPRecord", append(outpointAttrs(txid, outputIndex), shared.AttrService.String(service))...)
+	err := s.next.StoreSLAPRecord(ctx, txid, outputIndex, identityKey, domain, service)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *slapStorage) StoreSLAPRecordWithToken(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial) error {
+	next, ok := s.next.(slap.TokenRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSLAPRecordWithToken")
+	}
+	ctx, span := s.start(ctx, "StoreSLAPRecordWithToken", append(outpointAttrs(txid, outputIndex), shared.AttrService.String(service))...)
+	err := next.StoreSLAPRecordWithToken(ctx, txid, outputIndex, identityKey, domain, service, token)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *slapStorage) StoreSLAPRecordAt(ctx context.Context, txid string, outputIndex int, identityKey, domain, service string, token *types.TokenMaterial, createdAt time.Time) error {
+	next, ok := s.next.(slap.BackdatedRecordStore)
+	if !ok {
+		return shared.UnsupportedStorageError("StoreSLAPRecordAt")
+	}
+	ctx, span := s.start(ctx, "StoreSLAPRecordAt", append(outpointAttrs(txid, outputIndex), shared.AttrService.String(service))...)
+	err := next.StoreSLAPRecordAt(ctx, txid, outputIndex, identityKey, domain, service, token, createdAt)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *slapStorage) DeleteSLAPRecord(ctx context.Context, txid string, outputIndex int) error {
+	ctx, span := s.start(ctx, "DeleteSLAPRecord", outpointAttrs(txid, outputIndex)...)
+	err := s.next.DeleteSLAPRecord(ctx, txid, outputIndex)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *slapStorage) FindRecord(ctx context.Context, query types.SLAPQuery) ([]types.UTXOReference, error) {
+	var attrs []attribute.KeyValue
+	if query.Service != nil {
+		attrs = append(attrs, shared.AttrService.String(*query.Service))
+	}
+	ctx, span := s.start(ctx, "FindRecord", attrs...)
+	refs, err := s.next.FindRecord(ctx, query)
+	span.SetAttributes(shared.AttrResultCount.Int(len(refs)))
+	shared.EndSpan(span, err)
+	return refs, err
+}
+
+func (s *slapStorage) QueryRecords(ctx context.Context, query types.SLAPQuery) ([]types.SLAPRecord, error) {
+	next, ok := s.next.(slap.RecordQuerier)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("QueryRecords")
+	}
+	var attrs []attribute.KeyValue
+	if query.Service != nil {
+		attrs = append(attrs, shared.AttrService.String(*query.Service))
+	}
+	ctx, span := s.start(ctx, "QueryRecords", attrs...)
+	records, err := next.QueryRecords(ctx, query)
+	span.SetAttributes(shared.AttrResultCount.Int(len(records)))
+	shared.EndSpan(span, err)
+	return records, err
+}
+
+func (s *slapStorage) FindAll(ctx context.Context, limit, skip *int, sortOrder *types.SortOrder) ([]types.UTXOReference, error) {
+	ctx, span := s.start(ctx, "FindAll")
+	refs, err := s.next.FindAll(ctx, limit, skip, sortOrder)
+	span.SetAttributes(shared.AttrResultCount.Int(len(refs)))
+	shared.EndSpan(span, err)
+	return refs, err
+}
+
+func (s *slapStorage) FindByOutpoint(ctx context.Context, txid string, outputIndex int) (*types.SLAPRecord, error) {
+	next, ok := s.next.(slap.RecordReader)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("FindByOutpoint")
+	}
+	ctx, span := s.start(ctx, "FindByOutpoint", outpointAttrs(txid, outputIndex)...)
+	record, err := next.FindByOutpoint(ctx, txid, outputIndex)
+	span.SetAttributes(attribute.Bool("discovery.found", record != nil))
+	shared.EndSpan(span, err)
+	return record, err
+}
+
+func (s *slapStorage) ListRecords(ctx context.Context, limit, skip *int) ([]types.SLAPRecord, error) {
+	next, ok := s.next.(slap.RecordLister)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("ListRecords")
+	}
+	ctx, span := s.start(ctx, "ListRecords")
+	records, err := next.ListRecords(ctx, limit, skip)
+	span.SetAttributes(shared.AttrResultCount.Int(len(records)))
+	shared.EndSpan(span, err)
+	return records, err
+}
+
+func (s *slapStorage) ForEachRecord(ctx context.Context, fn func(types.SLAPRecord) error) error {
+	next, ok := s.next.(slap.RecordIterator)
+	if !ok {
+		return shared.UnsupportedStorageError("ForEachRecord")
+	}
+	ctx, span := s.start(ctx, "ForEachRecord")
+	count := 0
+	err := next.ForEachRecord(ctx, func(record types.SLAPRecord) error {
+		count++
+		return fn(record)
+	})
+	span.SetAttributes(shared.AttrResultCount.Int(count))
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *slapStorage) ImportRecords(ctx context.Context, records []types.SLAPRecord) error {
+	next, ok := s.next.(slap.RecordImporter)
+	if !ok {
+		return shared.UnsupportedStorageError("ImportRecords")
+	}
+	ctx, span := s.start(ctx, "ImportRecords", shared.AttrResultCount.Int(len(records)))
+	err := next.ImportRecords(ctx, records)
+	shared.EndSpan(span, err)
+	return err
+}
+
+func (s *slapStorage) CountRecordsByService(ctx context.Context) (map[string]int, error) {
+	next, ok := s.next.(slap.RecordCounter)
+	if !ok {
+		return nil, shared.UnsupportedStorageError("CountRecordsByService")
+	}
+	ctx, span := s.start(ctx, "CountRecordsByService")
+	counts, err := next.CountRecordsByService(ctx)
+	span.SetAttributes(shared.AttrResultCount.Int(len(counts)))
+	shared.EndSpan(span, err)
+	return counts, err
+}
+
+func (s *slapStorage) EnsureIndexes(ctx context.Context) error {
+	ctx, span := s.start(ctx, "EnsureIndexes")
+	err := s.next.EnsureIndexes(ctx)
+	shared.EndSpan(span, err)
+	return err
+}
@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bsv-blockchain/go-overlay-services/pkg/core/engine"
+	"github.com/bsv-blockchain/go-sdk/overlay/lookup"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/memstore"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/shared"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/ship"
+	"github.com/bsv-blockchain/go-overlay-discovery-services/pkg/slap"
+)
+
+const testTxid = "bdf1e48e845a65ba8c139c9b94844de30716f38d53787ba0a435e8705c4216d5"
+
+// newRecorder returns a tracer provider recording ended spans.
+func newRecorder(t *testing.T) (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider, recorder
+}
+
+// spansByName indexes the ended spans by name.
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	return spans
+}
+
+// attr returns the value of an attribute of the span.
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestLookup_NestsQueryAndStorageSpans(t *testing.T) {
+	provider, recorder := newRecorder(t)
+	ctx := context.Background()
+
+	storage := memstore.NewSHIPStorage()
+	require.NoError(t, storage.StoreSHIPRecord(ctx, testTxid, 0, "02abc", "https://a.example.com", "tm_meter"))
+	service := ship.NewLookupService(InstrumentSHIPStorage(storage, nil))
+	service.SetTracerProvider(provider)
+
+	_, err := service.Lookup(ctx, &lookup.LookupQuestion{Service: ship.Service, Query: json.RawMessage(`"findAll"`)})
+	require.NoError(t, err)
+
+	spans := spansByName(recorder)
+	require.Len(t, spans, 3)
+	root, execute, find := spans["Lookup"], spans["ExecuteLookup"], spans["SHIPStorage.FindAll"]
+	require.NotNil(t, root)
+	require.NotNil(t, execute)
+	require.NotNil(t, find)
+
+	assert.Equal(t, root.SpanContext().SpanID(), execute.Parent().SpanID())
+	assert.Equal(t, execute.SpanContext().SpanID(), find.Parent().SpanID())
+	assert.Equal(t, ship.Service, attr(root, shared.AttrService).AsString())
+	assert.Equal(t, string(shared.QueryTypeFindAll), attr(execute, shared.AttrQueryType).AsString())
+	assert.Equal(t, int64(1), attr(execute, shared.AttrResultCount).AsInt64())
+	assert.Equal(t, int64(1), attr(find, shared.AttrResultCount).AsInt64())
+}
+
+func TestOutputAdmittedByTopic_RecordsParseError(t *testing.T) {
+	provider, recorder := newRecorder(t)
+	service := slap.NewLookupService(InstrumentSLAPStorage(memstore.NewSLAPStorage(), nil))
+	service.SetTracerProvider(provider)
+
+	err := service.OutputAdmittedByTopic(context.Background(), &engine.OutputAdmittedByTopic{
+		Topic:      slap.Topic,
+		AtomicBEEF: []byte{0x01, 0x02},
+	})
+	require.ErrorIs(t, err, shared.ErrBEEFParseFailed)
+
+	spans := spansByName(recorder)
+	root, parse := spans["OutputAdmittedByTopic"], spans["ParsePushDropOutput"]
+	require.NotNil(t, root)
+	require.NotNil(t, parse)
+	assert.Equal(t, root.SpanContext().SpanID(), parse.Parent().SpanID())
+	assert.Equal(t, codes.Error, root.Status().Code)
+	assert.Equal(t, codes.Error, parse.Status().Code)
+	assert.Equal(t, slap.Topic, attr(root, shared.AttrTopic).AsString())
+	assert.Equal(t, "SLAP", attr(root, shared.AttrProtocol).AsString())
+}
+
+func TestIdentifyAdmissibleOutputs_RecordsSpan(t *testing.T) {
+	provider, recorder := newRecorder(t)
+	manager := ship.NewTopicManager(memstore.NewSHIPStorage(), nil)
+	manager.SetTracerProvider(provider)
+
+	lockingScript, err := script.NewFromHex("76a914000000000000000000000000000000000000000088ac")
+	require.NoError(t, err)
+	tx := transaction.NewTransaction()
+	tx.AddOutput(&transaction.TransactionOutput{Satoshis: 1, LockingScript: lockingScript})
+	beef, err := transaction.NewBeefFromTransaction(tx)
+	require.NoError(t, err)
+
+	_, err = manager.IdentifyAdmissibleOutputs(context.Background(), beef, tx.TxID(), nil)
+	require.NoError(t, err)
+
+	spans := spansByName(recorder)
+	admit, verify := spans["IdentifyAdmissibleOutputs"], spans["VerifySignatures"]
+	require.NotNil(t, admit)
+	require.NotNil(t, verify)
+	assert.Equal(t, admit.SpanContext().SpanID(), verify.Parent().SpanID())
+	assert.Equal(t, tx.TxID().String(), attr(admit, shared.AttrTxid).AsString())
+	assert.Equal(t, int64(0), attr(admit, shared.AttrResultCount).AsInt64())
+	assert.Equal(t, int64(1), attr(admit, shared.AttrRejectedCount).AsInt64())
+}
+
+func TestInstrumentStorage_UsesProvider(t *testing.T) {
+	provider, recorder := newRecorder(t)
+	ctx := context.Background()
+
+	storage := InstrumentSHIPStorage(memstore.NewSHIPStorage(), provider)
+	require.NoError(t, storage.StoreSHIPRecord(ctx, testTxid, 2, "02abc", "https://a.example.com", "tm_meter"))
+	record, err := storage.(ship.RecordReader).FindByOutpoint(ctx, testTxid, 2)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+
+	spans := spansByName(recorder)
+	store := spans["SHIPStorage.StoreSHIPRecord"]
+	require.NotNil(t, store)
+	assert.Equal(t, testTxid, attr(store, shared.AttrTxid).AsString())
+	assert.Equal(t, int64(2), attr(store, shared.AttrOutputIndex).AsInt64())
+	assert.Equal(t, "tm_meter", attr(store, shared.AttrTopic).AsString())
+	assert.True(t, attr(spans["SHIPStorage.FindByOutpoint"], "discovery.found").AsBool())
+}
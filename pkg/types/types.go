@@ -30,6 +30,8 @@ type SHIPRecord struct {
 	Topic string `json:"topic" bson:"topic"`
 	// CreatedAt is the timestamp when the record was created
 	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	// Token is the raw token material the record was parsed from (optional)
+	Token *TokenMaterial `json:"token,omitempty" bson:"token,omitempty"`
 }
 
 // SLAPRecord represents a SLAP (Service Lookup Availability Protocol) record.
@@ -47,6 +49,76 @@ type SLAPRecord struct {
 	Service string `json:"service" bson:"service"`
 	// CreatedAt is the timestamp when the record was created
 	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	// Token is the raw token material the record was parsed from (optional)
+	Token *TokenMaterial `json:"token,omitempty" bson:"token,omitempty"`
+}
+
+// TokenMaterial holds the raw PushDrop token a SHIP or SLAP record was parsed from,
+// so the record's signature linkage can be re-verified later.
+type TokenMaterial struct {
+	// Fields are the raw PushDrop fields: protocol, identity key, domain, topic or service, and signature
+	Fields [][]byte `json:"fields" bson:"fields"`
+	// LockingPublicKey is the hex-encoded public key that locks the token output
+	LockingPublicKey string `json:"lockingPublicKey" bson:"lockingPublicKey"`
+}
+
+// RecordEventType identifies the kind of change described by a RecordEvent.
+type RecordEventType string
+
+const (
+	// RecordEventAdmitted is emitted when a record is stored after its output was admitted
+	RecordEventAdmitted RecordEventType = "admitted"
+	// RecordEventRemoved is emitted when a record is deleted after its output was spent or evicted
+	RecordEventRemoved RecordEventType = "removed"
+)
+
+// RecordEvent describes a SHIP or SLAP record being admitted into or removed from discovery storage.
+// Removal events carry only the outpoint when the record was not found in storage.
+type RecordEvent struct {
+	// Type is the kind of change this event describes
+	Type RecordEventType `json:"type"`
+	// Protocol is the protocol identifier ("SHIP" or "SLAP")
+	Protocol string `json:"protocol"`
+	// Txid is the transaction ID of the advertisement output
+	Txid string `json:"txid"`
+	// OutputIndex is the index of the advertisement output within the transaction
+	OutputIndex int `json:"outputIndex"`
+	// IdentityKey is the public key that identifies the service provider
+	IdentityKey string `json:"identityKey,omitempty"`
+	// Domain is the domain where the service is hosted
+	Domain string `json:"domain,omitempty"`
+	// Topic is the advertised topic (SHIP only)
+	Topic string `json:"topic,omitempty"`
+	// Service is the advertised service (SLAP only)
+	Service string `json:"service,omitempty"`
+	// Timestamp is when the event occurred
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Event returns a RecordEvent of the given type describing this SHIP record.
+func (r SHIPRecord) Event(eventType RecordEventType) RecordEvent {
+	return RecordEvent{
+		Type:        eventType,
+		Protocol:    "SHIP",
+		Txid:        r.Txid,
+		OutputIndex: r.OutputIndex,
+		IdentityKey: r.IdentityKey,
+		Domain:      r.Domain,
+		Topic:       r.Topic,
+	}
+}
+
+// Event returns a RecordEvent of the given type describing this SLAP record.
+func (r SLAPRecord) Event(eventType RecordEventType) RecordEvent {
+	return RecordEvent{
+		Type:        eventType,
+		Protocol:    "SLAP",
+		Txid:        r.Txid,
+		OutputIndex: r.OutputIndex,
+		IdentityKey: r.IdentityKey,
+		Domain:      r.Domain,
+		Service:     r.Service,
+	}
 }
 
 // SortOrder represents the sort order for query results
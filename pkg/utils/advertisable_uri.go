@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Common error variables explaining why a URI is not advertisable.
+var (
+	ErrURIEmpty             = errors.New("URI is empty")
+	ErrURIUnsupportedScheme = errors.New("URI scheme is not advertisable")
+	ErrURIMalformed         = errors.New("URI is malformed")
+	ErrURILocalhost         = errors.New("URI host must not be localhost")
+	ErrURIPathNotAllowed    = errors.New("URI must not have a path")
+	ErrJS8CallMissingQuery  = errors.New("js8c URI requires a query string")
+	ErrJS8CallMissingParam  = errors.New("js8c URI is missing a required parameter")
+	ErrJS8CallInvalidParam  = errors.New("js8c URI parameter is invalid")
+)
+
+// SchemeFamily groups advertisable URI schemes by their base scheme.
+type SchemeFamily string
+
+// Scheme families of advertisable URIs.
+const (
+	// SchemeFamilyHTTPS covers https:// and its https+... variants
+	SchemeFamilyHTTPS SchemeFamily = "https"
+	// SchemeFamilyWSS covers wss:// real-time lookup streams
+	SchemeFamilyWSS SchemeFamily = "wss"
+	// SchemeFamilyJS8Call covers js8c+... HF radio advertisements
+	SchemeFamilyJS8Call SchemeFamily = "js8c"
+)
+
+// Transport is the medium an advertised host is reached over.
+type Transport string
+
+// Transports of advertisable URIs.
+const (
+	// TransportHTTPS is HTTP over TLS
+	TransportHTTPS Transport = "https"
+	// TransportWebSocket is a WebSocket over TLS
+	TransportWebSocket Transport = "websocket"
+	// TransportJS8Call is JS8 Call over HF radio
+	TransportJS8Call Transport = "js8call"
+)
+
+// AuthMode is the authentication an advertised host expects.
+type AuthMode string
+
+// Authentication modes of advertisable URIs.
+const (
+	// AuthNone means no mutual authentication
+	AuthNone AuthMode = "none"
+	// AuthBSV means BSV mutual authentication (BRC-103/104)
+	AuthBSV AuthMode = "bsvauth"
+	// AuthBSVScryptOffchain means BSV mutual authentication with sCrypt off-chain values
+	AuthBSVScryptOffchain AuthMode = "bsvauth+scrypt-offchain"
+)
+
+// JS8CallParams are the location and radio parameters of a js8c advertisement.
+type JS8CallParams struct {
+	// Latitude is the station latitude in degrees (-90 to 90)
+	Latitude float64 `json:"latitude"`
+	// Longitude is the station longitude in degrees (-180 to 180)
+	Longitude float64 `json:"longitude"`
+	// Frequency is the leading number of the freq parameter
+	Frequency float64 `json:"frequency"`
+	// FrequencyRaw is the freq parameter as advertised, including any units
+	FrequencyRaw string `json:"frequencyRaw"`
+	// Radius is the leading number of the radius parameter
+	Radius float64 `json:"radius"`
+	// RadiusRaw is the radius parameter as advertised, including any units
+	RadiusRaw string `json:"radiusRaw"`
+}
+
+// AdvertisableURI is the parsed form of a URI accepted by IsAdvertisableURI.
+type AdvertisableURI struct {
+	// Raw is the URI as advertised
+	Raw string `json:"raw"`
+	// Scheme is the full scheme, e.g. "https+bsvauth+smf"
+	Scheme string `json:"scheme"`
+	// Family is the base scheme family
+	Family SchemeFamily `json:"family"`
+	// Transport is the medium the host is reached over
+	Transport Transport `json:"transport"`
+	// Auth is the authentication the host expects
+	Auth AuthMode `json:"auth"`
+	// SMF reports whether the host collects payment (BSV Simple Monetization Framework)
+	SMF bool `json:"smf"`
+	// RTT reports whether the host deals in real-time (non-final) transactions
+	RTT bool `json:"rtt"`
+	// Host is the hostname, empty for js8c
+	Host string `json:"host,omitempty"`
+	// Port is the explicit port, or zero if none was given
+	Port int `json:"port,omitempty"`
+	// JS8Call holds the js8c parameters, nil for other families
+	JS8Call *JS8CallParams `json:"js8call,omitempty"`
+}
+
+// ParseAdvertisableURI parses a URI advertised in a SHIP or SLAP token, applying the
+// scheme-specific rules of the BRC-101 overlay advertisement spec. The returned error wraps
+// one of the ErrURI* or ErrJS8Call* errors explaining why the URI was rejected.
+// Schemes are resolved through DefaultURISchemeRegistry, so schemes registered or disabled
+// there are honored.
+func ParseAdvertisableURI(uri string) (*AdvertisableURI, error) {
+	return DefaultURISchemeRegistry().Parse(uri)
+}
+
+// parseHTTPSFamilyURI parses a URL by substituting its scheme with "https://", then
+// validates the hostname and path. It returns the host and explicit port.
+func parseHTTPSFamilyURI(uri, prefix string) (string, int, error) {
+	parsedURL, err := url.Parse(strings.Replace(uri, prefix, "https://", 1))
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrURIMalformed, err)
+	}
+
+	host, port, err := hostAndPort(parsedURL)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Path must be root path only
+	if !slices.Contains([]string{"/", ""}, parsedURL.Path) {
+		return "", 0, fmt.Errorf("%w: %q", ErrURIPathNotAllowed, parsedURL.Path)
+	}
+
+	return host, port, nil
+}
+
+// parseWSSURI parses a WebSocket Secure URI for real-time lookup streaming.
+func parseWSSURI(uri string) (string, int, error) {
+	parsedURL, err := url.Parse(uri)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrURIMalformed, err)
+	}
+	if parsedURL.Scheme != "wss" {
+		return "", 0, fmt.Errorf("%w: %q", ErrURIUnsupportedScheme, parsedURL.Scheme)
+	}
+
+	return hostAndPort(parsedURL)
+}
+
+// hostAndPort returns a parsed URL's host and explicit port, rejecting localhost.
+func hostAndPort(parsedURL *url.URL) (string, int, error) {
+	host := parsedURL.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return "", 0, ErrURILocalhost
+	}
+
+	port := 0
+	if portStr := parsedURL.Port(); portStr != "" {
+		var err error
+		if port, err = strconv.Atoi(portStr); err != nil {
+			return "", 0, fmt.Errorf("%w: invalid port %q", ErrURIMalformed, portStr)
+		}
+	}
+
+	return host, port, nil
+}
+
+// parseJS8CallParams parses the query string of a JS8 Call-based advertisement URI.
+// It requires the lat, long, freq and radius parameters, with latitude in -90 to 90,
+// longitude in -180 to 180, and positive frequency and radius values.
+func parseJS8CallParams(uri string) (*JS8CallParams, error) {
+	// Expect a query string with parameters
+	queryIndex := strings.Index(uri, "?")
+	if queryIndex == -1 {
+		return nil, ErrJS8CallMissingQuery
+	}
+
+	values, err := url.ParseQuery(uri[queryIndex+1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrURIMalformed, err)
+	}
+
+	for _, name := range []string{"lat", "long", "freq", "radius"} {
+		if values.Get(name) == "" {
+			return nil, fmt.Errorf("%w: %s", ErrJS8CallMissingParam, name)
+		}
+	}
+
+	params := &JS8CallParams{
+		FrequencyRaw: values.Get("freq"),
+		RadiusRaw:    values.Get("radius"),
+	}
+
+	// Validate latitude and longitude ranges
+	params.Latitude, err = strconv.ParseFloat(values.Get("lat"), 64)
+	if err != nil || params.Latitude < -90 || params.Latitude > 90 {
+		return nil, fmt.Errorf("%w: lat %q", ErrJS8CallInvalidParam, values.Get("lat"))
+	}
+	params.Longitude, err = strconv.ParseFloat(values.Get("long"), 64)
+	if err != nil || params.Longitude < -180 || params.Longitude > 180 {
+		return nil, fmt.Errorf("%w: long %q", ErrJS8CallInvalidParam, values.Get("long"))
+	}
+
+	if params.Frequency, err = leadingPositiveNumber("freq", params.FrequencyRaw); err != nil {
+		return nil, err
+	}
+	if params.Radius, err = leadingPositiveNumber("radius", params.RadiusRaw); err != nil {
+		return nil, err
+	}
+
+	// JS8 is more of a "demo" / "example". We include it to demonstrate that
+	// overlays can be advertised in many, many ways.
+	// If we were actually going to do this for real we would probably want to
+	// restrict the radius to a maximum value, establish and check for allowed units.
+	// Doing overlays over HF radio with js8c would be very interesting none the less.
+	// For now, we assume any positive numbers are acceptable.
+	return params, nil
+}
+
+// leadingPositiveNumber extracts the first number from a js8c parameter that may carry units,
+// such as "7.078MHz", and requires it to be positive.
+func leadingPositiveNumber(name, value string) (float64, error) {
+	// Check for negative sign first
+	if strings.HasPrefix(strings.TrimSpace(value), "-") {
+		return 0, fmt.Errorf("%w: %s %q", ErrJS8CallInvalidParam, name, value)
+	}
+	matches := numberRegex.FindStringSubmatch(value)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("%w: %s %q", ErrJS8CallInvalidParam, name, value)
+	}
+	number, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("%w: %s %q", ErrJS8CallInvalidParam, name, value)
+	}
+	return number, nil
+}
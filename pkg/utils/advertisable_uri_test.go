@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdvertisableURI(t *testing.T) {
+	tests := []struct {
+		name     string
+		uri      string
+		expected AdvertisableURI
+	}{
+		{
+			name: "https",
+			uri:  "https://example.com/",
+			expected: AdvertisableURI{
+				Scheme: "https", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthNone, Host: "example.com",
+			},
+		},
+		{
+			name: "https+bsvauth with port",
+			uri:  "https+bsvauth://example.com:8443",
+			expected: AdvertisableURI{
+				Scheme: "https+bsvauth", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthBSV, Host: "example.com", Port: 8443,
+			},
+		},
+		{
+			name: "https+bsvauth+smf",
+			uri:  "https+bsvauth+smf://overlay.example.com/",
+			expected: AdvertisableURI{
+				Scheme: "https+bsvauth+smf", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthBSV, SMF: true, Host: "overlay.example.com",
+			},
+		},
+		{
+			name: "https+bsvauth+scrypt-offchain",
+			uri:  "https+bsvauth+scrypt-offchain://example.com/",
+			expected: AdvertisableURI{
+				Scheme: "https+bsvauth+scrypt-offchain", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthBSVScryptOffchain, Host: "example.com",
+			},
+		},
+		{
+			name: "https+rtt",
+			uri:  "https+rtt://example.com/",
+			expected: AdvertisableURI{
+				Scheme: "https+rtt", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthNone, RTT: true, Host: "example.com",
+			},
+		},
+		{
+			name: "wss",
+			uri:  "wss://stream.example.com:9000/lookup",
+			expected: AdvertisableURI{
+				Scheme: "wss", Family: SchemeFamilyWSS, Transport: TransportWebSocket, Auth: AuthNone, Host: "stream.example.com", Port: 9000,
+			},
+		},
+		{
+			name: "js8c",
+			uri:  "js8c+bsvauth+smf:?lat=40.7128&long=-74.0060&freq=7.078MHz&radius=100km",
+			expected: AdvertisableURI{
+				Scheme: "js8c+bsvauth+smf", Family: SchemeFamilyJS8Call, Transport: TransportJS8Call, Auth: AuthBSV, SMF: true,
+				JS8Call: &JS8CallParams{
+					Latitude: 40.7128, Longitude: -74.0060, Frequency: 7.078, FrequencyRaw: "7.078MHz", Radius: 100, RadiusRaw: "100km",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseAdvertisableURI(tt.uri)
+			require.NoError(t, err)
+
+			tt.expected.Raw = tt.uri
+			assert.Equal(t, &tt.expected, parsed)
+			assert.True(t, IsAdvertisableURI(tt.uri))
+		})
+	}
+}
+
+func TestParseAdvertisableURI_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		uri      string
+		expected error
+	}{
+		{"empty", "", ErrURIEmpty},
+		{"whitespace", "  ", ErrURIEmpty},
+		{"http", "http://example.com", ErrURIUnsupportedScheme},
+		{"ws", "ws://example.com", ErrURIUnsupportedScheme},
+		{"malformed https", "https://[invalid", ErrURIMalformed},
+		{"https localhost", "https+bsvauth://LocalHost/", ErrURILocalhost},
+		{"wss localhost", "wss://localhost", ErrURILocalhost},
+		{"https path", "https://example.com/path", ErrURIPathNotAllowed},
+		{"js8c no query", "js8c+bsvauth+smf:", ErrJS8CallMissingQuery},
+		{"js8c missing radius", "js8c+bsvauth+smf:?lat=1&long=2&freq=3", ErrJS8CallMissingParam},
+		{"js8c bad lat", "js8c+bsvauth+smf:?lat=91&long=2&freq=3&radius=4", ErrJS8CallInvalidParam},
+		{"js8c negative freq", "js8c+bsvauth+smf:?lat=1&long=2&freq=-3&radius=4", ErrJS8CallInvalidParam},
+		{"js8c zero radius", "js8c+bsvauth+smf:?lat=1&long=2&freq=3&radius=0", ErrJS8CallInvalidParam},
+		{"js8c malformed query", "js8c+bsvauth+smf:?lat=1&long=2&freq=3&radius=4&x=%", ErrURIMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseAdvertisableURI(tt.uri)
+			require.ErrorIs(t, err, tt.expected)
+			assert.Nil(t, parsed)
+			assert.False(t, IsAdvertisableURI(tt.uri))
+		})
+	}
+}
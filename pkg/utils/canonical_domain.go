@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// defaultTLSPort is the implied port of the https family and wss schemes.
+const defaultTLSPort = "443"
+
+// CanonicalDomain returns the canonical form of an advertised domain, so that equivalent
+// spellings such as "https://Example.com/", "https://example.com:443" and "https://example.com"
+// are stored and queried as one value. For URIs with a host it lowercases the scheme, maps the
+// host to lowercase ASCII (converting internationalized names to punycode via IDNA), drops trailing
+// dots from the host, the default port 443 of the https family and wss, and a path of
+// only "/". Values without a valid host, such as js8c URIs or plain text, are returned with
+// only surrounding whitespace removed. The result is stable: canonicalizing it again changes nothing.
+func CanonicalDomain(domain string) string {
+	domain = strings.TrimSpace(domain)
+
+	parsed, err := url.Parse(domain)
+	if err != nil || parsed.Scheme == "" || parsed.Opaque != "" || parsed.Host == "" {
+		return domain
+	}
+	host, ok := canonicalHost(parsed.Hostname())
+	if !ok {
+		return domain
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	port := parsed.Port()
+	if port == defaultTLSPort && usesTLSPort(parsed.Scheme) {
+		port = ""
+	}
+
+	switch {
+	case port != "":
+		parsed.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		parsed.Host = "[" + host + "]"
+	default:
+		parsed.Host = host
+	}
+
+	if parsed.Path == "/" {
+		parsed.Path = ""
+		parsed.RawPath = ""
+	}
+
+	return parsed.String()
+}
+
+// canonicalHost returns the lowercase ASCII (punycode) form of a hostname, or the lowercase
+// form of an IPv6 literal. It reports false for hosts that are neither.
+func canonicalHost(host string) (string, bool) {
+	if strings.Contains(host, ":") {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return "", false
+		}
+		return strings.ToLower(host), true
+	}
+
+	// Trailing dots are trimmed again after mapping, which can turn characters such as U+3002 into dots
+	ascii, err := idna.Lookup.ToASCII(strings.TrimRight(host, "."))
+	ascii = strings.TrimRight(ascii, ".")
+	if err != nil || ascii == "" {
+		return "", false
+	}
+	return ascii, true
+}
+
+// usesTLSPort reports whether port 443 is the default for the scheme.
+func usesTLSPort(scheme string) bool {
+	return scheme == "https" || scheme == "wss" || strings.HasPrefix(scheme, "https+")
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalDomain(t *testing.T) {
+	tests := []struct {
+		domain   string
+		expected string
+	}{
+		{"https://example.com", "https://example.com"},
+		{"https://Example.COM/", "https://example.com"},
+		{"HTTPS://example.com:443/", "https://example.com"},
+		{"https://example.com.", "https://example.com"},
+		{"https://example.com..", "https://example.com"},
+		{"a://0..", "a://0"},
+		{"https://example.com。", "https://example.com"},
+		{"  https://example.com  ", "https://example.com"},
+		{"https://example.com:8443/", "https://example.com:8443"},
+		{"https+bsvauth+smf://Overlay.Example.com:443", "https+bsvauth+smf://overlay.example.com"},
+		{"wss://Stream.example.com:443/", "wss://stream.example.com"},
+		{"wss://stream.example.com:443/lookup", "wss://stream.example.com/lookup"},
+		{"https://bücher.example/", "https://xn--bcher-kva.example"},
+		{"https://XN--BCHER-KVA.example", "https://xn--bcher-kva.example"},
+		{"https://ＥＸＡＭＰＬＥ.com", "https://example.com"},
+		{"https://[2001:DB8::1]:443/", "https://[2001:db8::1]"},
+		{"https://[2001:db8::1]:8080", "https://[2001:db8::1]:8080"},
+		{"http://example.com:443/", "http://example.com:443"},
+		{"js8c+bsvauth+smf:?lat=40&long=-74&freq=7.078MHz&radius=100km", "js8c+bsvauth+smf:?lat=40&long=-74&freq=7.078MHz&radius=100km"},
+		{"example.com", "example.com"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			canonical := CanonicalDomain(tt.domain)
+			assert.Equal(t, tt.expected, canonical)
+			assert.Equal(t, canonical, CanonicalDomain(canonical), "canonical form must be stable")
+		})
+	}
+}
+
+func FuzzCanonicalDomain(f *testing.F) {
+	f.Add("https://Example.com:443/")
+	f.Add("wss://[::1]:443")
+	f.Add("https://bücher.example/")
+	f.Add("js8c+bsvauth+smf:?lat=1")
+
+	f.Fuzz(func(t *testing.T, domain string) {
+		canonical := CanonicalDomain(domain)
+		if again := CanonicalDomain(canonical); again != canonical {
+			t.Errorf("CanonicalDomain(%q) = %q, but CanonicalDomain(%q) = %q", domain, canonical, canonical, again)
+		}
+	})
+}
@@ -4,10 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
-	"fmt"
 
-	"github.com/bsv-blockchain/go-sdk/overlay"
-	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
 	"github.com/bsv-blockchain/go-sdk/wallet"
 )
 
@@ -15,7 +12,6 @@ import (
 var (
 	errInsufficientTokenFields = errors.New("insufficient fields in token (need at least protocol, identity key, and signature)")
 	errUnknownProtocol         = errors.New("unknown protocol")
-	errMissingIdentityKeyField = errors.New("missing identity key field")
 )
 
 // TokenFields represents the fields of a PushDrop token for SHIP or SLAP advertisement
@@ -41,76 +37,18 @@ func IsTokenSignatureCorrectlyLinked(ctx context.Context, lockingPublicKey strin
 		return false, errInsufficientTokenFields
 	}
 
-	// Make a copy to avoid mutating the original
-	fieldsCopy := make(TokenFields, len(fields))
-	copy(fieldsCopy, fields)
-
-	// The signature is the last field, which needs to be removed for verification
-	signature := fieldsCopy[len(fieldsCopy)-1]
-	dataFields := fieldsCopy[:len(fieldsCopy)-1]
-
-	// The protocol is in the first field
-	protocolBytes := dataFields[0]
-	protocolString := string(protocolBytes)
-
-	protocolID := string(overlay.Protocol(protocolString).ID())
-	if protocolID == "" {
-		return false, fmt.Errorf("%w: %s", errUnknownProtocol, protocolString)
-	}
-
-	// The identity key is in the second field
-	if len(dataFields) < 2 {
-		return false, errMissingIdentityKeyField
-	}
-	identityKeyBytes := dataFields[1]
-	identityKey, err := ec.PublicKeyFromBytes(identityKeyBytes)
-	if err != nil {
-		return false, fmt.Errorf("invalid identity key: %w", err)
-	}
-
-	// First, we ensure that the signature over the data is valid for the claimed identity key
-	data := flattenFields(dataFields)
-
-	// Convert signature bytes to ec.Signature
-	sig, err := ec.FromDER(signature)
-	if err != nil {
-		return false, fmt.Errorf("failed to parse signature: %w", err)
-	}
-
-	encryptionArgs := wallet.EncryptionArgs{
-		Counterparty: wallet.Counterparty{
-			Type:         wallet.CounterpartyTypeOther,
-			Counterparty: identityKey,
-		},
-		ProtocolID: wallet.Protocol{
-			SecurityLevel: wallet.SecurityLevelEveryAppAndCounterparty,
-			Protocol:      protocolID,
-		},
-		KeyID: "1",
-	}
-
-	verifyReq := wallet.VerifySignatureArgs{
-		EncryptionArgs: encryptionArgs,
-		Data:           data,
-		Signature:      sig,
-	}
-	anyoneWallet, _ := wallet.NewWallet(nil)
-	verifyResult, err := anyoneWallet.VerifySignature(ctx, verifyReq, "")
-	if err != nil {
-		return false, fmt.Errorf("signature verification failed: %w", err)
-	}
-	if !verifyResult.Valid {
-		return false, nil // Invalid signature, but not a technical error
-	}
-
-	// Then, we ensure that the locking public key matches the correct derived child
-	pubKeyReq := wallet.GetPublicKeyArgs{EncryptionArgs: encryptionArgs}
-	pubKeyResult, err := anyoneWallet.GetPublicKey(ctx, pubKeyReq, "")
+	// The protocol ID is resolved from the first field, so this only accepts SHIP and SLAP tokens.
+	// Use a TokenVerifier to check tokens of other protocols.
+	verifier, err := NewTokenVerifier(TokenVerifierConfig{
+		SecurityLevel:    wallet.SecurityLevelEveryAppAndCounterparty,
+		KeyID:            "1",
+		IdentityKeyField: 1,
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to get expected public key: %w", err)
+		return false, err
 	}
 
-	return pubKeyResult.PublicKey.ToDERHex() == lockingPublicKey, nil
+	return verifier.Verify(ctx, lockingPublicKey, fields)
 }
 
 // flattenFields concatenates all field bytes into a single byte slice for signature verification
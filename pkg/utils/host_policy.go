@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Common error variables for strict host validation.
+var (
+	ErrURIReservedAddress = errors.New("URI host is a private or reserved IP address")
+	ErrURISuspiciousHost  = errors.New("URI host is not a public hostname")
+)
+
+// reservedPrefixes are the IP ranges a public overlay host can never be reached at.
+var reservedPrefixes = []netip.Prefix{ //nolint:gochecknoglobals // constant table of reserved ranges
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network
+	netip.MustParsePrefix("10.0.0.0/8"),      // RFC1918 private
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // Loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // Link-local
+	netip.MustParsePrefix("172.16.0.0/12"),   // RFC1918 private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1 documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // RFC1918 private
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2 documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3 documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // Multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, including broadcast
+	netip.MustParsePrefix("::/128"),          // Unspecified
+	netip.MustParsePrefix("::1/128"),         // Loopback
+	netip.MustParsePrefix("::/96"),           // Deprecated IPv4-compatible
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which can embed any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // Discard-only
+	netip.MustParsePrefix("2001::/23"),       // IETF protocol assignments, including Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds an IPv4 address
+	netip.MustParsePrefix("fc00::/7"),        // Unique local
+	netip.MustParsePrefix("fe80::/10"),       // Link-local
+	netip.MustParsePrefix("fec0::/10"),       // Deprecated site-local
+	netip.MustParsePrefix("ff00::/8"),        // Multicast
+}
+
+// internalSuffixes are hostname suffixes that only resolve on private networks.
+var internalSuffixes = []string{ //nolint:gochecknoglobals // constant table of internal suffixes
+	".localhost", ".local", ".localdomain", ".internal", ".intranet", ".lan", ".home", ".corp", ".home.arpa",
+	".in-addr.arpa", ".ip6.arpa", ".onion", ".invalid", ".test", ".example",
+}
+
+// CheckPublicHost returns an error unless host, as returned by url.URL.Hostname, names a host
+// on the public internet. It rejects IP literals in loopback, RFC1918, link-local, carrier-grade NAT,
+// multicast, documentation and other reserved ranges, wrapping ErrURIReservedAddress, and
+// rejects hostnames that only resolve privately or that disguise IP addresses, such as
+// single-label names, internal suffixes and numeric forms like "2130706433" or "0x7f.1",
+// wrapping ErrURISuspiciousHost. It does not resolve hostnames.
+func CheckPublicHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("%w: empty host", ErrURISuspiciousHost)
+	}
+	if strings.Contains(host, "%") {
+		return fmt.Errorf("%w: %q has an IPv6 zone", ErrURISuspiciousHost, host)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return checkPublicAddr(host, addr)
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(host, "."))
+	if name == "localhost" {
+		return fmt.Errorf("%w: %q", ErrURISuspiciousHost, host)
+	}
+	if !strings.Contains(name, ".") {
+		return fmt.Errorf("%w: %q is a single-label name", ErrURISuspiciousHost, host)
+	}
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return fmt.Errorf("%w: %q has the internal suffix %q", ErrURISuspiciousHost, host, suffix)
+		}
+	}
+
+	// A top-level label made only of digits or hex is an IPv4 address in a non-canonical form
+	// (e.g. "127.1" or "0x7f.0.0.1") that many resolvers still interpret
+	labels := strings.Split(name, ".")
+	if isNumericLabel(labels[len(labels)-1]) {
+		return fmt.Errorf("%w: %q looks like a numeric IP address", ErrURISuspiciousHost, host)
+	}
+
+	return nil
+}
+
+// checkPublicAddr rejects IP addresses in reserved ranges, unmapping IPv4-mapped IPv6 first.
+func checkPublicAddr(host string, addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: %s is in %s", ErrURIReservedAddress, host, prefix)
+		}
+	}
+	return nil
+}
+
+// isNumericLabel reports whether a hostname label is a decimal, octal or hexadecimal number.
+func isNumericLabel(label string) bool {
+	digits := label
+	if strings.HasPrefix(label, "0x") {
+		digits = label[2:]
+	}
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		isHex := (r >= 'a' && r <= 'f') && digits != label
+		if (r < '0' || r > '9') && !isHex {
+			return false
+		}
+	}
+	return true
+}
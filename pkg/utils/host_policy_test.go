@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPublicHost(t *testing.T) {
+	public := []string{
+		"example.com",
+		"overlay.example.com.",
+		"8.8.8.8",
+		"2606:4700:4700::1111",
+		"a1.b2.example.org",
+	}
+	for _, host := range public {
+		t.Run(host, func(t *testing.T) {
+			assert.NoError(t, CheckPublicHost(host))
+		})
+	}
+
+	reserved := []string{
+		"127.0.0.1",
+		"127.8.9.10",
+		"10.0.0.5",
+		"172.16.4.1",
+		"192.168.1.1",
+		"169.254.169.254",
+		"100.64.0.1",
+		"0.0.0.0",
+		"255.255.255.255",
+		"224.0.0.1",
+		"192.0.2.10",
+		"::",
+		"::1",
+		"::ffff:127.0.0.1",
+		"::ffff:10.0.0.1",
+		"fe80::1",
+		"fd00::1",
+		"64:ff9b::a00:1",
+		"2001:db8::1",
+	}
+	for _, host := range reserved {
+		t.Run(host, func(t *testing.T) {
+			require.ErrorIs(t, CheckPublicHost(host), ErrURIReservedAddress)
+		})
+	}
+
+	suspicious := []string{
+		"",
+		"localhost",
+		"LOCALHOST.",
+		"api.localhost",
+		"intranet",
+		"2130706433",
+		"printer.local",
+		"db.internal",
+		"router.home.arpa",
+		"127.1",
+		"0x7f.0.0.1",
+		"10.0.0.0x1",
+		"fe80::1%eth0",
+	}
+	for _, host := range suspicious {
+		t.Run("suspicious "+host, func(t *testing.T) {
+			require.ErrorIs(t, CheckPublicHost(host), ErrURISuspiciousHost)
+		})
+	}
+}
+
+func TestURISchemeRegistry_StrictHosts(t *testing.T) {
+	registry := NewURISchemeRegistry()
+
+	uris := []string{
+		"https://127.0.0.1",
+		"https+bsvauth://10.0.0.5/",
+		"https+bsvauth+smf://[::1]:8080",
+		"wss://169.254.169.254",
+		"https://[fe80::1%25eth0]/",
+		"https+rtt://printer.local",
+	}
+
+	// Without strict mode only the literal hostname localhost is rejected
+	for _, uri := range uris {
+		assert.True(t, registry.IsAdvertisable(uri), uri)
+	}
+
+	registry.SetStrictHosts(true)
+	for _, uri := range uris {
+		_, err := registry.Parse(uri)
+		assert.Error(t, err, uri)
+	}
+
+	_, err := registry.Parse("https://192.168.0.1")
+	require.ErrorIs(t, err, ErrURIReservedAddress)
+	_, err = registry.Parse("wss://intranet:443")
+	require.ErrorIs(t, err, ErrURISuspiciousHost)
+
+	// Public hosts and host-less schemes are unaffected
+	assert.True(t, registry.IsAdvertisable("https+bsvauth+smf://overlay.example.com"))
+	assert.True(t, registry.IsAdvertisable("wss://8.8.8.8:443"))
+	assert.True(t, registry.IsAdvertisable("js8c+bsvauth+smf:?lat=40.7128&long=-74.0060&freq=7.078MHz&radius=100km"))
+
+	// The default registry stays permissive
+	assert.True(t, IsAdvertisableURI("https://127.0.0.1"))
+}
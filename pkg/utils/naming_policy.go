@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Common error variables explaining why a topic or service name is rejected.
+var (
+	ErrNameEmpty        = errors.New("name is empty")
+	ErrNameTooLong      = errors.New("name is too long")
+	ErrNamePrefix       = errors.New("name does not have an allowed prefix")
+	ErrNameInvalidChars = errors.New("name must be groups of allowed characters separated by single underscores")
+	ErrNameReserved     = errors.New("name is reserved")
+	ErrNamingPolicy     = errors.New("naming policy is invalid")
+)
+
+// Character sets for NamingPolicyConfig.AllowedChars.
+const (
+	// LowercaseLetters are the characters allowed by the BRC-87 default rule
+	LowercaseLetters = "abcdefghijklmnopqrstuvwxyz"
+	// Digits may be added to LowercaseLetters to allow names such as "tm_brc100"
+	Digits = "0123456789"
+)
+
+// Defaults of the BRC-87 naming rule.
+const (
+	// DefaultNameMaxLength is the maximum length of a name, including its prefix
+	DefaultNameMaxLength = 50
+)
+
+// NamingPolicyConfig configures a NamingPolicy. Zero values take the BRC-87 defaults.
+type NamingPolicyConfig struct {
+	// Prefixes are the accepted name prefixes (default "tm_" and "ls_")
+	Prefixes []string
+	// AllowedChars are the characters allowed in each underscore-separated group after the
+	// prefix (default LowercaseLetters)
+	AllowedChars string
+	// MaxLength is the maximum length of a name, including its prefix (default DefaultNameMaxLength)
+	MaxLength int
+	// Reserved are names that are never valid, such as "tm_ship" or "ls_slap"
+	Reserved []string
+}
+
+// NamingPolicy decides which topic and service names are valid. A name is a prefix followed by
+// one or more groups of allowed characters separated by single underscores.
+// It is immutable and safe for concurrent use.
+type NamingPolicy struct {
+	prefixes  []string
+	pattern   *regexp.Regexp
+	maxLength int
+	reserved  []string
+}
+
+// defaultNamingPolicy is the BRC-87 rule used by IsValidTopicOrServiceName.
+var defaultNamingPolicy = mustNamingPolicy(NamingPolicyConfig{}) //nolint:gochecknoglobals // immutable default policy
+
+// DefaultNamingPolicy returns the BRC-87 naming rule: a "tm_" or "ls_" prefix followed by
+// lowercase letters in underscore-separated groups, at most 50 characters in total, with no
+// reserved names. It is used by IsValidTopicOrServiceName and any component not given a
+// policy of its own.
+func DefaultNamingPolicy() *NamingPolicy {
+	return defaultNamingPolicy
+}
+
+// NewNamingPolicy creates a naming policy from the configuration.
+func NewNamingPolicy(cfg NamingPolicyConfig) (*NamingPolicy, error) {
+	prefixes := cfg.Prefixes
+	if len(prefixes) == 0 {
+		prefixes = []string{"tm_", "ls_"}
+	}
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			return nil, fmt.Errorf("%w: empty prefix", ErrNamingPolicy)
+		}
+	}
+
+	allowed := cfg.AllowedChars
+	if allowed == "" {
+		allowed = LowercaseLetters
+	}
+	if strings.Contains(allowed, "_") {
+		return nil, fmt.Errorf("%w: underscore is the group separator and cannot be an allowed character", ErrNamingPolicy)
+	}
+
+	maxLength := cfg.MaxLength
+	if maxLength == 0 {
+		maxLength = DefaultNameMaxLength
+	}
+	if maxLength < 0 {
+		return nil, fmt.Errorf("%w: negative maximum length %d", ErrNamingPolicy, maxLength)
+	}
+
+	var class strings.Builder
+	for _, r := range allowed {
+		class.WriteString(regexp.QuoteMeta(string(r)))
+	}
+	group := "[" + strings.ReplaceAll(class.String(), "-", `\-`) + "]+"
+	pattern, err := regexp.Compile("^" + group + "(?:_" + group + ")*$")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNamingPolicy, err)
+	}
+
+	return &NamingPolicy{
+		prefixes:  slices.Clone(prefixes),
+		pattern:   pattern,
+		maxLength: maxLength,
+		reserved:  slices.Clone(cfg.Reserved),
+	}, nil
+}
+
+// mustNamingPolicy creates a naming policy, panicking if the configuration is invalid.
+func mustNamingPolicy(cfg NamingPolicyConfig) *NamingPolicy {
+	policy, err := NewNamingPolicy(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+// Validate returns nil if the name is valid, or an error wrapping one of the ErrName* errors
+// explaining why it is not.
+func (p *NamingPolicy) Validate(name string) error {
+	if name == "" {
+		return ErrNameEmpty
+	}
+	if len(name) > p.maxLength {
+		return fmt.Errorf("%w: %d characters, at most %d allowed", ErrNameTooLong, len(name), p.maxLength)
+	}
+	if slices.Contains(p.reserved, name) {
+		return fmt.Errorf("%w: %q", ErrNameReserved, name)
+	}
+
+	for _, prefix := range p.prefixes {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			if !p.pattern.MatchString(rest) {
+				return fmt.Errorf("%w: %q", ErrNameInvalidChars, name)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q must start with one of %q", ErrNamePrefix, name, p.prefixes)
+}
+
+// IsValid reports whether the name is valid under the policy.
+func (p *NamingPolicy) IsValid(name string) bool {
+	return p.Validate(name) == nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultNamingPolicy(t *testing.T) {
+	policy := DefaultNamingPolicy()
+
+	valid := []string{"tm_payments", "ls_identity_verification", "tm_chat_messages", "tm_" + strings.Repeat("a", 47)}
+	for _, name := range valid {
+		assert.NoError(t, policy.Validate(name), name)
+	}
+
+	tests := []struct {
+		name     string
+		expected error
+	}{
+		{"", ErrNameEmpty},
+		{"tm_" + strings.Repeat("a", 48), ErrNameTooLong},
+		{"payments", ErrNamePrefix},
+		{"TM_payments", ErrNamePrefix},
+		{"tm_", ErrNameInvalidChars},
+		{"tm__double", ErrNameInvalidChars},
+		{"tm_payments_", ErrNameInvalidChars},
+		{"tm_brc100", ErrNameInvalidChars},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, policy.Validate(tt.name), tt.expected)
+			assert.False(t, IsValidTopicOrServiceName(tt.name))
+		})
+	}
+}
+
+func TestNewNamingPolicy(t *testing.T) {
+	policy, err := NewNamingPolicy(NamingPolicyConfig{
+		Prefixes:     []string{"tm_"},
+		AllowedChars: LowercaseLetters + Digits + "-",
+		MaxLength:    12,
+		Reserved:     []string{"tm_ship"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, policy.IsValid("tm_brc100"))
+	assert.True(t, policy.IsValid("tm_brc-100_x"))
+	require.ErrorIs(t, policy.Validate("ls_brc100"), ErrNamePrefix)
+	require.ErrorIs(t, policy.Validate("tm_ship"), ErrNameReserved)
+	require.ErrorIs(t, policy.Validate("tm_brc100_abc"), ErrNameTooLong)
+	require.ErrorIs(t, policy.Validate("tm_brc.100"), ErrNameInvalidChars)
+	require.ErrorIs(t, policy.Validate("tm_Brc100"), ErrNameInvalidChars)
+
+	// Regular expression metacharacters are matched literally
+	policy, err = NewNamingPolicy(NamingPolicyConfig{AllowedChars: "a]^\\"})
+	require.NoError(t, err)
+	assert.True(t, policy.IsValid(`tm_a]^\`))
+	assert.False(t, policy.IsValid("tm_b"))
+}
+
+func TestNewNamingPolicy_Invalid(t *testing.T) {
+	_, err := NewNamingPolicy(NamingPolicyConfig{Prefixes: []string{"tm_", ""}})
+	require.ErrorIs(t, err, ErrNamingPolicy)
+
+	_, err = NewNamingPolicy(NamingPolicyConfig{AllowedChars: "a_b"})
+	require.ErrorIs(t, err, ErrNamingPolicy)
+
+	_, err = NewNamingPolicy(NamingPolicyConfig{MaxLength: -1})
+	require.ErrorIs(t, err, ErrNamingPolicy)
+}
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bsv-blockchain/go-sdk/overlay"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/wallet"
+)
+
+// Static error variables for err113 compliance
+var (
+	errVerifierKeyIDEmpty       = errors.New("token verifier key ID cannot be empty")
+	errVerifierIdentityField    = errors.New("token verifier identity key field cannot be negative")
+	errVerifierWalletNil        = errors.New("token verifier wallet could not be created")
+	errTokenMissingDataFields   = errors.New("token has too few fields for the configured identity key field")
+	errTokenMissingSignatureFld = errors.New("token has no signature field")
+)
+
+// SignatureWallet is the subset of a BRC-100 wallet needed to verify linked token signatures.
+type SignatureWallet interface {
+	VerifySignature(ctx context.Context, args wallet.VerifySignatureArgs, originator string) (*wallet.VerifySignatureResult, error)
+	GetPublicKey(ctx context.Context, args wallet.GetPublicKeyArgs, originator string) (*wallet.GetPublicKeyResult, error)
+}
+
+// TokenVerifierConfig describes how a BRC-48 token's signature and locking key are derived.
+type TokenVerifierConfig struct {
+	// ProtocolID is the BRC-43 protocol name used for key derivation. When empty, it is resolved
+	// from the token's first field with overlay.Protocol, which only succeeds for SHIP and SLAP.
+	ProtocolID string
+	// SecurityLevel is the BRC-43 security level used for key derivation
+	SecurityLevel wallet.SecurityLevel
+	// KeyID is the BRC-43 key ID used for key derivation
+	KeyID string
+	// Counterparty is the counterparty used on the verifying side. When nil, the identity key
+	// carried in the token is used, which matches tokens signed for "anyone".
+	Counterparty *wallet.Counterparty
+	// IdentityKeyField is the index of the field holding the signer's DER identity key
+	IdentityKeyField int
+	// Wallet verifies signatures and derives the expected locking key (optional).
+	// When nil, the "anyone" wallet is used.
+	Wallet SignatureWallet
+}
+
+// SHIPTokenVerifierConfig returns the verifier configuration for SHIP advertisements.
+func SHIPTokenVerifierConfig() TokenVerifierConfig {
+	return TokenVerifierConfig{
+		ProtocolID:       string(overlay.ProtocolIDSHIP),
+		SecurityLevel:    wallet.SecurityLevelEveryAppAndCounterparty,
+		KeyID:            "1",
+		IdentityKeyField: 1,
+	}
+}
+
+// SLAPTokenVerifierConfig returns the verifier configuration for SLAP advertisements.
+func SLAPTokenVerifierConfig() TokenVerifierConfig {
+	return TokenVerifierConfig{
+		ProtocolID:       string(overlay.ProtocolIDSLAP),
+		SecurityLevel:    wallet.SecurityLevelEveryAppAndCounterparty,
+		KeyID:            "1",
+		IdentityKeyField: 1,
+	}
+}
+
+// TokenVerifier checks that a PushDrop token's signature and locking key are linked to the
+// identity key it claims, using a configurable BRC-43 derivation. The last field of the token
+// is the signature over the concatenation of all other fields.
+// It is safe for concurrent use if its wallet is.
+type TokenVerifier struct {
+	cfg TokenVerifierConfig
+}
+
+// NewTokenVerifier creates a token verifier from the configuration.
+func NewTokenVerifier(cfg TokenVerifierConfig) (*TokenVerifier, error) {
+	if cfg.KeyID == "" {
+		return nil, errVerifierKeyIDEmpty
+	}
+	if cfg.IdentityKeyField < 0 {
+		return nil, errVerifierIdentityField
+	}
+	if cfg.Wallet == nil {
+		anyoneWallet, err := wallet.NewWallet(nil)
+		if err != nil || anyoneWallet == nil {
+			return nil, fmt.Errorf("%w: %w", errVerifierWalletNil, err)
+		}
+		cfg.Wallet = anyoneWallet
+	}
+	return &TokenVerifier{cfg: cfg}, nil
+}
+
+// Verify reports whether the token's signature is valid for its claimed identity key and
+// the locking public key (hex) is the key derived for that identity. A false result with a
+// nil error means the token is well-formed but not correctly linked.
+func (v *TokenVerifier) Verify(ctx context.Context, lockingPublicKey string, fields TokenFields) (bool, error) {
+	if len(fields) == 0 {
+		return false, errTokenMissingSignatureFld
+	}
+
+	// The signature is the last field; everything before it is signed data
+	signature := fields[len(fields)-1]
+	dataFields := fields[:len(fields)-1]
+	if v.cfg.IdentityKeyField >= len(dataFields) {
+		return false, fmt.Errorf("%w: need field %d, token has %d data fields", errTokenMissingDataFields, v.cfg.IdentityKeyField, len(dataFields))
+	}
+
+	protocolID := v.cfg.ProtocolID
+	if protocolID == "" {
+		protocolString := string(dataFields[0])
+		protocolID = string(overlay.Protocol(protocolString).ID())
+		if protocolID == "" {
+			return false, fmt.Errorf("%w: %s", errUnknownProtocol, protocolString)
+		}
+	}
+
+	identityKey, err := ec.PublicKeyFromBytes(dataFields[v.cfg.IdentityKeyField])
+	if err != nil {
+		return false, fmt.Errorf("invalid identity key: %w", err)
+	}
+
+	sig, err := ec.FromDER(signature)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse signature: %w", err)
+	}
+
+	counterparty := wallet.Counterparty{Type: wallet.CounterpartyTypeOther, Counterparty: identityKey}
+	if v.cfg.Counterparty != nil {
+		counterparty = *v.cfg.Counterparty
+	}
+
+	encryptionArgs := wallet.EncryptionArgs{
+		Counterparty: counterparty,
+		ProtocolID: wallet.Protocol{
+			SecurityLevel: v.cfg.SecurityLevel,
+			Protocol:      protocolID,
+		},
+		KeyID: v.cfg.KeyID,
+	}
+
+	// First, we ensure that the signature over the data is valid for the claimed identity key
+	verifyResult, err := v.cfg.Wallet.VerifySignature(ctx, wallet.VerifySignatureArgs{
+		EncryptionArgs: encryptionArgs,
+		Data:           flattenFields(dataFields),
+		Signature:      sig,
+	}, "")
+	if err != nil {
+		return false, fmt.Errorf("signature verification failed: %w", err)
+	}
+	if !verifyResult.Valid {
+		return false, nil // Invalid signature, but not a technical error
+	}
+
+	// Then, we ensure that the locking public key matches the correct derived child
+	pubKeyResult, err := v.cfg.Wallet.GetPublicKey(ctx, wallet.GetPublicKeyArgs{EncryptionArgs: encryptionArgs}, "")
+	if err != nil {
+		return false, fmt.Errorf("failed to get expected public key: %w", err)
+	}
+
+	return pubKeyResult.PublicKey.ToDERHex() == lockingPublicKey, nil
+}
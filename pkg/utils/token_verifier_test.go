@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+// signCustomToken signs fields with the given BRC-43 derivation for "anyone", appends the
+// signature, and returns the fields with the locking public key hex.
+func signCustomToken(t *testing.T, ctx context.Context, signerWallet *wallet.Wallet, protocol wallet.Protocol, keyID string, fields TokenFields) (TokenFields, string) {
+	t.Helper()
+	encryptionArgs := wallet.EncryptionArgs{
+		ProtocolID:   protocol,
+		KeyID:        keyID,
+		Counterparty: wallet.Counterparty{Type: wallet.CounterpartyTypeAnyone},
+	}
+
+	sigResult, err := signerWallet.CreateSignature(ctx, wallet.CreateSignatureArgs{
+		EncryptionArgs: encryptionArgs,
+		Data:           flattenFields(fields),
+	}, "")
+	require.NoError(t, err)
+	sigDER, err := sigResult.Signature.ToDER()
+	require.NoError(t, err)
+
+	forSelf := true
+	pubKeyResult, err := signerWallet.GetPublicKey(ctx, wallet.GetPublicKeyArgs{
+		EncryptionArgs: encryptionArgs,
+		ForSelf:        &forSelf,
+	}, "")
+	require.NoError(t, err)
+
+	signed := make(TokenFields, 0, len(fields)+1)
+	signed = append(signed, fields...)
+	return append(signed, sigDER), pubKeyResult.PublicKey.ToDERHex()
+}
+
+func TestTokenVerifier_CustomProtocol(t *testing.T) {
+	ctx := context.Background()
+	signerWallet, identityDER := newTestWallet(t, ctx)
+
+	protocol := wallet.Protocol{SecurityLevel: wallet.SecurityLevelEveryApp, Protocol: "meter tokens"}
+	// Identity key first, followed by arbitrary protocol data
+	fields, pubKeyHex := signCustomToken(t, ctx, signerWallet, protocol, "meter-1", TokenFields{identityDER, []byte("reading"), []byte("42")})
+
+	verifier, err := NewTokenVerifier(TokenVerifierConfig{
+		ProtocolID:       "meter tokens",
+		SecurityLevel:    wallet.SecurityLevelEveryApp,
+		KeyID:            "meter-1",
+		IdentityKeyField: 0,
+	})
+	require.NoError(t, err)
+
+	valid, err := verifier.Verify(ctx, pubKeyHex, fields)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	t.Run("rejects a different key ID", func(t *testing.T) {
+		other, err := NewTokenVerifier(TokenVerifierConfig{
+			ProtocolID:    "meter tokens",
+			SecurityLevel: wallet.SecurityLevelEveryApp,
+			KeyID:         "meter-2",
+		})
+		require.NoError(t, err)
+
+		valid, err := other.Verify(ctx, pubKeyHex, fields)
+		require.NoError(t, err)
+		require.False(t, valid)
+	})
+
+	t.Run("rejects a different security level", func(t *testing.T) {
+		other, err := NewTokenVerifier(TokenVerifierConfig{
+			ProtocolID:    "meter tokens",
+			SecurityLevel: wallet.SecurityLevelEveryAppAndCounterparty,
+			KeyID:         "meter-1",
+		})
+		require.NoError(t, err)
+
+		valid, err := other.Verify(ctx, pubKeyHex, fields)
+		require.NoError(t, err)
+		require.False(t, valid)
+	})
+
+	t.Run("rejects a mismatched locking key", func(t *testing.T) {
+		_, otherKeyHex := signCustomToken(t, ctx, signerWallet, protocol, "meter-2", TokenFields{identityDER})
+
+		valid, err := verifier.Verify(ctx, otherKeyHex, fields)
+		require.NoError(t, err)
+		require.False(t, valid)
+	})
+}
+
+func TestTokenVerifier_Presets(t *testing.T) {
+	ctx := context.Background()
+	signerWallet, identityDER := newTestWallet(t, ctx)
+	fields, pubKeyHex := signedTokenFixture(t, ctx, signerWallet, "SHIP", "service host interconnect", identityDER)
+
+	shipVerifier, err := NewTokenVerifier(SHIPTokenVerifierConfig())
+	require.NoError(t, err)
+	valid, err := shipVerifier.Verify(ctx, pubKeyHex, fields)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	slapVerifier, err := NewTokenVerifier(SLAPTokenVerifierConfig())
+	require.NoError(t, err)
+	valid, err = slapVerifier.Verify(ctx, pubKeyHex, fields)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func TestTokenVerifier_InvalidInput(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewTokenVerifier(TokenVerifierConfig{ProtocolID: "x"})
+	require.ErrorIs(t, err, errVerifierKeyIDEmpty)
+
+	_, err = NewTokenVerifier(TokenVerifierConfig{KeyID: "1", IdentityKeyField: -1})
+	require.ErrorIs(t, err, errVerifierIdentityField)
+
+	verifier, err := NewTokenVerifier(TokenVerifierConfig{ProtocolID: "x", KeyID: "1", IdentityKeyField: 2})
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, "any", nil)
+	require.ErrorIs(t, err, errTokenMissingSignatureFld)
+
+	_, err = verifier.Verify(ctx, "any", TokenFields{[]byte("a"), []byte("b"), []byte("sig")})
+	require.ErrorIs(t, err, errTokenMissingDataFields)
+
+	_, err = verifier.Verify(ctx, "any", TokenFields{[]byte("a"), []byte("b"), []byte("not a key"), []byte("sig")})
+	require.ErrorContains(t, err, "invalid identity key")
+}
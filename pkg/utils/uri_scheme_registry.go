@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Common error variables for URI scheme registration.
+var (
+	ErrURISchemeInvalid = errors.New("URI scheme is invalid")
+	ErrURISchemeExists  = errors.New("URI scheme is already registered")
+	ErrURISchemeUnknown = errors.New("URI scheme is not registered")
+)
+
+// URISchemeValidator validates a URI that begins with a registered scheme's prefix. It may fill
+// in the family-specific fields of parsed, such as Host, Port or JS8Call, and returns an error
+// explaining why the URI is not advertisable.
+type URISchemeValidator func(uri string, parsed *AdvertisableURI) error
+
+// URIScheme describes an advertisable URI scheme.
+type URIScheme struct {
+	// Prefix is the text every URI of the scheme begins with, e.g. "https+bsvauth://" or "js8c+bsvauth+smf:"
+	Prefix string
+	// Family is the base scheme family
+	Family SchemeFamily
+	// Transport is the medium hosts of the scheme are reached over
+	Transport Transport
+	// Auth is the authentication hosts of the scheme expect
+	Auth AuthMode
+	// SMF reports whether hosts of the scheme collect payment
+	SMF bool
+	// RTT reports whether hosts of the scheme deal in real-time transactions
+	RTT bool
+	// Validate applies the scheme-specific rules to a URI
+	Validate URISchemeValidator
+}
+
+// Name returns the scheme name, which is the prefix without its trailing ":" or "://".
+func (s URIScheme) Name() string {
+	return strings.TrimRight(strings.TrimSuffix(s.Prefix, "//"), ":")
+}
+
+// BuiltinURISchemes returns the advertisable URI schemes defined by the BRC-101 spec.
+func BuiltinURISchemes() []URIScheme {
+	return []URIScheme{
+		{Prefix: "https://", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthNone, Validate: NewHTTPSFamilyValidator("https://")},
+		// Plain auth over HTTPS, but no payment can be collected
+		{Prefix: "https+bsvauth://", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthBSV, Validate: NewHTTPSFamilyValidator("https+bsvauth://")},
+		// Auth and payment over HTTPS
+		{Prefix: "https+bsvauth+smf://", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthBSV, SMF: true, Validate: NewHTTPSFamilyValidator("https+bsvauth+smf://")},
+		// A protocol allowing you to also supply sCrypt off-chain values to the topical admissibility checking context
+		{Prefix: "https+bsvauth+scrypt-offchain://", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthBSVScryptOffchain, Validate: NewHTTPSFamilyValidator("https+bsvauth+scrypt-offchain://")},
+		// A protocol allowing overlays that deal with real-time transactions (non-finals)
+		{Prefix: "https+rtt://", Family: SchemeFamilyHTTPS, Transport: TransportHTTPS, Auth: AuthNone, RTT: true, Validate: NewHTTPSFamilyValidator("https+rtt://")},
+		// WSS for real-time event-listening lookups
+		{Prefix: "wss://", Family: SchemeFamilyWSS, Transport: TransportWebSocket, Auth: AuthNone, Validate: validateWSSScheme},
+		// JS8 Call-based advertisement
+		{Prefix: "js8c+bsvauth+smf:", Family: SchemeFamilyJS8Call, Transport: TransportJS8Call, Auth: AuthBSV, SMF: true, Validate: validateJS8CallScheme},
+	}
+}
+
+// NewHTTPSFamilyValidator returns a validator applying the HTTPS rules to URIs beginning with
+// prefix: the URI must parse as a URL once prefix is replaced by "https://", must not name
+// localhost and must have no path beyond "/". It fills in Host and Port.
+func NewHTTPSFamilyValidator(prefix string) URISchemeValidator {
+	return func(uri string, parsed *AdvertisableURI) error {
+		var err error
+		parsed.Host, parsed.Port, err = parseHTTPSFamilyURI(uri, prefix)
+		return err
+	}
+}
+
+// validateWSSScheme validates a wss:// URI, filling in Host and Port.
+func validateWSSScheme(uri string, parsed *AdvertisableURI) error {
+	var err error
+	parsed.Host, parsed.Port, err = parseWSSURI(uri)
+	return err
+}
+
+// validateJS8CallScheme validates a js8c URI, filling in JS8Call.
+func validateJS8CallScheme(uri string, parsed *AdvertisableURI) error {
+	var err error
+	parsed.JS8Call, err = parseJS8CallParams(uri)
+	return err
+}
+
+// URISchemeRegistry holds the URI schemes accepted as advertisable. Applications may register
+// additional schemes and disable registered ones, including the built-in schemes.
+// It is safe for concurrent use.
+type URISchemeRegistry struct {
+	mutex    sync.RWMutex
+	schemes  []URIScheme
+	disabled map[string]bool
+	// strictHosts applies CheckPublicHost to every parsed host
+	strictHosts bool
+}
+
+// defaultURISchemes is the registry used by ParseAdvertisableURI and IsAdvertisableURI.
+var defaultURISchemes = NewURISchemeRegistry() //nolint:gochecknoglobals // process-wide registry applications register schemes on
+
+// DefaultURISchemeRegistry returns the process-wide registry used by ParseAdvertisableURI,
+// IsAdvertisableURI and any component not given a registry of its own. Schemes should be
+// registered on it before advertisements are created or admitted.
+func DefaultURISchemeRegistry() *URISchemeRegistry {
+	return defaultURISchemes
+}
+
+// NewURISchemeRegistry creates a registry holding the built-in BRC-101 schemes.
+func NewURISchemeRegistry() *URISchemeRegistry {
+	return &URISchemeRegistry{
+		schemes:  BuiltinURISchemes(),
+		disabled: make(map[string]bool),
+	}
+}
+
+// Register adds a scheme. The prefix must contain the ":" ending the scheme name and must not
+// already be registered, and the scheme must have a validator.
+func (r *URISchemeRegistry) Register(scheme URIScheme) error {
+	if scheme.Prefix == "" || !strings.Contains(scheme.Prefix, ":") || scheme.Name() == "" {
+		return fmt.Errorf("%w: prefix %q must be a scheme followed by \":\"", ErrURISchemeInvalid, scheme.Prefix)
+	}
+	if scheme.Validate == nil {
+		return fmt.Errorf("%w: %q has no validator", ErrURISchemeInvalid, scheme.Prefix)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.indexOf(scheme.Prefix) >= 0 {
+		return fmt.Errorf("%w: %q", ErrURISchemeExists, scheme.Prefix)
+	}
+	r.schemes = append(r.schemes, scheme)
+	return nil
+}
+
+// Disable stops URIs with the given prefix from being advertisable, without removing the scheme.
+func (r *URISchemeRegistry) Disable(prefix string) error {
+	return r.setDisabled(prefix, true)
+}
+
+// Enable re-enables a scheme previously disabled with Disable.
+func (r *URISchemeRegistry) Enable(prefix string) error {
+	return r.setDisabled(prefix, false)
+}
+
+// setDisabled marks a registered prefix as disabled or enabled.
+func (r *URISchemeRegistry) setDisabled(prefix string, disabled bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.indexOf(prefix) < 0 {
+		return fmt.Errorf("%w: %q", ErrURISchemeUnknown, prefix)
+	}
+	if disabled {
+		r.disabled[prefix] = true
+	} else {
+		delete(r.disabled, prefix)
+	}
+	return nil
+}
+
+// SetStrictHosts controls strict host validation, which is off by default. In strict mode the
+// host of every parsed URI must pass CheckPublicHost, so loopback, private, link-local and other
+// reserved IP literals and private-only hostnames are rejected as well as "localhost". Enable it
+// wherever clients will connect to advertised hosts. Only schemes whose validator fills in Host
+// are affected.
+func (r *URISchemeRegistry) SetStrictHosts(strict bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.strictHosts = strict
+}
+
+// Schemes returns the enabled schemes in registration order.
+func (r *URISchemeRegistry) Schemes() []URIScheme {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	schemes := make([]URIScheme, 0, len(r.schemes))
+	for _, scheme := range r.schemes {
+		if !r.disabled[scheme.Prefix] {
+			schemes = append(schemes, scheme)
+		}
+	}
+	return schemes
+}
+
+// Parse parses a URI using the enabled scheme with the longest matching prefix. The returned
+// error wraps ErrURIUnsupportedScheme when no enabled scheme matches, or the error returned by
+// the scheme's validator.
+func (r *URISchemeRegistry) Parse(uri string) (*AdvertisableURI, error) {
+	if strings.TrimSpace(uri) == "" {
+		return nil, ErrURIEmpty
+	}
+
+	scheme, strictHosts, ok := r.match(uri)
+	if !ok {
+		// If none of the enabled prefixes match, the URI is not advertisable
+		return nil, fmt.Errorf("%w: %q", ErrURIUnsupportedScheme, uri)
+	}
+
+	parsed := &AdvertisableURI{
+		Raw:       uri,
+		Scheme:    scheme.Name(),
+		Family:    scheme.Family,
+		Transport: scheme.Transport,
+		Auth:      scheme.Auth,
+		SMF:       scheme.SMF,
+		RTT:       scheme.RTT,
+	}
+	if err := scheme.Validate(uri, parsed); err != nil {
+		return nil, err
+	}
+	if strictHosts && parsed.Host != "" {
+		if err := CheckPublicHost(parsed.Host); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// IsAdvertisable reports whether Parse accepts the URI.
+func (r *URISchemeRegistry) IsAdvertisable(uri string) bool {
+	_, err := r.Parse(uri)
+	return err == nil
+}
+
+// match returns the enabled scheme with the longest prefix of uri, and whether strict host
+// validation is enabled.
+func (r *URISchemeRegistry) match(uri string) (URIScheme, bool, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var best URIScheme
+	found := false
+	for _, scheme := range r.schemes {
+		if r.disabled[scheme.Prefix] || !strings.HasPrefix(uri, scheme.Prefix) {
+			continue
+		}
+		if !found || len(scheme.Prefix) > len(best.Prefix) {
+			best, found = scheme, true
+		}
+	}
+	return best, r.strictHosts, found
+}
+
+// indexOf returns the position of the scheme with the given prefix, or -1. The caller must hold the mutex.
+func (r *URISchemeRegistry) indexOf(prefix string) int {
+	for i, scheme := range r.schemes {
+		if scheme.Prefix == prefix {
+			return i
+		}
+	}
+	return -1
+}
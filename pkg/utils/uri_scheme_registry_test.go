@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errOnionHostRequired = errors.New("host must be a .onion address")
+
+// onionScheme is a custom scheme accepting only Tor hidden service hosts.
+func onionScheme() URIScheme {
+	return URIScheme{
+		Prefix:    "https+onion://",
+		Family:    SchemeFamilyHTTPS,
+		Transport: Transport("tor"),
+		Auth:      AuthNone,
+		Validate: func(uri string, parsed *AdvertisableURI) error {
+			u, err := url.Parse(uri)
+			if err != nil {
+				return err
+			}
+			if !strings.HasSuffix(u.Hostname(), ".onion") {
+				return errOnionHostRequired
+			}
+			parsed.Host = u.Hostname()
+			return nil
+		},
+	}
+}
+
+func TestURISchemeRegistry_Register(t *testing.T) {
+	registry := NewURISchemeRegistry()
+	require.Len(t, registry.Schemes(), len(BuiltinURISchemes()))
+
+	_, err := registry.Parse("https+onion://abc.onion")
+	require.ErrorIs(t, err, ErrURIUnsupportedScheme)
+
+	require.NoError(t, registry.Register(onionScheme()))
+
+	parsed, err := registry.Parse("https+onion://abc.onion")
+	require.NoError(t, err)
+	assert.Equal(t, "https+onion", parsed.Scheme)
+	assert.Equal(t, Transport("tor"), parsed.Transport)
+	assert.Equal(t, "abc.onion", parsed.Host)
+
+	_, err = registry.Parse("https+onion://example.com")
+	require.ErrorIs(t, err, errOnionHostRequired)
+
+	// The default registry is unaffected
+	assert.False(t, IsAdvertisableURI("https+onion://abc.onion"))
+}
+
+func TestURISchemeRegistry_RegisterErrors(t *testing.T) {
+	registry := NewURISchemeRegistry()
+
+	err := registry.Register(URIScheme{Prefix: "https://", Validate: NewHTTPSFamilyValidator("https://")})
+	require.ErrorIs(t, err, ErrURISchemeExists)
+
+	err = registry.Register(URIScheme{Prefix: "custom", Validate: NewHTTPSFamilyValidator("custom")})
+	require.ErrorIs(t, err, ErrURISchemeInvalid)
+
+	err = registry.Register(URIScheme{Prefix: "://", Validate: NewHTTPSFamilyValidator("://")})
+	require.ErrorIs(t, err, ErrURISchemeInvalid)
+
+	err = registry.Register(URIScheme{Prefix: "custom://"})
+	require.ErrorIs(t, err, ErrURISchemeInvalid)
+
+	require.ErrorIs(t, registry.Disable("custom://"), ErrURISchemeUnknown)
+	require.ErrorIs(t, registry.Enable("custom://"), ErrURISchemeUnknown)
+}
+
+func TestURISchemeRegistry_DisableBuiltin(t *testing.T) {
+	registry := NewURISchemeRegistry()
+	require.NoError(t, registry.Disable("js8c+bsvauth+smf:"))
+
+	uri := "js8c+bsvauth+smf:?lat=40.7128&long=-74.0060&freq=7.078MHz&radius=100km"
+	_, err := registry.Parse(uri)
+	require.ErrorIs(t, err, ErrURIUnsupportedScheme)
+	assert.True(t, registry.IsAdvertisable("https://example.com"))
+	assert.Len(t, registry.Schemes(), len(BuiltinURISchemes())-1)
+
+	require.NoError(t, registry.Enable("js8c+bsvauth+smf:"))
+	assert.True(t, registry.IsAdvertisable(uri))
+}
+
+func TestURISchemeRegistry_LongestPrefixWins(t *testing.T) {
+	registry := NewURISchemeRegistry()
+	require.NoError(t, registry.Register(URIScheme{
+		Prefix:    "wss://relay.",
+		Family:    SchemeFamilyWSS,
+		Transport: TransportWebSocket,
+		Auth:      AuthBSV,
+		Validate:  validateWSSScheme,
+	}))
+
+	parsed, err := registry.Parse("wss://relay.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, AuthBSV, parsed.Auth)
+	assert.Equal(t, "relay.example.com", parsed.Host)
+
+	parsed, err = registry.Parse("wss://stream.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, AuthNone, parsed.Auth)
+}
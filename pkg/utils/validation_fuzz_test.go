@@ -113,38 +113,36 @@ func FuzzIsValidTopicOrServiceName(f *testing.F) {
 	})
 }
 
-// FuzzValidateCustomHTTPSURI tests the validateCustomHTTPSURI function
-// with random inputs to ensure robustness.
-func FuzzValidateCustomHTTPSURI(f *testing.F) {
+// FuzzParseHTTPSFamilyURI tests ParseAdvertisableURI with random HTTPS-family inputs
+// to ensure robustness.
+func FuzzParseHTTPSFamilyURI(f *testing.F) {
 	// Seed corpus with valid examples
-	f.Add("custom://example.com/", "custom://")
-	f.Add("https://example.com/", "https://")
-	f.Add("https+bsvauth://example.com/", "https+bsvauth://")
+	f.Add("https+bsvauth+smf://example.com/")
+	f.Add("https://example.com/")
+	f.Add("https+bsvauth://example.com/")
 
 	// Seed corpus with invalid examples
-	f.Add("custom://localhost/", "custom://")
-	f.Add("custom://example.com/path", "custom://")
-	f.Add("custom://[invalid", "custom://")
+	f.Add("https+bsvauth://localhost/")
+	f.Add("https+bsvauth://example.com/path")
+	f.Add("https+bsvauth://[invalid")
 
 	// Seed corpus with edge cases
-	f.Add("custom://", "custom://")
-	f.Add("://example.com/", "://")
-	f.Add("custom://198.51.100.1/", "custom://")
-	f.Add("custom://[::1]/", "custom://")
+	f.Add("https+bsvauth://")
+	f.Add("://example.com/")
+	f.Add("https+bsvauth://198.51.100.1/")
+	f.Add("https+bsvauth://[::1]/")
 
-	f.Fuzz(func(t *testing.T, uri, prefix string) {
-		if len(uri)+len(prefix) > 10000 {
+	f.Fuzz(func(t *testing.T, uri string) {
+		if len(uri) > 10000 {
 			t.Skip("input too large")
 		}
 		// Function should not panic on any input
-		_ = validateCustomHTTPSURI(uri, prefix)
-		// We don't validate the result as this is an internal function
-		// The main goal is to ensure it doesn't panic
+		checkParseAdvertisableURI(t, uri)
 	})
 }
 
-// FuzzValidateWSSURI tests the validateWSSURI function with random inputs.
-func FuzzValidateWSSURI(f *testing.F) {
+// FuzzParseWSSURI tests ParseAdvertisableURI with random WSS inputs.
+func FuzzParseWSSURI(f *testing.F) {
 	// Seed corpus with valid examples
 	f.Add("wss://example.com")
 	f.Add("wss://example.com:443")
@@ -167,14 +165,12 @@ func FuzzValidateWSSURI(f *testing.F) {
 			t.Skip("input too large")
 		}
 		// Function should not panic on any input
-		_ = validateWSSURI(uri)
-		// We don't validate the result as this is an internal function
-		// The main goal is to ensure it doesn't panic
+		checkParseAdvertisableURI(t, uri)
 	})
 }
 
-// FuzzValidateJS8CallURI tests the validateJS8CallURI function with random inputs.
-func FuzzValidateJS8CallURI(f *testing.F) {
+// FuzzParseJS8CallURI tests ParseAdvertisableURI with random JS8 Call inputs.
+func FuzzParseJS8CallURI(f *testing.F) {
 	// Seed corpus with valid examples
 	f.Add("js8c+bsvauth+smf:?lat=40.7128&long=-74.0060&freq=7.078&radius=100")
 	f.Add("js8c+bsvauth+smf:?lat=0&long=0&freq=1&radius=1")
@@ -200,8 +196,18 @@ func FuzzValidateJS8CallURI(f *testing.F) {
 			t.Skip("input too large")
 		}
 		// Function should not panic on any input
-		_ = validateJS8CallURI(uri)
-		// We don't validate the result as this is an internal function
-		// The main goal is to ensure it doesn't panic
+		checkParseAdvertisableURI(t, uri)
 	})
 }
+
+// checkParseAdvertisableURI parses uri and checks that the result agrees with IsAdvertisableURI.
+func checkParseAdvertisableURI(t *testing.T, uri string) {
+	t.Helper()
+	parsed, err := ParseAdvertisableURI(uri)
+	if err == nil && parsed == nil {
+		t.Errorf("ParseAdvertisableURI(%q) returned neither a URI nor an error", uri)
+	}
+	if IsAdvertisableURI(uri) != (err == nil) {
+		t.Errorf("IsAdvertisableURI(%q) disagrees with ParseAdvertisableURI error %v", uri, err)
+	}
+}
@@ -130,30 +130,29 @@ func TestIsValidTopicOrServiceName(t *testing.T) {
 	}
 }
 
-func TestValidateCustomHTTPSURI(t *testing.T) {
+func TestParseAdvertisableURI_HTTPSFamily(t *testing.T) {
 	tests := []struct {
 		name     string
 		uri      string
-		prefix   string
 		expected bool
 	}{
-		{"valid custom scheme", "custom://example.com/", "custom://", true},
-		{"localhost blocked", "custom://localhost/", "custom://", false},
-		{"path not allowed", "custom://example.com/path", "custom://", false},
-		{"malformed URL", "custom://[invalid", "custom://", false},
+		{"valid custom scheme", "https+bsvauth://example.com/", true},
+		{"localhost blocked", "https+bsvauth://localhost/", false},
+		{"path not allowed", "https+bsvauth://example.com/path", false},
+		{"malformed URL", "https+bsvauth://[invalid", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validateCustomHTTPSURI(tt.uri, tt.prefix)
-			if result != tt.expected {
-				t.Errorf("validateCustomHTTPSURI(%q, %q) = %v, expected %v", tt.uri, tt.prefix, result, tt.expected)
+			_, err := ParseAdvertisableURI(tt.uri)
+			if (err == nil) != tt.expected {
+				t.Errorf("ParseAdvertisableURI(%q) error = %v, expected valid %v", tt.uri, err, tt.expected)
 			}
 		})
 	}
 }
 
-func TestValidateWSSURI(t *testing.T) {
+func TestParseAdvertisableURI_WSS(t *testing.T) {
 	tests := []struct {
 		name     string
 		uri      string
@@ -167,15 +166,15 @@ func TestValidateWSSURI(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validateWSSURI(tt.uri)
-			if result != tt.expected {
-				t.Errorf("validateWSSURI(%q) = %v, expected %v", tt.uri, result, tt.expected)
+			_, err := ParseAdvertisableURI(tt.uri)
+			if (err == nil) != tt.expected {
+				t.Errorf("ParseAdvertisableURI(%q) error = %v, expected valid %v", tt.uri, err, tt.expected)
 			}
 		})
 	}
 }
 
-func TestValidateJS8CallURI(t *testing.T) {
+func TestParseAdvertisableURI_JS8Call(t *testing.T) {
 	tests := []struct {
 		name     string
 		uri      string
@@ -198,9 +197,9 @@ func TestValidateJS8CallURI(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validateJS8CallURI(tt.uri)
-			if result != tt.expected {
-				t.Errorf("validateJS8CallURI(%q) = %v, expected %v", tt.uri, result, tt.expected)
+			_, err := ParseAdvertisableURI(tt.uri)
+			if (err == nil) != tt.expected {
+				t.Errorf("ParseAdvertisableURI(%q) error = %v, expected valid %v", tt.uri, err, tt.expected)
 			}
 		})
 	}